@@ -76,5 +76,8 @@ func main() {
 	//get AppConfig
 	appConfig := utils.GetConfig()
 	log.Println("Custodian server started.")
-	srv.Setup(appConfig).ListenAndServe()
+	httpServer := srv.Setup(appConfig)
+	//notifications are delivered from the outbox in background
+	srv.StartOutbox()
+	httpServer.ListenAndServe()
 }
@@ -81,6 +81,20 @@ func CheckMask(obj map[string]interface{}, mask []string) []string {
 
 }
 
+// CheckFieldsMask : check field paths against mask, masked field restricts all paths through it
+func CheckFieldsMask(fields []string, mask []string) []string {
+	var restricted []string
+	for _, field := range fields {
+		for _, path := range mask {
+			if field == path || strings.HasPrefix(field, path+".") {
+				restricted = append(restricted, field)
+				break
+			}
+		}
+	}
+	return restricted
+}
+
 func cleanupType(value interface{}) interface{} {
 	if value != nil {
 		v := reflect.ValueOf(value)
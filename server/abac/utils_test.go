@@ -67,4 +67,9 @@ var _ = Describe("Utils", func() {
 		})
 
 	})
+
+	It("Must return aggregated fields restricted by mask", func() {
+		restricted := CheckFieldsMask([]string{"a", "c.x", "owner.name", "b"}, []string{"c", "owner.name"})
+		Expect(restricted).To(Equal([]string{"c.x", "owner.name"}))
+	})
 })
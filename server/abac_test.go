@@ -743,4 +743,64 @@ var _ = Describe("ABAC rules handling", func() {
 			})
 		})
 	})
+
+	Describe("Outbox rules", func() {
+		It("Must deny outbox list for users if no rule is set", func() {
+			httpServer = get_server(&auth.User{Authorized: true, Role: abac.JsonToObject(`{"id": "admin"}`)})
+
+			url := fmt.Sprintf("%s/outbox", appConfig.UrlPrefix)
+
+			var request, _ = http.NewRequest("GET", url, nil)
+			httpServer.Handler.ServeHTTP(recorder, request)
+			responseBody := recorder.Body.String()
+
+			var body map[string]interface{}
+			json.Unmarshal([]byte(responseBody), &body)
+			Expect(body["status"].(string)).To(Equal("FAIL"))
+		})
+
+		It("Must allow outbox list for services", func() {
+			httpServer = get_server(&auth.User{Authorized: true, Type: "service"})
+
+			url := fmt.Sprintf("%s/outbox", appConfig.UrlPrefix)
+
+			var request, _ = http.NewRequest("GET", url, nil)
+			httpServer.Handler.ServeHTTP(recorder, request)
+			responseBody := recorder.Body.String()
+
+			var body map[string]interface{}
+			json.Unmarshal([]byte(responseBody), &body)
+			Expect(body["status"].(string)).To(Equal("OK"))
+		})
+
+		It("Must allow outbox list for users by the rule of outbox resource", func() {
+			httpServer = get_server(&auth.User{
+				Authorized: true,
+				Role:       abac.JsonToObject(`{"id": "admin"}`),
+				ABAC: map[string]interface{}{
+					SERVICE_DOMAIN: map[string]interface{}{
+						"_default_resolution": "deny",
+						"outbox": map[string]interface{}{
+							"GET": []interface{}{
+								map[string]interface{}{
+									"result": "allow",
+									"rule":   map[string]interface{}{"sbj.role.id": "admin"},
+								},
+							},
+						},
+					},
+				},
+			})
+
+			url := fmt.Sprintf("%s/outbox", appConfig.UrlPrefix)
+
+			var request, _ = http.NewRequest("GET", url, nil)
+			httpServer.Handler.ServeHTTP(recorder, request)
+			responseBody := recorder.Body.String()
+
+			var body map[string]interface{}
+			json.Unmarshal([]byte(responseBody), &body)
+			Expect(body["status"].(string)).To(Equal("OK"))
+		})
+	})
 })
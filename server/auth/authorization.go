@@ -93,7 +93,7 @@ func GetServiceToken() (string, error) {
 	domain := os.Getenv("SERVICE_DOMAIN")
 
 	if domain != "" {
-		result := domain + ":" + sign(domain)
+		result := domain + ":" + Sign(domain)
 		return result, nil
 	}
 
@@ -102,10 +102,11 @@ func GetServiceToken() (string, error) {
 
 func CheckServiceToken(token string) bool {
 	splited := strings.SplitN(token, ":", 2)
-	return splited[1] == sign(splited[0])
+	return splited[1] == Sign(splited[0])
 }
 
-func sign(value string) string {
+//Sign the value with the service secret, services sharing the secret can verify it
+func Sign(value string) string {
 	secret := os.Getenv("SERVICE_AUTH_SECRET")
 
 	key := sha1.New()
@@ -10,6 +10,7 @@ import (
 const (
 	ErrBadRequest           = "bad_request"
 	ErrNotFound             = "not_found"
+	ErrInternalServerError  = "internal_server_error"
 )
 
 //The interface of error convertable to JSON in format {"Code":"some_code"; "Msg":"message"}.
@@ -53,4 +54,8 @@ func NewValidationError(code string, msg string, data interface{}) *ServerError
 
 func NewNotFoundError(code string, msg string, data interface{}) *ServerError {
 	return &ServerError{http.StatusNotFound, code, msg, data}
-}
\ No newline at end of file
+}
+
+func NewConflictError(code string, msg string, data interface{}) *ServerError {
+	return &ServerError{http.StatusConflict, code, msg, data}
+}
@@ -0,0 +1,403 @@
+package server
+
+import (
+	"custodian/server/abac"
+	"custodian/server/auth"
+	. "custodian/server/errors"
+	"custodian/server/graphql"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//Schema of the GraphQL endpoint generated from the metadata, it is rebuilt once the metadata cache is changed
+type graphqlSchemaCache struct {
+	mutex     sync.Mutex
+	metaCache *object.MetaCache
+	limits    graphql.Limits
+	version   uint64
+	schema    *graphql.Schema
+}
+
+func newGraphQLSchemaCache(metaCache *object.MetaCache, limits graphql.Limits) *graphqlSchemaCache {
+	return &graphqlSchemaCache{metaCache: metaCache, limits: limits}
+}
+
+func (c *graphqlSchemaCache) get() (*graphql.Schema, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	version := c.metaCache.Version()
+	if c.schema == nil || c.version != version {
+		schema, err := newGraphQLSchema(c.metaCache.GetList())
+		if err != nil {
+			return nil, NewFatalError(ErrInternalServerError, fmt.Sprintf("Failed to build GraphQL schema: %s", err.Error()), nil)
+		}
+		schema.Limits = c.limits
+		c.schema, c.version = schema, version
+	}
+	return c.schema, nil
+}
+
+//Values passed to resolvers of the request
+type graphqlContext struct {
+	processor    *object.Processor
+	user         auth.User
+	abacResolver abac.TroodABAC
+}
+
+//Builds the schema with an object type for each object. Objects and fields with names which are not valid in GraphQL are omitted
+func newGraphQLSchema(metaList []*object.Meta) (*graphql.Schema, error) {
+	sort.Slice(metaList, func(i, j int) bool { return metaList[i].Name < metaList[j].Name })
+	types := make(map[string]*graphql.Type)
+	for _, objectMeta := range metaList {
+		if graphql.IsName(objectMeta.Name) && objectMeta.Key != nil {
+			types[objectMeta.Name] = &graphql.Type{Kind: graphql.KindObject, Name: objectMeta.Name}
+		}
+	}
+
+	query := &graphql.Type{Kind: graphql.KindObject, Name: "Query"}
+	mutation := &graphql.Type{Kind: graphql.KindObject, Name: "Mutation"}
+	for _, objectMeta := range metaList {
+		objectType, ok := types[objectMeta.Name]
+		if !ok {
+			continue
+		}
+		for i := range objectMeta.Fields {
+			if field := graphqlField(&objectMeta.Fields[i], types); field != nil {
+				objectType.Fields = append(objectType.Fields, field)
+			}
+		}
+		query.Fields = append(query.Fields, graphqlQueryFields(objectMeta, objectType)...)
+		mutation.Fields = append(mutation.Fields, graphqlMutationFields(objectMeta, objectType)...)
+	}
+	for _, field := range append(query.Fields, mutation.Fields...) {
+		resolve := field.Resolve
+		field.Resolve = func(params *graphql.ResolveParams) (interface{}, error) {
+			value, err := resolve(params)
+			if err != nil {
+				return nil, graphqlError(err)
+			}
+			return value, nil
+		}
+	}
+	return graphql.NewSchema(query, mutation)
+}
+
+//Returns the field of the object type, links are resolved to the types of linked objects
+func graphqlField(fieldDescription *object.FieldDescription, types map[string]*graphql.Type) *graphql.Field {
+	if !graphql.IsName(fieldDescription.Name) {
+		return nil
+	}
+	field := &graphql.Field{Name: fieldDescription.Name}
+	switch fieldDescription.Type {
+	case description.FieldTypeString, description.FieldTypeDate, description.FieldTypeDateTime, description.FieldTypeTime, description.FieldTypeUUID:
+		field.Type = graphql.String
+	//integers are 64-bit, so that they don't fit GraphQL Int
+	case description.FieldTypeNumber, description.FieldTypeInteger, description.FieldTypeDecimal:
+		field.Type = graphql.Float
+	case description.FieldTypeBool:
+		field.Type = graphql.Boolean
+	case description.FieldTypeJSON:
+		field.Type = graphql.JSON
+	case description.FieldTypeStringArray:
+		field.Type = graphql.ListOf(graphql.String)
+	case description.FieldTypeNumberArray:
+		field.Type = graphql.ListOf(graphql.Float)
+	case description.FieldTypeEnum:
+		field.Type = graphqlEnumType(fieldDescription)
+	case description.FieldTypeObject:
+		if linkType, ok := types[fieldDescription.LinkMeta.Name]; ok {
+			field.Type = linkType
+			field.Resolve = resolveLink(fieldDescription.Name, fieldDescription.LinkMeta)
+		}
+	case description.FieldTypeArray, description.FieldTypeObjects:
+		if linkType, ok := types[fieldDescription.LinkMeta.Name]; ok {
+			field.Type = graphql.ListOf(linkType)
+			field.Resolve = resolveLink(fieldDescription.Name, fieldDescription.LinkMeta)
+		}
+	case description.FieldTypeGeneric:
+		if fieldDescription.LinkType == description.LinkTypeOuter {
+			if linkType, ok := types[fieldDescription.LinkMeta.Name]; ok {
+				field.Type = graphql.ListOf(linkType)
+				field.Resolve = resolveLink(fieldDescription.Name, fieldDescription.LinkMeta)
+			}
+		} else {
+			field.Type = graphqlUnionType(fieldDescription, types)
+		}
+	}
+	if field.Type == nil {
+		return nil
+	}
+	return field
+}
+
+//Choices become values of the enum if all of them are valid names, otherwise the field is a string
+func graphqlEnumType(fieldDescription *object.FieldDescription) *graphql.Type {
+	for _, choice := range fieldDescription.Enum {
+		if !graphql.IsName(choice) || choice == "true" || choice == "false" || choice == "null" {
+			return graphql.String
+		}
+	}
+	return &graphql.Type{
+		Kind:       graphql.KindEnum,
+		Name:       fmt.Sprintf("%s_%s_enum", fieldDescription.Meta.Name, fieldDescription.Name),
+		EnumValues: fieldDescription.Enum,
+	}
+}
+
+//Generic inner links are unions of the linked objects, the concrete type is taken from the "_object" value
+func graphqlUnionType(fieldDescription *object.FieldDescription, types map[string]*graphql.Type) *graphql.Type {
+	union := &graphql.Type{
+		Kind: graphql.KindUnion,
+		Name: fmt.Sprintf("%s_%s_generic", fieldDescription.Meta.Name, fieldDescription.Name),
+		ResolveType: func(value interface{}) string {
+			if link, ok := value.(map[string]interface{}); ok {
+				if objectName, ok := link[object.GenericInnerLinkObjectKey].(string); ok {
+					return objectName
+				}
+			}
+			return ""
+		},
+	}
+	for _, linkMeta := range fieldDescription.LinkMetaList.GetAll() {
+		if linkType, ok := types[linkMeta.Name]; ok {
+			union.PossibleTypes = append(union.PossibleTypes, linkType)
+		}
+	}
+	if len(union.PossibleTypes) == 0 {
+		return nil
+	}
+	return union
+}
+
+//Links beyond the retrieved depth are keys, they are returned as objects with the key field only
+func resolveLink(fieldName string, linkMeta *object.Meta) func(params *graphql.ResolveParams) (interface{}, error) {
+	asObject := func(value interface{}) interface{} {
+		if _, ok := value.(map[string]interface{}); ok || value == nil {
+			return value
+		}
+		return map[string]interface{}{linkMeta.Key.Name: value}
+	}
+	return func(params *graphql.ResolveParams) (interface{}, error) {
+		value := params.Source[fieldName]
+		if items, ok := value.([]interface{}); ok {
+			objects := make([]interface{}, len(items))
+			for i, item := range items {
+				objects[i] = asObject(item)
+			}
+			return objects, nil
+		}
+		return asObject(value), nil
+	}
+}
+
+//Records are retrieved as deep as the subfields are selected, the depth of the query is limited by the schema
+func recordDepth(params *graphql.ResolveParams) int {
+	if depth := params.Depth(); depth > 1 {
+		return depth
+	}
+	return 1
+}
+
+//Checks access to the object the same way it is checked by CreateJsonAction for REST endpoints,
+//records restricted by the filter of the rule are checked separately
+func checkObjectAccess(abacResolver abac.TroodABAC, objectName string, action string) error {
+	passed, rule := abacResolver.Check(objectName, action)
+	if !passed && !(rule != nil && rule.Filter != nil && rule.Result == "deny") {
+		return abac.NewError("Access restricted by ABAC access rule")
+	}
+	return nil
+}
+
+func graphqlQueryFields(objectMeta *object.Meta, objectType *graphql.Type) []*graphql.Field {
+	objectName := objectMeta.Name
+	return []*graphql.Field{
+		{
+			Name:        objectName,
+			Description: fmt.Sprintf("Record of %s by key", objectName),
+			Type:        objectType,
+			Args:        []*graphql.Argument{{Name: "key", Type: graphql.NonNull(graphql.ID)}},
+			Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+				ctx := params.Context.(*graphqlContext)
+				if err := checkObjectAccess(ctx.abacResolver, objectName, "data_GET"); err != nil {
+					return nil, err
+				}
+				record, err := ctx.processor.Get(objectName, params.Args["key"].(string), nil, nil, recordDepth(params), false)
+				if err != nil || record == nil {
+					return nil, err
+				}
+				pass, result := ctx.abacResolver.MaskRecord(record, "data_GET")
+				if !pass {
+					return nil, abac.NewError("Permission denied")
+				}
+				return result.(*object.Record).GetData(), nil
+			},
+		},
+		{
+			Name:        objectName + "_list",
+			Description: fmt.Sprintf("Records of %s matching the RQL filter", objectName),
+			Type:        graphql.ListOf(objectType),
+			Args:        []*graphql.Argument{{Name: "q", Type: graphql.String, Description: "RQL filter, eg: eq(name,value),limit(0,10)"}},
+			Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+				ctx := params.Context.(*graphqlContext)
+				if err := checkObjectAccess(ctx.abacResolver, objectName, "data_LIST"); err != nil {
+					return nil, err
+				}
+				var filters []string
+				_, rule := ctx.abacResolver.Check(objectName, "data_LIST")
+				if rule != nil && rule.Filter != nil {
+					if rule.Result == "deny" {
+						filters = append(filters, rule.Filter.Invert().String())
+					} else {
+						filters = append(filters, rule.Filter.String())
+					}
+				}
+				if userFilter, ok := params.Args["q"].(string); ok && userFilter != "" {
+					filters = append(filters, userFilter)
+				}
+				_, records, err := ctx.processor.GetBulk(objectName, strings.Join(filters, ","), nil, nil, recordDepth(params), false)
+				if err != nil {
+					return nil, err
+				}
+				result := make([]interface{}, 0, len(records))
+				for _, record := range records {
+					if pass, masked := ctx.abacResolver.MaskRecord(record, "data_LIST"); pass {
+						result = append(result, masked.(*object.Record).GetData())
+					}
+				}
+				return result, nil
+			},
+		},
+	}
+}
+
+func graphqlMutationFields(objectMeta *object.Meta, objectType *graphql.Type) []*graphql.Field {
+	objectName := objectMeta.Name
+	keyArgument := &graphql.Argument{Name: "key", Type: graphql.NonNull(graphql.ID)}
+	dataArgument := &graphql.Argument{Name: "data", Type: graphql.NonNull(graphql.JSON)}
+
+	//the changed record is retrieved again, so that its links are resolved as deep as the subfields are selected
+	retrieve := func(params *graphql.ResolveParams, record *object.Record) (interface{}, error) {
+		key, err := record.Meta.Key.ValueAsString(record.Data[record.Meta.Key.Name])
+		if err != nil {
+			return nil, err
+		}
+		record, err = params.Context.(*graphqlContext).processor.Get(objectName, key, nil, nil, recordDepth(params), false)
+		if err != nil || record == nil {
+			return nil, err
+		}
+		return record.GetData(), nil
+	}
+	//the record is checked by the rules of the action before it is changed
+	recordToChange := func(params *graphql.ResolveParams, action string) (*object.Record, *abac.RuleABAC, error) {
+		ctx := params.Context.(*graphqlContext)
+		if err := checkObjectAccess(ctx.abacResolver, objectName, action); err != nil {
+			return nil, nil, err
+		}
+		record, err := ctx.processor.Get(objectName, params.Args["key"].(string), nil, nil, 1, true)
+		if err != nil || record == nil {
+			return nil, nil, &ServerError{http.StatusNotFound, ErrNotFound, "record not found", nil}
+		}
+		pass, rule := ctx.abacResolver.CheckRecord(record, action)
+		if !pass {
+			return nil, nil, abac.NewError("Permission denied")
+		}
+		return record, rule, nil
+	}
+	recordData := func(params *graphql.ResolveParams) (map[string]interface{}, error) {
+		if data, ok := params.Args["data"].(map[string]interface{}); ok {
+			return data, nil
+		}
+		return nil, &ServerError{http.StatusBadRequest, ErrBadRequest, "Data must be an object", nil}
+	}
+
+	return []*graphql.Field{
+		{
+			Name:        "create_" + objectName,
+			Description: fmt.Sprintf("Creates the record of %s", objectName),
+			Type:        objectType,
+			Args:        []*graphql.Argument{dataArgument},
+			Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+				ctx := params.Context.(*graphqlContext)
+				data, err := recordData(params)
+				if err != nil {
+					return nil, err
+				}
+				if err := checkObjectAccess(ctx.abacResolver, objectName, "data_POST"); err != nil {
+					return nil, err
+				}
+				if _, rule := ctx.abacResolver.Check(objectName, "data_POST"); rule != nil {
+					if restricted := abac.CheckMask(data, rule.Mask); len(restricted) > 0 {
+						return nil, abac.NewError(fmt.Sprintf("Creating object with fields [%s] restricted by ABAC rule", strings.Join(restricted, ",")))
+					}
+				}
+				record, err := ctx.processor.CreateRecord(objectName, data, ctx.user)
+				if err != nil {
+					return nil, err
+				}
+				return retrieve(params, record)
+			},
+		},
+		{
+			Name:        "update_" + objectName,
+			Description: fmt.Sprintf("Updates the record of %s by key", objectName),
+			Type:        objectType,
+			Args:        []*graphql.Argument{keyArgument, dataArgument},
+			Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+				ctx := params.Context.(*graphqlContext)
+				data, err := recordData(params)
+				if err != nil {
+					return nil, err
+				}
+				_, rule, err := recordToChange(params, "data_PATCH")
+				if err != nil {
+					return nil, err
+				}
+				if rule != nil {
+					if restricted := abac.CheckMask(data, rule.Mask); len(restricted) > 0 {
+						return nil, abac.NewError(fmt.Sprintf("Updating fields [%s] restricted by ABAC rule", strings.Join(restricted, ",")))
+					}
+				}
+				record, err := ctx.processor.UpdateRecord(objectName, params.Args["key"].(string), data, ctx.user)
+				if err != nil || record == nil {
+					return nil, err
+				}
+				return retrieve(params, record)
+			},
+		},
+		{
+			Name:        "delete_" + objectName,
+			Description: fmt.Sprintf("Deletes the record of %s by key", objectName),
+			Type:        objectType,
+			Args:        []*graphql.Argument{keyArgument},
+			Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+				ctx := params.Context.(*graphqlContext)
+				if _, _, err := recordToChange(params, "data_DELETE"); err != nil {
+					return nil, err
+				}
+				record, err := ctx.processor.RemoveRecord(objectName, params.Args["key"].(string), ctx.user)
+				if err != nil || record == nil {
+					return nil, err
+				}
+				return record.GetData(), nil
+			},
+		},
+	}
+}
+
+//Errors of resolvers are returned with the code of the error in extensions
+func graphqlError(err error) *graphql.Error {
+	switch e := err.(type) {
+	case *graphql.Error:
+		return e
+	case *ServerError:
+		return &graphql.Error{Message: e.Msg, Extensions: map[string]interface{}{"code": e.Code, "data": e.Data}}
+	case *abac.AccessError:
+		return &graphql.Error{Message: e.Error(), Extensions: map[string]interface{}{"code": e.Serialize()["code"]}}
+	}
+	return &graphql.Error{Message: err.Error()}
+}
@@ -0,0 +1,93 @@
+package graphql
+
+//Executable document: operations and fragments, type system definitions are not supported
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+const (
+	OperationQuery    = "query"
+	OperationMutation = "mutation"
+)
+
+type Operation struct {
+	Type         string
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+}
+
+type VariableDefinition struct {
+	Name    string
+	Type    *TypeRef
+	Default Value
+}
+
+//Type of the variable as it is written in the document, eg: [String!]!
+type TypeRef struct {
+	Name    string
+	OfType  *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	var s string
+	if t.OfType != nil {
+		s = "[" + t.OfType.String() + "]"
+	} else {
+		s = t.Name
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+//Selection is one of: *SelectedField, *FragmentSpread or *InlineFragment
+type Selection interface{}
+
+type SelectedField struct {
+	Alias        string
+	Name         string
+	Arguments    map[string]Value
+	Directives   []*Directive
+	SelectionSet []Selection
+}
+
+//Key of the field in the response
+func (f *SelectedField) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+}
+
+type Directive struct {
+	Name      string
+	Arguments map[string]Value
+}
+
+//Value is one of: nil, bool, int64, float64, string, EnumValue, Variable, []Value or map[string]Value
+type Value interface{}
+
+type EnumValue string
+
+type Variable string
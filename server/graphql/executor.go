@@ -0,0 +1,648 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+//Error of the request or of the field, in the latter case the path of the field is set
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(format string, args ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...)}
+}
+
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+//Data is omitted if the request fails before the execution
+type Result struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+//Object of the response which keeps the order of the selected fields
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		b.Write(encodedKey)
+		b.WriteByte(':')
+		encodedValue, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(encodedValue)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+//Sentinel error: the value is null because of the field error which is already reported,
+//it propagates to the nearest nullable parent
+var errNull = errors.New("null propagated")
+
+type executor struct {
+	schema    *Schema
+	document  *Document
+	variables map[string]interface{}
+	context   interface{}
+	errors    []*Error
+}
+
+//Executes the request. Errors of the document and the variables are returned without data,
+//errors of fields are returned along with data having null values of the failed fields.
+//The context is passed to resolvers as is
+func (s *Schema) Execute(request *Request, context interface{}) *Result {
+	document, err := Parse(request.Query)
+	if err != nil {
+		return &Result{Errors: []*Error{asError(err)}}
+	}
+	operation, err := document.operation(request.OperationName)
+	if err != nil {
+		return &Result{Errors: []*Error{asError(err)}}
+	}
+	if err := s.Limits.check(operation, document.Fragments); err != nil {
+		return &Result{Errors: []*Error{asError(err)}}
+	}
+	rootType := s.Query
+	if operation.Type == OperationMutation {
+		if s.Mutation == nil {
+			return &Result{Errors: []*Error{newError("Schema is not configured for mutations")}}
+		}
+		rootType = s.Mutation
+	}
+
+	e := &executor{schema: s, document: document, context: context, errors: make([]*Error, 0)}
+	if e.variables, err = e.coerceVariables(operation, request.Variables); err != nil {
+		return &Result{Errors: []*Error{asError(err)}}
+	}
+	data, err := e.selectionSet(rootType, nil, operation.SelectionSet, []interface{}{})
+	if err != nil && err != errNull {
+		return &Result{Errors: []*Error{asError(err)}}
+	}
+	result := &Result{Data: data}
+	if err == errNull {
+		result.Data = json.RawMessage("null")
+	}
+	if len(e.errors) > 0 {
+		result.Errors = e.errors
+	}
+	return result
+}
+
+func (d *Document) operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) > 1 {
+			return nil, newError("Must provide operation name if query contains multiple operations")
+		}
+		return d.Operations[0], nil
+	}
+	for _, operation := range d.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+	return nil, newError("Unknown operation named \"%s\"", name)
+}
+
+func asError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Message: err.Error()}
+}
+
+func (e *executor) coerceVariables(operation *Operation, values map[string]interface{}) (map[string]interface{}, error) {
+	variables := make(map[string]interface{})
+	for _, definition := range operation.Variables {
+		t, err := e.inputType(definition.Type)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := values[definition.Name]
+		if !ok {
+			if definition.Default != nil {
+				if variables[definition.Name], err = coerceLiteral(definition.Default, t, nil); err != nil {
+					return nil, newError("Variable \"$%s\" has invalid default value: %s", definition.Name, err.Error())
+				}
+			} else if t.Kind == KindNonNull {
+				return nil, newError("Variable \"$%s\" of required type \"%s\" was not provided", definition.Name, t)
+			}
+			continue
+		}
+		if variables[definition.Name], err = coerceInput(value, t); err != nil {
+			return nil, newError("Variable \"$%s\" got invalid value: %s", definition.Name, err.Error())
+		}
+	}
+	return variables, nil
+}
+
+//Returns the type of the variable, only scalars and enums can be used as input
+func (e *executor) inputType(ref *TypeRef) (*Type, error) {
+	var t *Type
+	if ref.OfType != nil {
+		ofType, err := e.inputType(ref.OfType)
+		if err != nil {
+			return nil, err
+		}
+		t = ListOf(ofType)
+	} else if t = e.schema.Type(ref.Name); t == nil {
+		return nil, newError("Unknown type \"%s\"", ref.Name)
+	} else if t.Kind != KindScalar && t.Kind != KindEnum {
+		return nil, newError("Type \"%s\" can't be used as input", ref.Name)
+	}
+	if ref.NonNull {
+		t = NonNull(t)
+	}
+	return t, nil
+}
+
+//Groups selected fields by their response keys, fragments are expanded if they apply to the type
+func (e *executor) collectFields(objectType *Type, selectionSet []Selection, fields *orderedMap, visited map[string]bool) error {
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *SelectedField:
+			if include, err := e.included(selection.Directives); err != nil || !include {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			var group []*SelectedField
+			if existing, ok := fields.values[selection.ResponseKey()]; ok {
+				group = existing.([]*SelectedField)
+			}
+			fields.set(selection.ResponseKey(), append(group, selection))
+		case *InlineFragment:
+			if include, err := e.included(selection.Directives); err != nil || !include {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if selection.TypeCondition != "" && !e.fragmentApplies(objectType, selection.TypeCondition) {
+				continue
+			}
+			if err := e.collectFields(objectType, selection.SelectionSet, fields, visited); err != nil {
+				return err
+			}
+		case *FragmentSpread:
+			if include, err := e.included(selection.Directives); err != nil || !include {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if visited[selection.Name] {
+				continue
+			}
+			visited[selection.Name] = true
+			fragment, ok := e.document.Fragments[selection.Name]
+			if !ok {
+				return newError("Unknown fragment \"%s\"", selection.Name)
+			}
+			if !e.fragmentApplies(objectType, fragment.TypeCondition) {
+				continue
+			}
+			if err := e.collectFields(objectType, fragment.SelectionSet, fields, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *executor) fragmentApplies(objectType *Type, typeCondition string) bool {
+	if typeCondition == objectType.Name {
+		return true
+	}
+	if conditionType := e.schema.Type(typeCondition); conditionType != nil && conditionType.Kind == KindUnion {
+		for _, possibleType := range conditionType.PossibleTypes {
+			if possibleType.Name == objectType.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//Applies @skip and @include directives
+func (e *executor) included(directives []*Directive) (bool, error) {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			return false, newError("Unknown directive \"@%s\"", directive.Name)
+		}
+		value, err := coerceLiteral(directive.Arguments["if"], NonNull(Boolean), e.variables)
+		if err != nil {
+			return false, newError("Directive \"@%s\" argument \"if\": %s", directive.Name, err.Error())
+		}
+		if value.(bool) == (directive.Name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (e *executor) selectionSet(objectType *Type, source map[string]interface{}, selectionSet []Selection, path []interface{}) (*orderedMap, error) {
+	fields := &orderedMap{values: make(map[string]interface{})}
+	if err := e.collectFields(objectType, selectionSet, fields, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	result := &orderedMap{values: make(map[string]interface{}, len(fields.keys))}
+	propagated := false
+	for _, key := range fields.keys {
+		value, err := e.field(objectType, source, fields.values[key].([]*SelectedField), append(path[:len(path):len(path)], key))
+		if err == errNull {
+			propagated = true
+		} else if err != nil {
+			return nil, err
+		}
+		result.set(key, value)
+	}
+	if propagated {
+		return nil, errNull
+	}
+	return result, nil
+}
+
+func (e *executor) field(objectType *Type, source map[string]interface{}, nodes []*SelectedField, path []interface{}) (interface{}, error) {
+	node := nodes[0]
+	switch {
+	case node.Name == "__typename":
+		return objectType.Name, nil
+	case node.Name == "__schema" && objectType == e.schema.Query:
+		return e.complete(introspectionSchemaType, nodes, e.schema.introspection, path)
+	case node.Name == "__type" && objectType == e.schema.Query:
+		name, err := coerceLiteral(node.Arguments["name"], NonNull(String), e.variables)
+		if err != nil {
+			return nil, newError("Argument \"name\" of field \"__type\": %s", err.Error())
+		}
+		for _, t := range e.schema.introspection["types"].([]interface{}) {
+			if t.(map[string]interface{})["name"] == name {
+				return e.complete(introspectionTypeType, nodes, t, path)
+			}
+		}
+		return nil, nil
+	}
+
+	field := objectType.Field(node.Name)
+	if field == nil {
+		return nil, newError("Cannot query field \"%s\" on type \"%s\"", node.Name, objectType.Name)
+	}
+	args, err := e.arguments(field, node)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if field.Resolve != nil {
+		value, err = field.Resolve(&ResolveParams{Source: source, Args: args, Context: e.context, Selection: node, fragments: e.document.Fragments})
+		if err != nil {
+			fieldError := *asError(err)
+			fieldError.Path = path
+			e.errors = append(e.errors, &fieldError)
+			if field.Type.Kind == KindNonNull {
+				return nil, errNull
+			}
+			return nil, nil
+		}
+	} else if source != nil {
+		value = source[field.Name]
+	}
+
+	value, err = e.complete(field.Type, nodes, value, path)
+	if err == errNull && field.Type.Kind != KindNonNull {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (e *executor) arguments(field *Field, node *SelectedField) (map[string]interface{}, error) {
+	for name := range node.Arguments {
+		if field.Arg(name) == nil {
+			return nil, newError("Unknown argument \"%s\" on field \"%s\"", name, field.Name)
+		}
+	}
+	args := make(map[string]interface{})
+	for _, arg := range field.Args {
+		literal, ok := node.Arguments[arg.Name]
+		if variable, isVariable := literal.(Variable); isVariable {
+			_, ok = e.variables[string(variable)]
+		}
+		if !ok {
+			if arg.Default != nil {
+				args[arg.Name] = arg.Default
+			} else if arg.Type.Kind == KindNonNull {
+				return nil, newError("Argument \"%s\" of type \"%s\" is required on field \"%s\"", arg.Name, arg.Type, field.Name)
+			}
+			continue
+		}
+		value, err := coerceLiteral(literal, arg.Type, e.variables)
+		if err != nil {
+			return nil, newError("Argument \"%s\" on field \"%s\" has invalid value: %s", arg.Name, field.Name, err.Error())
+		}
+		args[arg.Name] = value
+	}
+	return args, nil
+}
+
+//Completes the resolved value according to the type. Errors of values are reported as field errors
+func (e *executor) complete(t *Type, nodes []*SelectedField, value interface{}, path []interface{}) (interface{}, error) {
+	if t.Kind == KindNonNull {
+		completed, err := e.complete(t.OfType, nodes, value, path)
+		if err != nil {
+			return nil, err
+		}
+		if completed == nil {
+			e.fieldError(path, "Cannot return null for non-nullable field")
+			return nil, errNull
+		}
+		return completed, nil
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	named := t.Named()
+	hasSelection := len(nodes[0].SelectionSet) > 0
+	if (named.Kind == KindObject || named.Kind == KindUnion) != hasSelection {
+		if hasSelection {
+			return nil, newError("Field \"%s\" of type \"%s\" must not have a selection", nodes[0].Name, t)
+		}
+		return nil, newError("Field \"%s\" of type \"%s\" must have a selection of subfields", nodes[0].Name, t)
+	}
+
+	switch t.Kind {
+	case KindList:
+		items, ok := listItems(value)
+		if !ok {
+			e.fieldError(path, "Expected a list for field \"%s\"", nodes[0].Name)
+			return nil, errNull
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			completed, err := e.complete(t.OfType, nodes, item, append(path[:len(path):len(path)], i))
+			if err == errNull && t.OfType.Kind != KindNonNull {
+				completed, err = nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			result[i] = completed
+		}
+		return result, nil
+	case KindScalar:
+		serialized, err := t.Serialize(value)
+		if err != nil {
+			e.fieldError(path, err.Error())
+			return nil, errNull
+		}
+		return serialized, nil
+	case KindEnum:
+		for _, enumValue := range t.EnumValues {
+			if value == enumValue {
+				return value, nil
+			}
+		}
+		e.fieldError(path, "Enum \"%s\" cannot represent value: %v", t.Name, value)
+		return nil, errNull
+	case KindUnion:
+		typeName := t.ResolveType(value)
+		for _, possibleType := range t.PossibleTypes {
+			if possibleType.Name == typeName {
+				return e.complete(possibleType, nodes, value, path)
+			}
+		}
+		e.fieldError(path, "Union \"%s\" cannot represent value: %v", t.Name, value)
+		return nil, errNull
+	}
+
+	source, ok := value.(map[string]interface{})
+	if !ok {
+		e.fieldError(path, "Expected an object for field \"%s\"", nodes[0].Name)
+		return nil, errNull
+	}
+	selectionSet := make([]Selection, 0)
+	for _, node := range nodes {
+		selectionSet = append(selectionSet, node.SelectionSet...)
+	}
+	result, err := e.selectionSet(t, source, selectionSet, path)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//Returns items of any slice, eg: []map[string]interface{} or named types of slices
+func listItems(value interface{}) ([]interface{}, bool) {
+	if items, ok := value.([]interface{}); ok {
+		return items, true
+	}
+	slice := reflect.ValueOf(value)
+	if slice.Kind() != reflect.Slice {
+		return nil, false
+	}
+	items := make([]interface{}, slice.Len())
+	for i := range items {
+		items[i] = slice.Index(i).Interface()
+	}
+	return items, true
+}
+
+func (e *executor) fieldError(path []interface{}, format string, args ...interface{}) {
+	fieldError := newError(format, args...)
+	fieldError.Path = path
+	e.errors = append(e.errors, fieldError)
+}
+
+//Coerces the literal of the document, variables are replaced with their coerced values
+func coerceLiteral(value Value, t *Type, variables map[string]interface{}) (interface{}, error) {
+	if variable, ok := value.(Variable); ok {
+		value, ok := variables[string(variable)]
+		if (!ok || value == nil) && t.Kind == KindNonNull {
+			return nil, fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return value, nil
+	}
+	if t.Kind == KindNonNull {
+		if value == nil {
+			return nil, fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return coerceLiteral(value, t.OfType, variables)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	switch t.Kind {
+	case KindList:
+		items, ok := value.([]Value)
+		if !ok {
+			item, err := coerceLiteral(value, t.OfType, variables)
+			return []interface{}{item}, err
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			if result[i], err = coerceLiteral(item, t.OfType, variables); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case KindEnum:
+		if enumValue, ok := value.(EnumValue); ok {
+			return coerceInput(string(enumValue), t)
+		}
+		return nil, fmt.Errorf("expected value of enum %s", t)
+	}
+	if t == JSON {
+		return literalToJSON(value, variables), nil
+	}
+	switch value := value.(type) {
+	case int64, float64, string, bool:
+		return coerceInput(value, t)
+	}
+	return nil, fmt.Errorf("expected value of type %s", t)
+}
+
+//Converts the literal to the value decoded from JSON, so numbers are float64
+func literalToJSON(value Value, variables map[string]interface{}) interface{} {
+	switch value := value.(type) {
+	case Variable:
+		return variables[string(value)]
+	case EnumValue:
+		return string(value)
+	case int64:
+		return float64(value)
+	case []Value:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = literalToJSON(item, variables)
+		}
+		return items
+	case map[string]Value:
+		object := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			object[key] = literalToJSON(item, variables)
+		}
+		return object
+	}
+	return value
+}
+
+//Coerces the input value which is either the literal or the value of the variable decoded from JSON
+func coerceInput(value interface{}, t *Type) (interface{}, error) {
+	if t.Kind == KindNonNull {
+		if value == nil {
+			return nil, fmt.Errorf("expected non-null value of type %s", t)
+		}
+		return coerceInput(value, t.OfType)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	switch t.Kind {
+	case KindList:
+		items, ok := value.([]interface{})
+		if !ok {
+			item, err := coerceInput(value, t.OfType)
+			return []interface{}{item}, err
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			if result[i], err = coerceInput(item, t.OfType); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case KindEnum:
+		for _, enumValue := range t.EnumValues {
+			if value == enumValue {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("value %v is not a value of enum %s", value, t)
+	}
+
+	switch t {
+	case Int:
+		switch number := value.(type) {
+		case int64:
+			if number >= math.MinInt32 && number <= math.MaxInt32 {
+				return int(number), nil
+			}
+		case float64:
+			if number == math.Trunc(number) && math.Abs(number) <= math.MaxInt32 {
+				return int(number), nil
+			}
+		}
+	case Float:
+		switch number := value.(type) {
+		case int64:
+			return float64(number), nil
+		case float64:
+			return number, nil
+		}
+	case String:
+		if _, ok := value.(string); ok {
+			return value, nil
+		}
+	case Boolean:
+		if _, ok := value.(bool); ok {
+			return value, nil
+		}
+	case ID:
+		switch id := value.(type) {
+		case string:
+			return id, nil
+		case int64:
+			return strconv.FormatInt(id, 10), nil
+		case float64:
+			if id == math.Trunc(id) {
+				return strconv.FormatFloat(id, 'f', -1, 64), nil
+			}
+		}
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("%v is not a value of type %s", value, t)
+}
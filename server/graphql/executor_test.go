@@ -0,0 +1,205 @@
+package graphql_test
+
+import (
+	"custodian/server/graphql"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Executor", func() {
+	var schema *graphql.Schema
+	var depth int
+
+	execute := func(query string, variables map[string]interface{}) string {
+		result := schema.Execute(&graphql.Request{Query: query, Variables: variables}, nil)
+		encoded, err := json.Marshal(result)
+		Expect(err).To(BeNil())
+		return string(encoded)
+	}
+
+	BeforeEach(func() {
+		color := &graphql.Type{Kind: graphql.KindEnum, Name: "color", EnumValues: []string{"red", "green"}}
+		object := &graphql.Type{Kind: graphql.KindObject, Name: "a_object"}
+		object.Fields = []*graphql.Field{
+			{Name: "id", Type: graphql.NonNull(graphql.Int)},
+			{Name: "name", Type: graphql.String},
+			{Name: "color", Type: color},
+			{Name: "parent", Type: object},
+		}
+		other := &graphql.Type{Kind: graphql.KindObject, Name: "b_object", Fields: []*graphql.Field{{Name: "title", Type: graphql.String}}}
+		union := &graphql.Type{
+			Kind: graphql.KindUnion, Name: "generic", PossibleTypes: []*graphql.Type{object, other},
+			ResolveType: func(value interface{}) string { return value.(map[string]interface{})["_object"].(string) },
+		}
+		records := []interface{}{
+			map[string]interface{}{"id": 1.0, "name": "first", "color": "red"},
+			map[string]interface{}{"id": 2.0, "name": "second", "parent": map[string]interface{}{"id": 1.0, "name": "first"}},
+		}
+		query := &graphql.Type{Kind: graphql.KindObject, Name: "Query", Fields: []*graphql.Field{
+			{
+				Name: "a_object", Type: object,
+				Args: []*graphql.Argument{{Name: "key", Type: graphql.NonNull(graphql.ID)}},
+				Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+					depth = params.Depth()
+					for _, record := range records {
+						if id, _ := json.Marshal(record.(map[string]interface{})["id"]); string(id) == params.Args["key"] {
+							return record, nil
+						}
+					}
+					return nil, nil
+				},
+			},
+			{
+				Name: "a_object_list", Type: graphql.ListOf(graphql.NonNull(object)),
+				Args: []*graphql.Argument{{Name: "color", Type: color}, {Name: "limit", Type: graphql.Int, Default: 10}},
+				Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+					result := make([]interface{}, 0)
+					for _, record := range records {
+						if len(result) < params.Args["limit"].(int) && (params.Args["color"] == nil || record.(map[string]interface{})["color"] == params.Args["color"]) {
+							result = append(result, record)
+						}
+					}
+					return result, nil
+				},
+			},
+			{
+				Name: "broken", Type: graphql.ListOf(graphql.NonNull(object)),
+				Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+					return []interface{}{map[string]interface{}{"name": "without id"}}, nil
+				},
+			},
+			{
+				Name: "generic", Type: graphql.ListOf(union),
+				Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+					return []interface{}{
+						map[string]interface{}{"_object": "a_object", "id": 1.0},
+						map[string]interface{}{"_object": "b_object", "title": "other"},
+					}, nil
+				},
+			},
+		}}
+		mutation := &graphql.Type{Kind: graphql.KindObject, Name: "Mutation", Fields: []*graphql.Field{
+			{
+				Name: "create_a_object", Type: graphql.NonNull(object),
+				Args: []*graphql.Argument{{Name: "data", Type: graphql.NonNull(graphql.JSON)}},
+				Resolve: func(params *graphql.ResolveParams) (interface{}, error) {
+					if params.Args["data"].(map[string]interface{})["name"] == nil {
+						return nil, errors.New("name is required")
+					}
+					return params.Args["data"], nil
+				},
+			},
+		}}
+		var err error
+		schema, err = graphql.NewSchema(query, mutation)
+		Expect(err).To(BeNil())
+	})
+
+	It("resolves fields in the order of the selection", func() {
+		Expect(execute(`{ a_object(key: 2) { name id parent { id } } }`, nil)).To(Equal(
+			`{"data":{"a_object":{"name":"second","id":2,"parent":{"id":1}}}}`,
+		))
+		Expect(depth).To(Equal(2))
+	})
+
+	It("applies aliases, fragments and directives", func() {
+		query := `query($skip: Boolean!) {
+			first: a_object(key: "1") { ...fields }
+			second: a_object(key: "2") { ... on a_object { id } name @skip(if: $skip) }
+		}
+		fragment fields on a_object { id color }`
+
+		Expect(execute(query, map[string]interface{}{"skip": true})).To(Equal(
+			`{"data":{"first":{"id":1,"color":"red"},"second":{"id":2}}}`,
+		))
+	})
+
+	It("coerces enum and default arguments", func() {
+		Expect(execute(`{ a_object_list(color: red) { id } }`, nil)).To(Equal(`{"data":{"a_object_list":[{"id":1}]}}`))
+		Expect(execute(`query($limit: Int) { a_object_list(limit: $limit) { id } }`, map[string]interface{}{"limit": 1.0})).To(Equal(
+			`{"data":{"a_object_list":[{"id":1}]}}`,
+		))
+		Expect(execute(`query($limit: Int) { a_object_list(limit: $limit) { id } }`, nil)).To(Equal(
+			`{"data":{"a_object_list":[{"id":1},{"id":2}]}}`,
+		))
+	})
+
+	It("resolves concrete types of unions", func() {
+		Expect(execute(`{ generic { __typename ... on a_object { id } ... on b_object { title } } }`, nil)).To(Equal(
+			`{"data":{"generic":[{"__typename":"a_object","id":1},{"__typename":"b_object","title":"other"}]}}`,
+		))
+	})
+
+	It("propagates null of non-null fields to the nearest nullable parent", func() {
+		Expect(execute(`{ broken { id name } }`, nil)).To(Equal(
+			`{"data":{"broken":null},"errors":[{"message":"Cannot return null for non-nullable field","path":["broken",0,"id"]}]}`,
+		))
+	})
+
+	It("returns errors of resolvers with the path", func() {
+		Expect(execute(`mutation { create_a_object(data: {id: 3}) { id } }`, nil)).To(Equal(
+			`{"data":null,"errors":[{"message":"name is required","path":["create_a_object"]}]}`,
+		))
+		Expect(execute(`mutation($data: JSON!) { create_a_object(data: $data) { id name } }`, map[string]interface{}{"data": map[string]interface{}{"id": 3.0, "name": "third"}})).To(Equal(
+			`{"data":{"create_a_object":{"id":3,"name":"third"}}}`,
+		))
+	})
+
+	It("rejects invalid requests without data", func() {
+		Expect(execute(`{ a_object(key: 1) { unknown } }`, nil)).To(Equal(
+			`{"errors":[{"message":"Cannot query field \"unknown\" on type \"a_object\""}]}`,
+		))
+		Expect(execute(`{ a_object { id } }`, nil)).To(Equal(
+			`{"errors":[{"message":"Argument \"key\" of type \"ID!\" is required on field \"a_object\""}]}`,
+		))
+		Expect(execute(`{ a_object(key: 1) }`, nil)).To(Equal(
+			`{"errors":[{"message":"Field \"a_object\" of type \"a_object\" must have a selection of subfields"}]}`,
+		))
+		Expect(execute(`query($key: ID!) { a_object(key: $key) { id } }`, nil)).To(Equal(
+			`{"errors":[{"message":"Variable \"$key\" of required type \"ID!\" was not provided"}]}`,
+		))
+	})
+
+	It("rejects operations exceeding the depth limit", func() {
+		schema.Limits = graphql.Limits{MaxDepth: 2}
+		Expect(execute(`{ a_object(key: 2) { parent { id } } }`, nil)).To(Equal(
+			`{"errors":[{"message":"Query depth 3 exceeds the maximum depth of 2"}]}`,
+		))
+		Expect(execute(`{ a_object(key: 2) { ...parent } } fragment parent on a_object { parent { id } }`, nil)).To(Equal(
+			`{"errors":[{"message":"Query depth 3 exceeds the maximum depth of 2"}]}`,
+		))
+		Expect(execute(`{ a_object(key: 2) { id parent } }`, nil)).NotTo(ContainSubstring("maximum depth"))
+	})
+
+	It("rejects operations exceeding the complexity limit", func() {
+		schema.Limits = graphql.Limits{MaxComplexity: 5}
+		Expect(execute(`{ a_object(key: 2) { id name parent { id } } }`, nil)).To(Equal(
+			`{"data":{"a_object":{"id":2,"name":"second","parent":{"id":1}}}}`,
+		))
+		Expect(execute(`{ a_object(key: 2) { ...fields parent { ...fields } } } fragment fields on a_object { id name }`, nil)).To(Equal(
+			`{"errors":[{"message":"Query complexity exceeds the maximum complexity of 5"}]}`,
+		))
+	})
+
+	It("introspects the schema", func() {
+		Expect(execute(`{ __type(name: "a_object") { kind fields { name type { kind name ofType { name } } } } }`, nil)).To(Equal(
+			`{"data":{"__type":{"kind":"OBJECT","fields":[` +
+				`{"name":"id","type":{"kind":"NON_NULL","name":null,"ofType":{"name":"Int"}}},` +
+				`{"name":"name","type":{"kind":"SCALAR","name":"String","ofType":null}},` +
+				`{"name":"color","type":{"kind":"ENUM","name":"color","ofType":null}},` +
+				`{"name":"parent","type":{"kind":"OBJECT","name":"a_object","ofType":null}}]}}}`,
+		))
+		Expect(execute(`{ __schema { queryType { name } mutationType { name } } }`, nil)).To(Equal(
+			`{"data":{"__schema":{"queryType":{"name":"Query"},"mutationType":{"name":"Mutation"}}}}`,
+		))
+	})
+
+	It("prints the schema", func() {
+		Expect(schema.String()).To(ContainSubstring("type Query {\n  a_object(key: ID!): a_object\n  a_object_list(color: color, limit: Int = 10): [a_object!]\n"))
+		Expect(schema.String()).To(ContainSubstring("union generic = a_object | b_object\n"))
+		Expect(schema.String()).To(ContainSubstring("enum color {\n  red\n  green\n}\n"))
+	})
+})
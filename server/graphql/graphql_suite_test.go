@@ -0,0 +1,20 @@
+package graphql_test
+
+import (
+	"github.com/onsi/ginkgo/reporters"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestServer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	if ci := os.Getenv("CI"); ci != "" {
+		teamcityReporter := reporters.NewTeamCityReporter(os.Stdout)
+		RunSpecsWithCustomReporters(t, "GraphQL Suite", []Reporter{teamcityReporter})
+	} else {
+		RunSpecs(t, "GraphQL Suite")
+	}
+}
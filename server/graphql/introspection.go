@@ -0,0 +1,167 @@
+package graphql
+
+//Types of the introspection system, the values of their fields are taken from the maps built by buildIntrospection
+var (
+	introspectionSchemaType    = &Type{Kind: KindObject, Name: "__Schema"}
+	introspectionTypeType      = &Type{Kind: KindObject, Name: "__Type"}
+	introspectionFieldType     = &Type{Kind: KindObject, Name: "__Field"}
+	introspectionInputType     = &Type{Kind: KindObject, Name: "__InputValue"}
+	introspectionEnumValueType = &Type{Kind: KindObject, Name: "__EnumValue"}
+	introspectionDirectiveType = &Type{Kind: KindObject, Name: "__Directive"}
+	introspectionTypeKindType  = &Type{
+		Kind: KindEnum, Name: "__TypeKind",
+		EnumValues: []string{"SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL"},
+	}
+	introspectionLocationType = &Type{
+		Kind: KindEnum, Name: "__DirectiveLocation",
+		EnumValues: []string{"QUERY", "MUTATION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+	}
+)
+
+func init() {
+	includeDeprecated := []*Argument{{Name: "includeDeprecated", Type: Boolean, Default: false}}
+	introspectionSchemaType.Fields = []*Field{
+		{Name: "description", Type: String},
+		{Name: "types", Type: NonNull(ListOf(NonNull(introspectionTypeType)))},
+		{Name: "queryType", Type: NonNull(introspectionTypeType)},
+		{Name: "mutationType", Type: introspectionTypeType},
+		{Name: "subscriptionType", Type: introspectionTypeType},
+		{Name: "directives", Type: NonNull(ListOf(NonNull(introspectionDirectiveType)))},
+	}
+	introspectionTypeType.Fields = []*Field{
+		{Name: "kind", Type: NonNull(introspectionTypeKindType)},
+		{Name: "name", Type: String},
+		{Name: "description", Type: String},
+		{Name: "specifiedByURL", Type: String},
+		{Name: "fields", Type: ListOf(NonNull(introspectionFieldType)), Args: includeDeprecated},
+		{Name: "interfaces", Type: ListOf(NonNull(introspectionTypeType))},
+		{Name: "possibleTypes", Type: ListOf(NonNull(introspectionTypeType))},
+		{Name: "enumValues", Type: ListOf(NonNull(introspectionEnumValueType)), Args: includeDeprecated},
+		{Name: "inputFields", Type: ListOf(NonNull(introspectionInputType)), Args: includeDeprecated},
+		{Name: "ofType", Type: introspectionTypeType},
+	}
+	introspectionFieldType.Fields = []*Field{
+		{Name: "name", Type: NonNull(String)},
+		{Name: "description", Type: String},
+		{Name: "args", Type: NonNull(ListOf(NonNull(introspectionInputType))), Args: includeDeprecated},
+		{Name: "type", Type: NonNull(introspectionTypeType)},
+		{Name: "isDeprecated", Type: NonNull(Boolean)},
+		{Name: "deprecationReason", Type: String},
+	}
+	introspectionInputType.Fields = []*Field{
+		{Name: "name", Type: NonNull(String)},
+		{Name: "description", Type: String},
+		{Name: "type", Type: NonNull(introspectionTypeType)},
+		{Name: "defaultValue", Type: String},
+		{Name: "isDeprecated", Type: NonNull(Boolean)},
+		{Name: "deprecationReason", Type: String},
+	}
+	introspectionEnumValueType.Fields = []*Field{
+		{Name: "name", Type: NonNull(String)},
+		{Name: "description", Type: String},
+		{Name: "isDeprecated", Type: NonNull(Boolean)},
+		{Name: "deprecationReason", Type: String},
+	}
+	introspectionDirectiveType.Fields = []*Field{
+		{Name: "name", Type: NonNull(String)},
+		{Name: "description", Type: String},
+		{Name: "isRepeatable", Type: NonNull(Boolean)},
+		{Name: "locations", Type: NonNull(ListOf(NonNull(introspectionLocationType)))},
+		{Name: "args", Type: NonNull(ListOf(NonNull(introspectionInputType))), Args: includeDeprecated},
+	}
+}
+
+//Directives supported by the executor
+var directives = []map[string]interface{}{
+	{"name": "include", "description": "Includes the field or the fragment only when the argument is true"},
+	{"name": "skip", "description": "Skips the field or the fragment when the argument is true"},
+}
+
+//Builds the data of the __schema field. Named types are built once, so that type references share them
+func (s *Schema) buildIntrospection() map[string]interface{} {
+	named := make(map[string]map[string]interface{}, len(s.types))
+	for name := range s.types {
+		named[name] = make(map[string]interface{})
+	}
+	var typeRef func(t *Type) map[string]interface{}
+	typeRef = func(t *Type) map[string]interface{} {
+		if t.OfType != nil {
+			return map[string]interface{}{"kind": string(t.Kind), "ofType": typeRef(t.OfType)}
+		}
+		return named[t.Name]
+	}
+	inputValue := func(arg *Argument) map[string]interface{} {
+		var defaultValue interface{}
+		if arg.Default != nil {
+			defaultValue = printValue(arg.Default)
+		}
+		return map[string]interface{}{
+			"name": arg.Name, "description": description(arg.Description), "type": typeRef(arg.Type),
+			"defaultValue": defaultValue, "isDeprecated": false,
+		}
+	}
+
+	types := make([]interface{}, 0, len(s.types))
+	for _, t := range s.Types() {
+		data := named[t.Name]
+		data["kind"] = string(t.Kind)
+		data["name"] = t.Name
+		data["description"] = description(t.Description)
+		switch t.Kind {
+		case KindObject:
+			fields := make([]interface{}, 0, len(t.Fields))
+			for _, field := range t.Fields {
+				args := make([]interface{}, 0, len(field.Args))
+				for _, arg := range field.Args {
+					args = append(args, inputValue(arg))
+				}
+				fields = append(fields, map[string]interface{}{
+					"name": field.Name, "description": description(field.Description), "args": args,
+					"type": typeRef(field.Type), "isDeprecated": false,
+				})
+			}
+			data["fields"] = fields
+			data["interfaces"] = []interface{}{}
+		case KindUnion:
+			possibleTypes := make([]interface{}, 0, len(t.PossibleTypes))
+			for _, possibleType := range t.PossibleTypes {
+				possibleTypes = append(possibleTypes, named[possibleType.Name])
+			}
+			data["possibleTypes"] = possibleTypes
+		case KindEnum:
+			enumValues := make([]interface{}, 0, len(t.EnumValues))
+			for _, value := range t.EnumValues {
+				enumValues = append(enumValues, map[string]interface{}{"name": value, "isDeprecated": false})
+			}
+			data["enumValues"] = enumValues
+		}
+		types = append(types, data)
+	}
+
+	directiveData := make([]interface{}, 0, len(directives))
+	for _, directive := range directives {
+		data := map[string]interface{}{
+			"isRepeatable": false,
+			"locations":    []interface{}{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+			"args":         []interface{}{inputValue(&Argument{Name: "if", Type: NonNull(Boolean)})},
+		}
+		for key, value := range directive {
+			data[key] = value
+		}
+		directiveData = append(directiveData, data)
+	}
+
+	schema := map[string]interface{}{"types": types, "queryType": named[s.Query.Name], "directives": directiveData}
+	if s.Mutation != nil {
+		schema["mutationType"] = named[s.Mutation.Name]
+	}
+	return schema
+}
+
+//Empty descriptions are returned as null
+func description(text string) interface{} {
+	if text == "" {
+		return nil
+	}
+	return text
+}
@@ -0,0 +1,246 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "<EOF>"
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+type lexer struct {
+	source string
+	pos    int
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+//Returns the next token skipping whitespaces, commas and comments
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			l.pos++
+		} else if c == '#' {
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' && l.source[l.pos] != '\r' {
+				l.pos++
+			}
+		} else if strings.HasPrefix(l.source[l.pos:], "\uFEFF") {
+			l.pos += len("\uFEFF")
+		} else {
+			break
+		}
+	}
+	if l.pos == len(l.source) {
+		return token{tokenEOF, "", l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.source[l.pos]
+	switch {
+	case strings.HasPrefix(l.source[l.pos:], "..."):
+		l.pos += 3
+		return token{tokenPunctuator, "...", start}, nil
+	case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+		l.pos++
+		return token{tokenPunctuator, string(c), start}, nil
+	case isNameStart(c):
+		for l.pos < len(l.source) && (isNameStart(l.source[l.pos]) || isDigit(l.source[l.pos])) {
+			l.pos++
+		}
+		return token{tokenName, l.source[start:l.pos], start}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case strings.HasPrefix(l.source[l.pos:], `"""`):
+		return l.blockString()
+	case c == '"':
+		return l.string()
+	}
+	r, _ := utf8.DecodeRuneInString(l.source[l.pos:])
+	return token{}, l.errorf("unexpected character %q", r)
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	kind := tokenInt
+	if l.source[l.pos] == '-' {
+		l.pos++
+	}
+	if !l.digits() {
+		return token{}, l.errorf("invalid number")
+	}
+	if l.pos < len(l.source) && l.source[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		if !l.digits() {
+			return token{}, l.errorf("invalid number")
+		}
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.source) && (l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.pos++
+		}
+		if !l.digits() {
+			return token{}, l.errorf("invalid number")
+		}
+	}
+	if l.pos < len(l.source) && (isNameStart(l.source[l.pos]) || l.source[l.pos] == '.') {
+		return token{}, l.errorf("invalid number")
+	}
+	return token{kind, l.source[start:l.pos], start}, nil
+}
+
+func (l *lexer) digits() bool {
+	start := l.pos
+	for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+		l.pos++
+	}
+	return l.pos > start
+}
+
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{tokenString, b.String(), start}, nil
+		case c == '\n' || c == '\r':
+			return token{}, l.errorf("unterminated string")
+		case c == '\\':
+			if l.pos+1 == len(l.source) {
+				return token{}, l.errorf("unterminated string")
+			}
+			escaped := l.source[l.pos+1]
+			l.pos += 2
+			switch escaped {
+			case '"', '\\', '/':
+				b.WriteByte(escaped)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.source) {
+					return token{}, l.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.source[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, l.errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				l.pos += 4
+			default:
+				return token{}, l.errorf("invalid escape sequence \\%c", escaped)
+			}
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, l.errorf("unterminated string")
+}
+
+//Block strings are kept raw except the common indentation and the leading and trailing blank lines
+func (l *lexer) blockString() (token, error) {
+	start := l.pos
+	l.pos += 3
+	end := strings.Index(l.source[l.pos:], `"""`)
+	for end > 0 && l.source[l.pos+end-1] == '\\' {
+		next := strings.Index(l.source[l.pos+end+1:], `"""`)
+		if next < 0 {
+			end = -1
+			break
+		}
+		end += next + 1
+	}
+	if end < 0 {
+		return token{}, l.errorf("unterminated string")
+	}
+	raw := strings.Replace(l.source[l.pos:l.pos+end], `\"""`, `"""`, -1)
+	l.pos += end + 3
+	return token{tokenString, blockStringValue(raw), start}, nil
+}
+
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.Replace(strings.Replace(raw, "\r\n", "\n", -1), "\r", "\n", -1), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return syntaxError(l.source, l.pos, format, args...)
+}
+
+func syntaxError(source string, pos int, format string, args ...interface{}) error {
+	line, column := 1, 1
+	for _, c := range source[:pos] {
+		if c == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	return &Error{Message: fmt.Sprintf("Syntax error: "+format, args...), Locations: []Location{{line, column}}}
+}
@@ -0,0 +1,62 @@
+package graphql
+
+//Limits of the operation which are checked before its execution, zero value means no limit
+type Limits struct {
+	//depth of nested fields, eg: 2 for "{ a { b } }"
+	MaxDepth int
+	//number of fields selected by the operation, fields of fragments are counted each time the fragment is spread
+	MaxComplexity int
+}
+
+//Measures the operation, measuring is stopped once the complexity exceeds the limit,
+//so that fragments spread over and over again can't make it expensive
+type operationMeasure struct {
+	fragments     map[string]*Fragment
+	maxComplexity int
+	depth         int
+	complexity    int
+}
+
+func (m *operationMeasure) exceeded() bool {
+	return m.maxComplexity > 0 && m.complexity > m.maxComplexity
+}
+
+func (m *operationMeasure) selectionSet(selectionSet []Selection, level int, visited map[string]bool) {
+	for _, selection := range selectionSet {
+		if m.exceeded() {
+			return
+		}
+		switch selection := selection.(type) {
+		case *SelectedField:
+			m.complexity++
+			if level+1 > m.depth {
+				m.depth = level + 1
+			}
+			m.selectionSet(selection.SelectionSet, level+1, visited)
+		case *InlineFragment:
+			m.selectionSet(selection.SelectionSet, level, visited)
+		case *FragmentSpread:
+			if fragment, ok := m.fragments[selection.Name]; ok && !visited[selection.Name] {
+				visited[selection.Name] = true
+				m.selectionSet(fragment.SelectionSet, level, visited)
+				delete(visited, selection.Name)
+			}
+		}
+	}
+}
+
+//Checks the depth and the complexity of the operation against the limits
+func (l Limits) check(operation *Operation, fragments map[string]*Fragment) error {
+	if l.MaxDepth <= 0 && l.MaxComplexity <= 0 {
+		return nil
+	}
+	measure := &operationMeasure{fragments: fragments, maxComplexity: l.MaxComplexity}
+	measure.selectionSet(operation.SelectionSet, 0, make(map[string]bool))
+	if l.MaxDepth > 0 && measure.depth > l.MaxDepth {
+		return newError("Query depth %d exceeds the maximum depth of %d", measure.depth, l.MaxDepth)
+	}
+	if measure.exceeded() {
+		return newError("Query complexity exceeds the maximum complexity of %d", l.MaxComplexity)
+	}
+	return nil
+}
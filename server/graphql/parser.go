@@ -0,0 +1,421 @@
+package graphql
+
+import (
+	"strconv"
+)
+
+type parser struct {
+	lexer *lexer
+	token token
+}
+
+//Parses the executable document, the query shorthand "{ ... }" is parsed as the anonymous query
+func Parse(source string) (*Document, error) {
+	p := &parser{lexer: &lexer{source: source}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	document := &Document{Fragments: make(map[string]*Fragment)}
+	for p.token.kind != tokenEOF {
+		if p.peek("{") {
+			selectionSet, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			document.Operations = append(document.Operations, &Operation{Type: OperationQuery, SelectionSet: selectionSet})
+		} else if p.peekName("query") || p.peekName("mutation") {
+			operation, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			document.Operations = append(document.Operations, operation)
+		} else if p.peekName("fragment") {
+			fragment, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := document.Fragments[fragment.Name]; ok {
+				return nil, &Error{Message: "There can be only one fragment named \"" + fragment.Name + "\""}
+			}
+			document.Fragments[fragment.Name] = fragment
+		} else {
+			return nil, p.unexpected()
+		}
+	}
+	if len(document.Operations) == 0 {
+		return nil, &Error{Message: "Document does not contain any operation"}
+	}
+	return document, nil
+}
+
+func (p *parser) advance() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = t
+	return nil
+}
+
+func (p *parser) peek(punctuator string) bool {
+	return p.token.kind == tokenPunctuator && p.token.value == punctuator
+}
+
+func (p *parser) peekName(name string) bool {
+	return p.token.kind == tokenName && p.token.value == name
+}
+
+//Skips the punctuator if it is the current token
+func (p *parser) skip(punctuator string) (bool, error) {
+	if !p.peek(punctuator) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(punctuator string) error {
+	if !p.peek(punctuator) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.token.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.token.value
+	return name, p.advance()
+}
+
+func (p *parser) unexpected() error {
+	return syntaxError(p.lexer.source, p.token.pos, "unexpected %s", p.token)
+}
+
+func (p *parser) operation() (*Operation, error) {
+	operation := &Operation{Type: p.token.value}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.token.kind == tokenName {
+		operation.Name = p.token.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			variable, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			operation.Variables = append(operation.Variables, variable)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	selectionSet, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	operation.SelectionSet = selectionSet
+	return operation, nil
+}
+
+func (p *parser) variableDefinition() (*VariableDefinition, error) {
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	typeRef, err := p.typeRef()
+	if err != nil {
+		return nil, err
+	}
+	variable := &VariableDefinition{Name: name, Type: typeRef}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if variable.Default, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	return variable, nil
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	typeRef := &TypeRef{}
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		if typeRef.OfType, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else if typeRef.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	nonNull, err := p.skip("!")
+	typeRef.NonNull = nonNull
+	return typeRef, err
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	fragment := &Fragment{}
+	var err error
+	if fragment.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if fragment.Name == "on" {
+		return nil, p.unexpected()
+	}
+	if !p.peekName("on") {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if fragment.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	if fragment.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	selections := make([]Selection, 0)
+	for {
+		if ok, err := p.skip("}"); err != nil {
+			return nil, err
+		} else if ok {
+			break
+		}
+		selection, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, p.unexpected()
+	}
+	return selections, nil
+}
+
+func (p *parser) selection() (Selection, error) {
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragmentSelection()
+	}
+
+	field := &SelectedField{Arguments: make(map[string]Value)}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		field.Alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	field.Name = name
+	if field.Arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if field.SelectionSet, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) fragmentSelection() (Selection, error) {
+	if p.token.kind == tokenName && !p.peekName("on") {
+		spread := &FragmentSpread{Name: p.token.value}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		spread.Directives, err = p.directives()
+		return spread, err
+	}
+	fragment := &InlineFragment{}
+	var err error
+	if p.peekName("on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if fragment.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if fragment.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if fragment.SelectionSet, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) arguments(constant bool) (map[string]Value, error) {
+	arguments := make(map[string]Value)
+	if ok, err := p.skip("("); err != nil || !ok {
+		return arguments, err
+	}
+	for {
+		if ok, err := p.skip(")"); err != nil {
+			return nil, err
+		} else if ok {
+			break
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arguments[name], err = p.value(constant); err != nil {
+			return nil, err
+		}
+	}
+	return arguments, nil
+}
+
+func (p *parser) directives() ([]*Directive, error) {
+	directives := make([]*Directive, 0)
+	for p.peek("@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		arguments, err := p.arguments(false)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &Directive{Name: name, Arguments: arguments})
+	}
+	return directives, nil
+}
+
+//Parses the value, variables are not allowed in constant values, eg: defaults of variables
+func (p *parser) value(constant bool) (Value, error) {
+	t := p.token
+	switch t.kind {
+	case tokenInt:
+		value, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, syntaxError(p.lexer.source, t.pos, "invalid integer %s", t.value)
+		}
+		return value, p.advance()
+	case tokenFloat:
+		value, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, syntaxError(p.lexer.source, t.pos, "invalid float %s", t.value)
+		}
+		return value, p.advance()
+	case tokenString:
+		return t.value, p.advance()
+	case tokenName:
+		var value Value
+		switch t.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = EnumValue(t.value)
+		}
+		return value, p.advance()
+	}
+
+	switch {
+	case p.peek("$") && !constant:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return Variable(name), err
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		list := make([]Value, 0)
+		for {
+			if ok, err := p.skip("]"); err != nil {
+				return nil, err
+			} else if ok {
+				return list, nil
+			}
+			item, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		object := make(map[string]Value)
+		for {
+			if ok, err := p.skip("}"); err != nil {
+				return nil, err
+			} else if ok {
+				return object, nil
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.value(constant); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, p.unexpected()
+}
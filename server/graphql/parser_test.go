@@ -0,0 +1,73 @@
+package graphql_test
+
+import (
+	"custodian/server/graphql"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parser", func() {
+	It("parses the query shorthand", func() {
+		document, err := graphql.Parse(`{ a_object(key: 1) { id name } }`)
+
+		Expect(err).To(BeNil())
+		Expect(document.Operations).To(HaveLen(1))
+		Expect(document.Operations[0].Type).To(Equal(graphql.OperationQuery))
+		field := document.Operations[0].SelectionSet[0].(*graphql.SelectedField)
+		Expect(field.Name).To(Equal("a_object"))
+		Expect(field.Arguments["key"]).To(Equal(int64(1)))
+		Expect(field.SelectionSet).To(HaveLen(2))
+	})
+
+	It("parses variables, aliases, fragments and directives", func() {
+		document, err := graphql.Parse(`
+			# comment
+			mutation Create($data: JSON!, $flag: Boolean = true, $keys: [ID!]) {
+				created: create_a_object(data: $data) @include(if: $flag) { ...fields }
+			}
+			fragment fields on a_object { id ... on a_object { name } }
+		`)
+
+		Expect(err).To(BeNil())
+		operation := document.Operations[0]
+		Expect(operation.Type).To(Equal(graphql.OperationMutation))
+		Expect(operation.Name).To(Equal("Create"))
+		Expect(operation.Variables).To(HaveLen(3))
+		Expect(operation.Variables[0].Type.String()).To(Equal("JSON!"))
+		Expect(operation.Variables[1].Default).To(Equal(true))
+		Expect(operation.Variables[2].Type.String()).To(Equal("[ID!]"))
+
+		field := operation.SelectionSet[0].(*graphql.SelectedField)
+		Expect(field.ResponseKey()).To(Equal("created"))
+		Expect(field.Arguments["data"]).To(Equal(graphql.Variable("data")))
+		Expect(field.Directives[0].Name).To(Equal("include"))
+		Expect(field.SelectionSet[0]).To(Equal(&graphql.FragmentSpread{Name: "fields", Directives: []*graphql.Directive{}}))
+		Expect(document.Fragments["fields"].TypeCondition).To(Equal("a_object"))
+	})
+
+	It("parses values", func() {
+		document, err := graphql.Parse(`{ f(a: [1, 2.5, "s\n", null, ENUM], b: {c: false}, d: """
+			block
+		""") }`)
+
+		Expect(err).To(BeNil())
+		arguments := document.Operations[0].SelectionSet[0].(*graphql.SelectedField).Arguments
+		Expect(arguments["a"]).To(Equal([]graphql.Value{int64(1), 2.5, "s\n", nil, graphql.EnumValue("ENUM")}))
+		Expect(arguments["b"]).To(Equal(map[string]graphql.Value{"c": false}))
+		Expect(arguments["d"]).To(Equal("block"))
+	})
+
+	It("returns the location of the syntax error", func() {
+		_, err := graphql.Parse("{\n  a(b: ) }")
+
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*graphql.Error).Locations).To(Equal([]graphql.Location{{Line: 2, Column: 8}}))
+	})
+
+	It("rejects variables in default values", func() {
+		_, err := graphql.Parse(`query($a: Int = $b) { f }`)
+
+		Expect(err).NotTo(BeNil())
+	})
+})
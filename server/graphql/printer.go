@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Prints the value as the GraphQL literal
+func printValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(value)
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = printValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = key + ": " + printValue(value[key])
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return fmt.Sprint(value)
+}
+
+//Prints the schema in the schema definition language, built-in scalars and introspection types are omitted
+func (s *Schema) String() string {
+	var b strings.Builder
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n")
+	if s.Mutation != nil {
+		b.WriteString("  mutation: " + s.Mutation.Name + "\n")
+	}
+	b.WriteString("}\n")
+	for _, t := range s.Types() {
+		if strings.HasPrefix(t.Name, "__") || t == Int || t == Float || t == String || t == Boolean || t == ID {
+			continue
+		}
+		b.WriteString("\n")
+		if t.Description != "" {
+			b.WriteString(strconv.Quote(t.Description) + "\n")
+		}
+		switch t.Kind {
+		case KindScalar:
+			b.WriteString("scalar " + t.Name + "\n")
+		case KindEnum:
+			b.WriteString("enum " + t.Name + " {\n")
+			for _, value := range t.EnumValues {
+				b.WriteString("  " + value + "\n")
+			}
+			b.WriteString("}\n")
+		case KindUnion:
+			names := make([]string, len(t.PossibleTypes))
+			for i, possibleType := range t.PossibleTypes {
+				names[i] = possibleType.Name
+			}
+			b.WriteString("union " + t.Name + " = " + strings.Join(names, " | ") + "\n")
+		case KindObject:
+			b.WriteString("type " + t.Name + " {\n")
+			for _, field := range t.Fields {
+				if field.Description != "" {
+					b.WriteString("  " + strconv.Quote(field.Description) + "\n")
+				}
+				b.WriteString("  " + field.Name)
+				if len(field.Args) > 0 {
+					args := make([]string, len(field.Args))
+					for i, arg := range field.Args {
+						args[i] = arg.Name + ": " + arg.Type.String()
+						if arg.Default != nil {
+							args[i] += " = " + printValue(arg.Default)
+						}
+					}
+					b.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				b.WriteString(": " + field.Type.String() + "\n")
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
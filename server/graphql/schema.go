@@ -0,0 +1,285 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+)
+
+type Kind string
+
+const (
+	KindScalar  Kind = "SCALAR"
+	KindObject  Kind = "OBJECT"
+	KindUnion   Kind = "UNION"
+	KindEnum    Kind = "ENUM"
+	KindList    Kind = "LIST"
+	KindNonNull Kind = "NON_NULL"
+)
+
+//Named or wrapping type: the list or the non-null one wraps OfType
+type Type struct {
+	Kind        Kind
+	Name        string
+	Description string
+	Fields      []*Field
+	//possible types of the union
+	PossibleTypes []*Type
+	//returns the name of the concrete type of the union value
+	ResolveType func(value interface{}) string
+	EnumValues  []string
+	//converts the value to the result of the scalar, eg: float64 to integer
+	Serialize func(value interface{}) (interface{}, error)
+	OfType    *Type
+}
+
+func (t *Type) String() string {
+	switch t.Kind {
+	case KindList:
+		return "[" + t.OfType.String() + "]"
+	case KindNonNull:
+		return t.OfType.String() + "!"
+	}
+	return t.Name
+}
+
+//Returns the named type unwrapping lists and non-null types
+func (t *Type) Named() *Type {
+	for t.OfType != nil {
+		t = t.OfType
+	}
+	return t
+}
+
+func (t *Type) Field(name string) *Field {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func ListOf(t *Type) *Type {
+	return &Type{Kind: KindList, OfType: t}
+}
+
+func NonNull(t *Type) *Type {
+	return &Type{Kind: KindNonNull, OfType: t}
+}
+
+type Field struct {
+	Name        string
+	Description string
+	Type        *Type
+	Args        []*Argument
+	//the value of the source object under the name of the field is returned if it is not set
+	Resolve func(params *ResolveParams) (interface{}, error)
+}
+
+func (f *Field) Arg(name string) *Argument {
+	for _, arg := range f.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+type Argument struct {
+	Name        string
+	Description string
+	Type        *Type
+	Default     interface{}
+}
+
+type ResolveParams struct {
+	//value of the parent object, it is nil for root fields
+	Source  map[string]interface{}
+	Args    map[string]interface{}
+	Context interface{}
+	//selection of the field, it can be used to look ahead the requested subfields
+	Selection *SelectedField
+	fragments map[string]*Fragment
+}
+
+//Returns the depth of the selection set of the field: 0 for scalars, 1 if only scalar subfields are selected and so on
+func (p *ResolveParams) Depth() int {
+	return selectionDepth(p.Selection.SelectionSet, p.fragments, make(map[string]bool))
+}
+
+func selectionDepth(selectionSet []Selection, fragments map[string]*Fragment, visited map[string]bool) int {
+	if len(selectionSet) == 0 {
+		return 0
+	}
+	depth := 1
+	for _, selection := range selectionSet {
+		var nested int
+		switch selection := selection.(type) {
+		case *SelectedField:
+			if nested = selectionDepth(selection.SelectionSet, fragments, visited); nested > 0 {
+				nested++
+			}
+		case *InlineFragment:
+			nested = selectionDepth(selection.SelectionSet, fragments, visited)
+		case *FragmentSpread:
+			if fragment, ok := fragments[selection.Name]; ok && !visited[selection.Name] {
+				visited[selection.Name] = true
+				nested = selectionDepth(fragment.SelectionSet, fragments, visited)
+				delete(visited, selection.Name)
+			}
+		}
+		if nested > depth {
+			depth = nested
+		}
+	}
+	return depth
+}
+
+var (
+	Int     = &Type{Kind: KindScalar, Name: "Int", Description: "Signed 32-bit integer", Serialize: serializeInt}
+	Float   = &Type{Kind: KindScalar, Name: "Float", Description: "Double-precision floating-point value", Serialize: serializeFloat}
+	String  = &Type{Kind: KindScalar, Name: "String", Description: "UTF-8 character sequence", Serialize: serializeString}
+	Boolean = &Type{Kind: KindScalar, Name: "Boolean", Description: "true or false", Serialize: serializeBoolean}
+	ID      = &Type{Kind: KindScalar, Name: "ID", Description: "Unique identifier serialized as a string", Serialize: serializeID}
+	//arbitrary JSON value
+	JSON = &Type{Kind: KindScalar, Name: "JSON", Description: "Arbitrary JSON value", Serialize: func(value interface{}) (interface{}, error) { return value, nil }}
+)
+
+func serializeInt(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case int:
+		return value, nil
+	case int64:
+		return value, nil
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) <= math.MaxInt32 {
+			return int64(value), nil
+		}
+	}
+	return nil, fmt.Errorf("Int cannot represent value: %v", value)
+}
+
+func serializeFloat(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	}
+	return nil, fmt.Errorf("Float cannot represent value: %v", value)
+}
+
+func serializeString(value interface{}) (interface{}, error) {
+	if value, ok := value.(string); ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("String cannot represent value: %v", value)
+}
+
+func serializeBoolean(value interface{}) (interface{}, error) {
+	if value, ok := value.(bool); ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent value: %v", value)
+}
+
+func serializeID(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case int, int64:
+		return fmt.Sprint(value), nil
+	case float64:
+		if value == math.Trunc(value) {
+			return fmt.Sprintf("%.0f", value), nil
+		}
+	}
+	return nil, fmt.Errorf("ID cannot represent value: %v", value)
+}
+
+var nameRegexp = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+//Checks if the string can be used as the name of the type, the field or the enum value
+func IsName(name string) bool {
+	return nameRegexp.MatchString(name)
+}
+
+type Schema struct {
+	Query    *Type
+	Mutation *Type
+	//operations exceeding the limits are rejected without execution
+	Limits Limits
+	types  map[string]*Type
+	//introspection data of the schema, it is built once since the schema is immutable
+	introspection map[string]interface{}
+}
+
+//Builds the schema of the root types, all types reachable from them are collected. Type names must be unique
+func NewSchema(query *Type, mutation *Type) (*Schema, error) {
+	schema := &Schema{Query: query, Mutation: mutation, types: make(map[string]*Type)}
+	for _, scalar := range []*Type{Int, Float, String, Boolean, ID} {
+		schema.types[scalar.Name] = scalar
+	}
+	roots := []*Type{query, introspectionSchemaType}
+	if mutation != nil {
+		roots = append(roots, mutation)
+	}
+	for _, root := range roots {
+		if err := schema.collect(root); err != nil {
+			return nil, err
+		}
+	}
+	schema.introspection = schema.buildIntrospection()
+	return schema, nil
+}
+
+func (s *Schema) collect(t *Type) error {
+	t = t.Named()
+	if !IsName(t.Name) {
+		return fmt.Errorf("Name %q is not valid", t.Name)
+	}
+	if existing, ok := s.types[t.Name]; ok {
+		if existing != t {
+			return fmt.Errorf("Type %q is defined more than once", t.Name)
+		}
+		return nil
+	}
+	s.types[t.Name] = t
+	for _, field := range t.Fields {
+		if !IsName(field.Name) {
+			return fmt.Errorf("Name %q is not valid", field.Name)
+		}
+		if err := s.collect(field.Type); err != nil {
+			return err
+		}
+		for _, arg := range field.Args {
+			if err := s.collect(arg.Type); err != nil {
+				return err
+			}
+		}
+	}
+	for _, possibleType := range t.PossibleTypes {
+		if err := s.collect(possibleType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) Type(name string) *Type {
+	return s.types[name]
+}
+
+//Returns named types sorted by name
+func (s *Schema) Types() []*Type {
+	types := make([]*Type, 0, len(s.types))
+	for _, t := range s.types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+	return types
+}
@@ -0,0 +1,166 @@
+package server_test
+
+import (
+	"bytes"
+	"custodian/server"
+	"custodian/server/auth"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GraphQL", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	var httpServer *http.Server
+	var recorder *httptest.ResponseRecorder
+
+	//transaction managers
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	BeforeEach(func() {
+		aMetaDescription := description.MetaDescription{
+			Name: "a_gq7xw",
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name:     "name",
+					Type:     description.FieldTypeString,
+					Optional: true,
+				},
+				{
+					Name:     "color",
+					Type:     description.FieldTypeEnum,
+					Optional: true,
+					Enum:     description.EnumChoices{"red", "green"},
+				},
+			},
+		}
+		aMetaObj, err := metaStore.NewMeta(&aMetaDescription)
+		Expect(err).To(BeNil())
+		Expect(metaStore.Create(aMetaObj)).To(BeNil())
+
+		bMetaDescription := description.MetaDescription{
+			Name: "b_gq7xw",
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name:     "a",
+					Type:     description.FieldTypeObject,
+					LinkMeta: "a_gq7xw",
+					LinkType: description.LinkTypeInner,
+					Optional: true,
+				},
+			},
+		}
+		bMetaObj, err := metaStore.NewMeta(&bMetaDescription)
+		Expect(err).To(BeNil())
+		Expect(metaStore.Create(bMetaObj)).To(BeNil())
+
+		//the server is set up after the objects are created, so that its cache contains them
+		httpServer = server.New("localhost", "8081", appConfig.UrlPrefix, appConfig.DbConnectionUrl).Setup(appConfig)
+		recorder = httptest.NewRecorder()
+	})
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	execute := func(query string, variables map[string]interface{}) map[string]interface{} {
+		encodedRequest, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+		url := fmt.Sprintf("%s/graphql", appConfig.UrlPrefix)
+		request, _ := http.NewRequest("POST", url, bytes.NewReader(encodedRequest))
+		request.Header.Set("Content-Type", "application/json")
+		httpServer.Handler.ServeHTTP(recorder, request)
+
+		var body map[string]interface{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &body)).To(BeNil())
+		return body
+	}
+
+	It("retrieves the record with the linked record", func() {
+		aRecord, err := dataProcessor.CreateRecord("a_gq7xw", map[string]interface{}{"name": "A", "color": "red"}, auth.User{})
+		Expect(err).To(BeNil())
+		bRecord, err := dataProcessor.CreateRecord("b_gq7xw", map[string]interface{}{"a": aRecord.Pk()}, auth.User{})
+		Expect(err).To(BeNil())
+
+		body := execute(fmt.Sprintf(`{ b_gq7xw(key: %v) { id a { name color } } }`, bRecord.Pk()), nil)
+
+		Expect(body["errors"]).To(BeNil())
+		b := body["data"].(map[string]interface{})["b_gq7xw"].(map[string]interface{})
+		Expect(b["a"]).To(Equal(map[string]interface{}{"name": "A", "color": "red"}))
+	})
+
+	It("lists records matching the filter", func() {
+		for _, name := range []string{"A", "B", "B"} {
+			_, err := dataProcessor.CreateRecord("a_gq7xw", map[string]interface{}{"name": name}, auth.User{})
+			Expect(err).To(BeNil())
+		}
+
+		body := execute(`query($q: String) { a_gq7xw_list(q: $q) { name } }`, map[string]interface{}{"q": "eq(name,B)"})
+
+		Expect(body["errors"]).To(BeNil())
+		Expect(body["data"].(map[string]interface{})["a_gq7xw_list"]).To(HaveLen(2))
+	})
+
+	It("creates, updates and deletes the record", func() {
+		body := execute(`mutation { create_a_gq7xw(data: {name: "A"}) { id name } }`, nil)
+		Expect(body["errors"]).To(BeNil())
+		created := body["data"].(map[string]interface{})["create_a_gq7xw"].(map[string]interface{})
+		Expect(created["name"]).To(Equal("A"))
+
+		recorder = httptest.NewRecorder()
+		body = execute(`mutation($key: ID!) { update_a_gq7xw(key: $key, data: {color: green}) { color } }`, map[string]interface{}{"key": created["id"]})
+		Expect(body["errors"]).To(BeNil())
+		Expect(body["data"].(map[string]interface{})["update_a_gq7xw"]).To(Equal(map[string]interface{}{"color": "green"}))
+
+		recorder = httptest.NewRecorder()
+		body = execute(`mutation($key: ID!) { delete_a_gq7xw(key: $key) { id } }`, map[string]interface{}{"key": created["id"]})
+		Expect(body["errors"]).To(BeNil())
+
+		record, err := dataProcessor.Get("a_gq7xw", fmt.Sprint(created["id"]), nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(record).To(BeNil())
+	})
+
+	It("returns errors of the processor with the path of the field", func() {
+		body := execute(`mutation { update_a_gq7xw(key: "999", data: {name: "A"}) { id } }`, nil)
+
+		errors := body["errors"].([]interface{})
+		Expect(errors).To(HaveLen(1))
+		Expect(errors[0].(map[string]interface{})["path"]).To(Equal([]interface{}{"update_a_gq7xw"}))
+		Expect(errors[0].(map[string]interface{})["extensions"].(map[string]interface{})["code"]).To(Equal("not_found"))
+	})
+})
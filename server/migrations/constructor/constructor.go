@@ -31,6 +31,12 @@ func (mc *MigrationConstructor) Construct(currentMetaDescription *description.Me
 		operationDescriptions = append(operationDescriptions, *operationDescription)
 	}
 
+	//indexes, constraints, checks and rollups are removed before fields they refer to and added after them
+	operationDescriptions = append(operationDescriptions, mc.processIndexesRemoval(currentMetaDescription, newMigrationMetaDescription)...)
+	operationDescriptions = append(operationDescriptions, mc.processUniqueTogetherRemoval(currentMetaDescription, newMigrationMetaDescription)...)
+	operationDescriptions = append(operationDescriptions, mc.processChecksRemoval(currentMetaDescription, newMigrationMetaDescription)...)
+	operationDescriptions = append(operationDescriptions, mc.processRollupsRemoval(currentMetaDescription, newMigrationMetaDescription)...)
+
 	operationDescriptions = append(operationDescriptions, mc.processFieldsAddition(currentMetaDescription, newMigrationMetaDescription)...)
 	operationDescriptions = append(operationDescriptions, mc.processFieldsUpdate(currentMetaDescription, newMigrationMetaDescription)...)
 	operationDescriptions = append(operationDescriptions, mc.processFieldsRemoval(currentMetaDescription, newMigrationMetaDescription)...)
@@ -39,6 +45,15 @@ func (mc *MigrationConstructor) Construct(currentMetaDescription *description.Me
 	operationDescriptions = append(operationDescriptions, mc.processActionsUpdate(currentMetaDescription, newMigrationMetaDescription)...)
 	operationDescriptions = append(operationDescriptions, mc.processActionsRemoval(currentMetaDescription, newMigrationMetaDescription)...)
 
+	//the deletion mark field is added along with soft delete unless it is declared explicitly
+	operationDescriptions = append(operationDescriptions, mc.processSoftDeleteUpdate(currentMetaDescription, newMigrationMetaDescription)...)
+	operationDescriptions = append(operationDescriptions, mc.processHistoryUpdate(currentMetaDescription, newMigrationMetaDescription)...)
+
+	operationDescriptions = append(operationDescriptions, mc.processIndexesAddition(currentMetaDescription, newMigrationMetaDescription)...)
+	operationDescriptions = append(operationDescriptions, mc.processUniqueTogetherAddition(currentMetaDescription, newMigrationMetaDescription)...)
+	operationDescriptions = append(operationDescriptions, mc.processChecksAddition(currentMetaDescription, newMigrationMetaDescription)...)
+	operationDescriptions = append(operationDescriptions, mc.processRollupsAddition(currentMetaDescription, newMigrationMetaDescription)...)
+
 	if len(operationDescriptions) == 0 {
 		return nil, errors.NewValidationError(migrations.MigrationNoChangesWereDetected, "No changes were detected", nil)
 	}
@@ -115,6 +130,10 @@ func (mc *MigrationConstructor) processFieldsRemoval(currentMetaDescription *des
 	operationDescriptions := make([]MigrationOperationDescription, 0)
 	if currentMetaDescription != nil && newMigrationMetaDescription != nil {
 		for i, currentMetaField := range currentMetaDescription.Fields {
+			//the deletion mark field is kept while soft delete is enabled even if it is not declared
+			if currentMetaField.Name == description.SoftDeleteFieldName && newMigrationMetaDescription.SoftDelete {
+				continue
+			}
 			//if field is not presented in the new metaDescription and is not supposed to be renamed
 			if newMigrationMetaDescription.MetaDescription().FindField(currentMetaField.Name) == nil {
 				if newMigrationMetaDescription.FindFieldWithPreviousName(currentMetaField.Name) == nil {
@@ -145,7 +164,11 @@ func (mc *MigrationConstructor) processFieldsUpdate(currentMetaDescription *desc
 				optionalChanged := currentField.Optional != newFieldDescription.Optional
 				nowOnUpdateChanged := currentField.NowOnUpdate != newFieldDescription.NowOnUpdate
 				nowOnCreateChanged := currentField.NowOnCreate != newFieldDescription.NowOnCreate
-				if nameChanged || defChanged || onDeleteChanged || linkMetaListChanged || optionalChanged || nowOnCreateChanged || nowOnUpdateChanged {
+				searchableChanged := currentField.Searchable != newFieldDescription.Searchable
+				validationRulesChanged := !currentField.ValidationRulesEqual(&newFieldDescription.Field)
+				formulaChanged := currentField.Formula != newFieldDescription.Formula || currentField.Stored != newFieldDescription.Stored
+				typeChanged := currentField.Type != newFieldDescription.Type || currentField.Precision != newFieldDescription.Precision || currentField.Scale != newFieldDescription.Scale
+				if typeChanged || nameChanged || defChanged || onDeleteChanged || linkMetaListChanged || optionalChanged || nowOnCreateChanged || nowOnUpdateChanged || searchableChanged || validationRulesChanged || formulaChanged {
 					operationDescriptions = append(operationDescriptions, *NewMigrationOperationDescription(UpdateFieldOperation, &newMigrationMetaDescription.Fields[i], nil, nil))
 				}
 			}
@@ -200,7 +223,10 @@ func (mc *MigrationConstructor) processActionsUpdate(currentMetaDescription *des
 				activeIfNotRootChanged := currentAction.ActiveIfNotRoot != newActionDescription.ActiveIfNotRoot
 				includeValuesChanged := !reflect.DeepEqual(currentAction.IncludeValues, newActionDescription.IncludeValues)
 				nameChanged := currentAction.Name != newActionDescription.Name
-				if nameChanged || methodChanged || protocolChanged || argsChanged || activeIfNotRootChanged || includeValuesChanged {
+				webhookChanged := !reflect.DeepEqual(currentAction.WebhookOptions(), newActionDescription.WebhookOptions())
+				triggersChanged := currentAction.Condition != newActionDescription.Condition || !reflect.DeepEqual(currentAction.ChangedFields, newActionDescription.ChangedFields)
+				hookChanged := currentAction.Hook != newActionDescription.Hook || currentAction.Timeout != newActionDescription.Timeout
+				if nameChanged || methodChanged || protocolChanged || argsChanged || activeIfNotRootChanged || includeValuesChanged || webhookChanged || triggersChanged || hookChanged {
 					operationDescriptions = append(operationDescriptions, *NewMigrationOperationDescription(UpdateActionOperation, nil, nil, &newMigrationMetaDescription.Actions[i]))
 				}
 			}
@@ -209,6 +235,139 @@ func (mc *MigrationConstructor) processActionsUpdate(currentMetaDescription *des
 	return operationDescriptions
 }
 
+//Changed indexes are recreated, so they are both removed and added
+func (mc *MigrationConstructor) processIndexesAddition(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil {
+		for i, newIndex := range newMigrationMetaDescription.Indexes {
+			currentIndex := currentMetaDescription.FindIndex(newIndex.Name)
+			if currentIndex == nil || !reflect.DeepEqual(*currentIndex, newIndex) {
+				operationDescriptions = append(operationDescriptions, *NewIndexMigrationOperationDescription(AddIndexOperation, &newMigrationMetaDescription.Indexes[i]))
+			}
+		}
+	}
+	return operationDescriptions
+}
+
+func (mc *MigrationConstructor) processIndexesRemoval(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil {
+		newMetaDescription := newMigrationMetaDescription.MetaDescription()
+		for i, currentIndex := range currentMetaDescription.Indexes {
+			newIndex := newMetaDescription.FindIndex(currentIndex.Name)
+			if newIndex == nil || !reflect.DeepEqual(currentIndex, *newIndex) {
+				operationDescriptions = append(operationDescriptions, *NewIndexMigrationOperationDescription(RemoveIndexOperation, &currentMetaDescription.Indexes[i]))
+			}
+		}
+	}
+	return operationDescriptions
+}
+
+func (mc *MigrationConstructor) processUniqueTogetherAddition(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil {
+		for _, newFields := range newMigrationMetaDescription.UniqueTogether {
+			if currentMetaDescription.FindUniqueTogether(newFields) < 0 {
+				operationDescriptions = append(operationDescriptions, *NewUniqueTogetherMigrationOperationDescription(AddUniqueTogetherOperation, newFields))
+			}
+		}
+	}
+	return operationDescriptions
+}
+
+func (mc *MigrationConstructor) processUniqueTogetherRemoval(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil {
+		newMetaDescription := newMigrationMetaDescription.MetaDescription()
+		for _, currentFields := range currentMetaDescription.UniqueTogether {
+			if newMetaDescription.FindUniqueTogether(currentFields) < 0 {
+				operationDescriptions = append(operationDescriptions, *NewUniqueTogetherMigrationOperationDescription(RemoveUniqueTogetherOperation, currentFields))
+			}
+		}
+	}
+	return operationDescriptions
+}
+
+//Changed checks are replaced, so they are both removed and added
+func (mc *MigrationConstructor) processChecksAddition(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil {
+		for i, newCheck := range newMigrationMetaDescription.Checks {
+			currentCheck := currentMetaDescription.FindCheck(newCheck.Name)
+			if currentCheck == nil || *currentCheck != newCheck {
+				operationDescriptions = append(operationDescriptions, *NewCheckMigrationOperationDescription(AddCheckOperation, &newMigrationMetaDescription.Checks[i]))
+			}
+		}
+	}
+	return operationDescriptions
+}
+
+func (mc *MigrationConstructor) processChecksRemoval(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil {
+		newMetaDescription := newMigrationMetaDescription.MetaDescription()
+		for i, currentCheck := range currentMetaDescription.Checks {
+			newCheck := newMetaDescription.FindCheck(currentCheck.Name)
+			if newCheck == nil || *newCheck != currentCheck {
+				operationDescriptions = append(operationDescriptions, *NewCheckMigrationOperationDescription(RemoveCheckOperation, &currentMetaDescription.Checks[i]))
+			}
+		}
+	}
+	return operationDescriptions
+}
+
+//Changed rollups are replaced, so they are both removed and added
+func (mc *MigrationConstructor) processRollupsAddition(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil {
+		for i, newRollup := range newMigrationMetaDescription.Rollups {
+			currentRollup := currentMetaDescription.FindRollup(newRollup.Name)
+			if currentRollup == nil || *currentRollup != newRollup {
+				operationDescriptions = append(operationDescriptions, *NewRollupMigrationOperationDescription(AddRollupOperation, &newMigrationMetaDescription.Rollups[i]))
+			}
+		}
+	}
+	return operationDescriptions
+}
+
+func (mc *MigrationConstructor) processRollupsRemoval(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil {
+		newMetaDescription := newMigrationMetaDescription.MetaDescription()
+		for i, currentRollup := range currentMetaDescription.Rollups {
+			newRollup := newMetaDescription.FindRollup(currentRollup.Name)
+			if newRollup == nil || *newRollup != currentRollup {
+				operationDescriptions = append(operationDescriptions, *NewRollupMigrationOperationDescription(RemoveRollupOperation, &currentMetaDescription.Rollups[i]))
+			}
+		}
+	}
+	return operationDescriptions
+}
+
+func (mc *MigrationConstructor) processSoftDeleteUpdate(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil && currentMetaDescription.SoftDelete != newMigrationMetaDescription.SoftDelete {
+		operationType := DisableSoftDeleteOperation
+		if newMigrationMetaDescription.SoftDelete {
+			operationType = EnableSoftDeleteOperation
+		}
+		operationDescriptions = append(operationDescriptions, *NewMigrationOperationDescription(operationType, nil, nil, nil))
+	}
+	return operationDescriptions
+}
+
+func (mc *MigrationConstructor) processHistoryUpdate(currentMetaDescription *description.MetaDescription, newMigrationMetaDescription *MigrationMetaDescription) []MigrationOperationDescription {
+	operationDescriptions := make([]MigrationOperationDescription, 0)
+	if currentMetaDescription != nil && newMigrationMetaDescription != nil && currentMetaDescription.History != newMigrationMetaDescription.History {
+		operationType := DisableHistoryOperation
+		if newMigrationMetaDescription.History {
+			operationType = EnableHistoryOperation
+		}
+		operationDescriptions = append(operationDescriptions, *NewMigrationOperationDescription(operationType, nil, nil, nil))
+	}
+	return operationDescriptions
+}
+
 func NewMigrationConstructor(manager *managers.MigrationManager) *MigrationConstructor {
 	return &MigrationConstructor{migrationManager: manager}
 }
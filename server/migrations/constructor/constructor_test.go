@@ -763,6 +763,214 @@ var _ = Describe("Migration Constructor", func() {
 			Expect(err).To(BeNil())
 		})
 
+		It("generates operations if index is being changed", func() {
+			globalTransaction, err := dbTransactionManager.BeginTransaction()
+			Expect(err).To(BeNil())
+
+			currentMetaDescription := &description.MetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []description.Field{
+					{
+						Name:     "id",
+						Type:     description.FieldTypeString,
+						Optional: false,
+					},
+				},
+				Indexes: []description.Index{{Name: "id_index", Fields: []string{"id"}}},
+				Cas:     false,
+			}
+			newMetaMigrationDescription := &migration_description.MigrationMetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []migration_description.MigrationFieldDescription{
+					{
+						Field: description.Field{
+							Name:     "id",
+							Type:     description.FieldTypeString,
+							Optional: false,
+						},
+						PreviousName: "",
+					},
+				},
+				Indexes: []description.Index{{Name: "id_index", Fields: []string{"id"}, Method: description.IndexMethodHash}},
+				Cas:     false,
+			}
+			migrationDescription, err := migrationConstructor.Construct(currentMetaDescription, newMetaMigrationDescription, globalTransaction)
+			Expect(err).To(BeNil())
+
+			Expect(migrationDescription).NotTo(BeNil())
+			Expect(migrationDescription.Operations).To(HaveLen(2))
+			Expect(migrationDescription.Operations[0].Type).To(Equal(migration_description.RemoveIndexOperation))
+			Expect(migrationDescription.Operations[1].Type).To(Equal(migration_description.AddIndexOperation))
+			Expect(migrationDescription.Operations[1].Index.Method).To(Equal(description.IndexMethodHash))
+
+			err = globalTransaction.Commit()
+			Expect(err).To(BeNil())
+		})
+
+		It("generates operations if unique together fields are being changed", func() {
+			globalTransaction, err := dbTransactionManager.BeginTransaction()
+			Expect(err).To(BeNil())
+
+			currentMetaDescription := &description.MetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []description.Field{
+					{Name: "id", Type: description.FieldTypeString, Optional: false},
+					{Name: "code", Type: description.FieldTypeString, Optional: false},
+					{Name: "region", Type: description.FieldTypeString, Optional: false},
+				},
+				UniqueTogether: [][]string{{"code"}},
+				Cas:            false,
+			}
+			newMetaMigrationDescription := &migration_description.MigrationMetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []migration_description.MigrationFieldDescription{
+					{Field: description.Field{Name: "id", Type: description.FieldTypeString, Optional: false}},
+					{Field: description.Field{Name: "code", Type: description.FieldTypeString, Optional: false}},
+					{Field: description.Field{Name: "region", Type: description.FieldTypeString, Optional: false}},
+				},
+				UniqueTogether: [][]string{{"code", "region"}},
+				Cas:            false,
+			}
+			migrationDescription, err := migrationConstructor.Construct(currentMetaDescription, newMetaMigrationDescription, globalTransaction)
+			Expect(err).To(BeNil())
+
+			Expect(migrationDescription).NotTo(BeNil())
+			Expect(migrationDescription.Operations).To(HaveLen(2))
+			Expect(migrationDescription.Operations[0].Type).To(Equal(migration_description.RemoveUniqueTogetherOperation))
+			Expect(migrationDescription.Operations[0].UniqueTogether).To(Equal([]string{"code"}))
+			Expect(migrationDescription.Operations[1].Type).To(Equal(migration_description.AddUniqueTogetherOperation))
+			Expect(migrationDescription.Operations[1].UniqueTogether).To(Equal([]string{"code", "region"}))
+
+			err = globalTransaction.Commit()
+			Expect(err).To(BeNil())
+		})
+
+		It("generates operations if checks and rollups are being changed", func() {
+			globalTransaction, err := dbTransactionManager.BeginTransaction()
+			Expect(err).To(BeNil())
+
+			currentMetaDescription := &description.MetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []description.Field{
+					{Name: "id", Type: description.FieldTypeNumber, Optional: false},
+					{Name: "amount", Type: description.FieldTypeNumber, Optional: false},
+				},
+				Checks: []description.Check{
+					{Name: "positive_amount", Expression: "amount > 0"},
+					{Name: "limited_amount", Expression: "amount < 100"},
+				},
+				Rollups: []description.Rollup{{Name: "items_count", Link: "items", Func: description.RollupFuncCount}},
+				Cas:     false,
+			}
+			newMetaMigrationDescription := &migration_description.MigrationMetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []migration_description.MigrationFieldDescription{
+					{Field: description.Field{Name: "id", Type: description.FieldTypeNumber, Optional: false}},
+					{Field: description.Field{Name: "amount", Type: description.FieldTypeNumber, Optional: false}},
+				},
+				Checks: []description.Check{
+					{Name: "positive_amount", Expression: "amount > 0"},
+					{Name: "limited_amount", Expression: "amount < 1000"},
+				},
+				Cas: false,
+			}
+			migrationDescription, err := migrationConstructor.Construct(currentMetaDescription, newMetaMigrationDescription, globalTransaction)
+			Expect(err).To(BeNil())
+
+			Expect(migrationDescription).NotTo(BeNil())
+			Expect(migrationDescription.Operations).To(HaveLen(3))
+			Expect(migrationDescription.Operations[0].Type).To(Equal(migration_description.RemoveCheckOperation))
+			Expect(migrationDescription.Operations[0].Check.Expression).To(Equal("amount < 100"))
+			Expect(migrationDescription.Operations[1].Type).To(Equal(migration_description.RemoveRollupOperation))
+			Expect(migrationDescription.Operations[1].Rollup.Name).To(Equal("items_count"))
+			Expect(migrationDescription.Operations[2].Type).To(Equal(migration_description.AddCheckOperation))
+			Expect(migrationDescription.Operations[2].Check.Expression).To(Equal("amount < 1000"))
+
+			err = globalTransaction.Commit()
+			Expect(err).To(BeNil())
+		})
+
+		It("generates operations if soft delete and history are being enabled", func() {
+			globalTransaction, err := dbTransactionManager.BeginTransaction()
+			Expect(err).To(BeNil())
+
+			currentMetaDescription := &description.MetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []description.Field{
+					{Name: "id", Type: description.FieldTypeString, Optional: false},
+				},
+				Cas: false,
+			}
+			newMetaMigrationDescription := &migration_description.MigrationMetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []migration_description.MigrationFieldDescription{
+					{Field: description.Field{Name: "id", Type: description.FieldTypeString, Optional: false}},
+				},
+				SoftDelete: true,
+				History:    true,
+				Cas:        false,
+			}
+			migrationDescription, err := migrationConstructor.Construct(currentMetaDescription, newMetaMigrationDescription, globalTransaction)
+			Expect(err).To(BeNil())
+
+			Expect(migrationDescription).NotTo(BeNil())
+			Expect(migrationDescription.Operations).To(HaveLen(2))
+			Expect(migrationDescription.Operations[0].Type).To(Equal(migration_description.EnableSoftDeleteOperation))
+			Expect(migrationDescription.Operations[1].Type).To(Equal(migration_description.EnableHistoryOperation))
+
+			err = globalTransaction.Commit()
+			Expect(err).To(BeNil())
+		})
+
+		It("keeps the deletion mark field while soft delete is enabled", func() {
+			globalTransaction, err := dbTransactionManager.BeginTransaction()
+			Expect(err).To(BeNil())
+
+			currentMetaDescription := &description.MetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []description.Field{
+					{Name: "id", Type: description.FieldTypeString, Optional: false},
+					{Name: description.SoftDeleteFieldName, Type: description.FieldTypeDateTime, Optional: true},
+				},
+				SoftDelete: true,
+				Cas:        false,
+			}
+			newMetaMigrationDescription := &migration_description.MigrationMetaDescription{
+				Name: testObjAName,
+				Key:  "id",
+				Fields: []migration_description.MigrationFieldDescription{
+					{Field: description.Field{Name: "id", Type: description.FieldTypeString, Optional: false}},
+				},
+				SoftDelete: true,
+				Cas:        false,
+			}
+			_, err = migrationConstructor.Construct(currentMetaDescription, newMetaMigrationDescription, globalTransaction)
+			Expect(err).NotTo(BeNil())
+			Expect(err.(*errors.ServerError).Code).To(Equal(migrations.MigrationNoChangesWereDetected))
+
+			newMetaMigrationDescription.SoftDelete = false
+			migrationDescription, err := migrationConstructor.Construct(currentMetaDescription, newMetaMigrationDescription, globalTransaction)
+			Expect(err).To(BeNil())
+
+			Expect(migrationDescription).NotTo(BeNil())
+			Expect(migrationDescription.Operations).To(HaveLen(2))
+			Expect(migrationDescription.Operations[0].Type).To(Equal(migration_description.RemoveFieldOperation))
+			Expect(migrationDescription.Operations[0].Field.Name).To(Equal(description.SoftDeleteFieldName))
+			Expect(migrationDescription.Operations[1].Type).To(Equal(migration_description.DisableSoftDeleteOperation))
+
+			err = globalTransaction.Commit()
+			Expect(err).To(BeNil())
+		})
+
 		It("generates operation if action is being removed", func() {
 			globalTransaction, err := dbTransactionManager.BeginTransaction()
 			Expect(err).To(BeNil())
@@ -79,12 +79,18 @@ type MigrationActionDescription struct {
 }
 
 type MigrationMetaDescription struct {
-	Name         string                       `json:"name"`
-	PreviousName string                       `json:"previousName"`
-	Key          string                       `json:"key"`
-	Fields       []MigrationFieldDescription  `json:"fields"`
-	Actions      []MigrationActionDescription `json:"actions,omitempty"`
-	Cas          bool                         `json:"cas"`
+	Name           string                       `json:"name"`
+	PreviousName   string                       `json:"previousName"`
+	Key            string                       `json:"key"`
+	Fields         []MigrationFieldDescription  `json:"fields"`
+	Actions        []MigrationActionDescription `json:"actions,omitempty"`
+	Cas            bool                         `json:"cas"`
+	SoftDelete     bool                         `json:"softDelete"`
+	History        bool                         `json:"history"`
+	Indexes        []description.Index          `json:"indexes,omitempty"`
+	UniqueTogether [][]string                   `json:"uniqueTogether,omitempty"`
+	Checks         []description.Check          `json:"checks,omitempty"`
+	Rollups        []description.Rollup         `json:"rollups,omitempty"`
 }
 
 func MigrationMetaDescriptionFromJson(inputReader io.Reader)(*MigrationMetaDescription, error)  {
@@ -106,7 +112,16 @@ func (mmd *MigrationMetaDescription) MetaDescription() *description.MetaDescript
 		actions = append(actions, *mmd.Actions[i].Action.Clone())
 	}
 
-	return description.NewMetaDescription(mmd.Name, mmd.Key, fields, actions, mmd.Cas)
+	metaDescription := description.NewMetaDescription(mmd.Name, mmd.Key, fields, actions, mmd.Cas)
+	metaDescription.SoftDelete = mmd.SoftDelete
+	metaDescription.History = mmd.History
+	metaDescription.UniqueTogether = mmd.UniqueTogether
+	metaDescription.Checks = mmd.Checks
+	metaDescription.Rollups = mmd.Rollups
+	for i := range mmd.Indexes {
+		metaDescription.Indexes = append(metaDescription.Indexes, *mmd.Indexes[i].Clone())
+	}
+	return metaDescription
 }
 
 func (mmd *MigrationMetaDescription) FindFieldWithPreviousName(fieldName string) *MigrationFieldDescription {
@@ -132,12 +147,32 @@ type MigrationOperationDescription struct {
 	Field           *MigrationFieldDescription   `json:"field,omitempty"`
 	MetaDescription *description.MetaDescription `json:"object,omitempty"`
 	Action          *MigrationActionDescription  `json:"action,omitempty"`
+	Index           *description.Index           `json:"index,omitempty"`
+	UniqueTogether  []string                     `json:"uniqueTogether,omitempty"`
+	Check           *description.Check           `json:"check,omitempty"`
+	Rollup          *description.Rollup          `json:"rollup,omitempty"`
 }
 
 func NewMigrationOperationDescription(operationType string, field *MigrationFieldDescription, metaDescription *description.MetaDescription, action *MigrationActionDescription) *MigrationOperationDescription {
 	return &MigrationOperationDescription{Type: operationType, Field: field, MetaDescription: metaDescription, Action: action}
 }
 
+func NewIndexMigrationOperationDescription(operationType string, index *description.Index) *MigrationOperationDescription {
+	return &MigrationOperationDescription{Type: operationType, Index: index}
+}
+
+func NewUniqueTogetherMigrationOperationDescription(operationType string, fields []string) *MigrationOperationDescription {
+	return &MigrationOperationDescription{Type: operationType, UniqueTogether: fields}
+}
+
+func NewCheckMigrationOperationDescription(operationType string, check *description.Check) *MigrationOperationDescription {
+	return &MigrationOperationDescription{Type: operationType, Check: check}
+}
+
+func NewRollupMigrationOperationDescription(operationType string, rollup *description.Rollup) *MigrationOperationDescription {
+	return &MigrationOperationDescription{Type: operationType, Rollup: rollup}
+}
+
 const (
 	AddFieldOperation    = "addField"
 	RemoveFieldOperation = "removeField"
@@ -150,4 +185,22 @@ const (
 	AddActionOperation    = "addAction"
 	UpdateActionOperation = "updateAction"
 	RemoveActionOperation = "removeAction"
+
+	AddIndexOperation    = "addIndex"
+	RemoveIndexOperation = "removeIndex"
+
+	AddUniqueTogetherOperation    = "addUniqueTogether"
+	RemoveUniqueTogetherOperation = "removeUniqueTogether"
+
+	AddCheckOperation    = "addCheck"
+	RemoveCheckOperation = "removeCheck"
+
+	AddRollupOperation    = "addRollup"
+	RemoveRollupOperation = "removeRollup"
+
+	EnableSoftDeleteOperation  = "enableSoftDelete"
+	DisableSoftDeleteOperation = "disableSoftDelete"
+
+	EnableHistoryOperation  = "enableHistory"
+	DisableHistoryOperation = "disableHistory"
 )
@@ -87,6 +87,38 @@ func (rmds *ReversionMigrationDescriptionService) invertOperation(previousStateM
 	case RemoveActionOperation:
 		invertedOperation.Action = operationDescription.Action
 		invertedOperation.Type = AddActionOperation
+	case AddIndexOperation:
+		invertedOperation.Index = operationDescription.Index
+		invertedOperation.Type = RemoveIndexOperation
+	case RemoveIndexOperation:
+		invertedOperation.Index = operationDescription.Index
+		invertedOperation.Type = AddIndexOperation
+	case AddUniqueTogetherOperation:
+		invertedOperation.UniqueTogether = operationDescription.UniqueTogether
+		invertedOperation.Type = RemoveUniqueTogetherOperation
+	case RemoveUniqueTogetherOperation:
+		invertedOperation.UniqueTogether = operationDescription.UniqueTogether
+		invertedOperation.Type = AddUniqueTogetherOperation
+	case AddCheckOperation:
+		invertedOperation.Check = operationDescription.Check
+		invertedOperation.Type = RemoveCheckOperation
+	case RemoveCheckOperation:
+		invertedOperation.Check = operationDescription.Check
+		invertedOperation.Type = AddCheckOperation
+	case AddRollupOperation:
+		invertedOperation.Rollup = operationDescription.Rollup
+		invertedOperation.Type = RemoveRollupOperation
+	case RemoveRollupOperation:
+		invertedOperation.Rollup = operationDescription.Rollup
+		invertedOperation.Type = AddRollupOperation
+	case EnableSoftDeleteOperation:
+		invertedOperation.Type = DisableSoftDeleteOperation
+	case DisableSoftDeleteOperation:
+		invertedOperation.Type = EnableSoftDeleteOperation
+	case EnableHistoryOperation:
+		invertedOperation.Type = DisableHistoryOperation
+	case DisableHistoryOperation:
+		invertedOperation.Type = EnableHistoryOperation
 	}
 	return invertedOperation, nil
 }
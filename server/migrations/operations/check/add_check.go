@@ -0,0 +1,50 @@
+package check
+
+import (
+	"custodian/server/errors"
+	"custodian/server/migrations"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+
+	"fmt"
+)
+
+type AddCheckOperation struct {
+	Check *description.Check
+}
+
+func (o *AddCheckOperation) SyncMetaDescription(metaDescriptionToApply *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	metaDescriptionToApply = metaDescriptionToApply.Clone()
+	if err := o.validate(metaDescriptionToApply); err != nil {
+		return nil, err
+	}
+	metaDescriptionToApply.Checks = append(metaDescriptionToApply.Checks, *o.Check)
+
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(metaDescriptionToApply.Name, *metaDescriptionToApply); err != nil {
+		return nil, err
+	} else {
+		return metaDescriptionToApply, nil
+	}
+}
+
+func (o *AddCheckOperation) validate(metaDescription *description.MetaDescription) error {
+	if metaDescription.FindCheck(o.Check.Name) != nil {
+		return errors.NewValidationError(
+			migrations.MigrationErrorInvalidDescription,
+			fmt.Sprintf("Object %s already has check named '%s'", metaDescription.Name, o.Check.Name),
+			nil,
+		)
+	}
+	return nil
+}
+
+//Checks are evaluated by the processor, so there is nothing to change in the database
+func (o *AddCheckOperation) SyncDbDescription(metaDescriptionToApply *description.MetaDescription, transaction transactions.DbTransaction, syncer object.MetaDescriptionSyncer) (err error) {
+	return nil
+}
+
+func NewAddCheckOperation(check *description.Check) *AddCheckOperation {
+	return &AddCheckOperation{Check: check}
+}
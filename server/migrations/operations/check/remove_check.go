@@ -0,0 +1,56 @@
+package check
+
+import (
+	"custodian/server/errors"
+	"custodian/server/migrations"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+
+	"fmt"
+)
+
+type RemoveCheckOperation struct {
+	Check *description.Check
+}
+
+func (o *RemoveCheckOperation) SyncMetaDescription(metaDescription *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	updatedMetaDescription := metaDescription.Clone()
+	if err := o.validate(updatedMetaDescription); err != nil {
+		return nil, err
+	}
+
+	updatedMetaDescription.Checks = make([]description.Check, 0)
+
+	//remove check from the meta description
+	for i, currentCheck := range metaDescription.Checks {
+		if currentCheck.Name != o.Check.Name {
+			updatedMetaDescription.Checks = append(updatedMetaDescription.Checks, metaDescription.Checks[i])
+		}
+	}
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(updatedMetaDescription.Name, *updatedMetaDescription); err != nil {
+		return nil, err
+	} else {
+		return updatedMetaDescription, nil
+	}
+}
+
+func (o *RemoveCheckOperation) validate(metaDescription *description.MetaDescription) error {
+	if metaDescription.FindCheck(o.Check.Name) == nil {
+		return errors.NewValidationError(
+			migrations.MigrationErrorInvalidDescription,
+			fmt.Sprintf("Object %s has no check named %s", metaDescription.Name, o.Check.Name),
+			nil,
+		)
+	}
+	return nil
+}
+
+func (o *RemoveCheckOperation) SyncDbDescription(metaDescription *description.MetaDescription, transaction transactions.DbTransaction, syncer object.MetaDescriptionSyncer) (err error) {
+	return nil
+}
+
+func NewRemoveCheckOperation(check *description.Check) *RemoveCheckOperation {
+	return &RemoveCheckOperation{Check: check}
+}
@@ -0,0 +1,52 @@
+package index
+
+import (
+	"custodian/server/errors"
+	"custodian/server/migrations"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"fmt"
+)
+
+type AddIndexOperation struct {
+	Index *description.Index
+}
+
+func (o *AddIndexOperation) SyncMetaDescription(metaDescriptionToApply *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	metaDescriptionToApply = metaDescriptionToApply.Clone()
+	if err := o.validate(metaDescriptionToApply); err != nil {
+		return nil, err
+	}
+	metaDescriptionToApply.Indexes = append(metaDescriptionToApply.Indexes, *o.Index.Clone())
+
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(metaDescriptionToApply.Name, *metaDescriptionToApply); err != nil {
+		return nil, err
+	} else {
+		return metaDescriptionToApply, nil
+	}
+}
+
+func (o *AddIndexOperation) validate(metaDescription *description.MetaDescription) error {
+	if metaDescription.FindIndex(o.Index.Name) != nil {
+		return errors.NewValidationError(
+			migrations.MigrationErrorInvalidDescription,
+			fmt.Sprintf("Object %s already has index named '%s'", metaDescription.Name, o.Index.Name),
+			nil,
+		)
+	}
+	for _, fieldName := range o.Index.Fields {
+		if metaDescription.FindField(fieldName) == nil {
+			return errors.NewValidationError(
+				migrations.MigrationErrorInvalidDescription,
+				fmt.Sprintf("Object %s has no field named %s to be indexed by '%s'", metaDescription.Name, fieldName, o.Index.Name),
+				nil,
+			)
+		}
+	}
+	return nil
+}
+
+func NewAddIndexOperation(index *description.Index) *AddIndexOperation {
+	return &AddIndexOperation{Index: index}
+}
@@ -0,0 +1,50 @@
+package index
+
+import (
+	"custodian/server/errors"
+	"custodian/server/migrations"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"fmt"
+)
+
+type RemoveIndexOperation struct {
+	Index *description.Index
+}
+
+func (o *RemoveIndexOperation) SyncMetaDescription(metaDescription *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	updatedMetaDescription := metaDescription.Clone()
+	if err := o.validate(updatedMetaDescription); err != nil {
+		return nil, err
+	}
+
+	updatedMetaDescription.Indexes = make([]description.Index, 0)
+
+	//remove index from the meta description
+	for i, currentIndex := range metaDescription.Indexes {
+		if currentIndex.Name != o.Index.Name {
+			updatedMetaDescription.Indexes = append(updatedMetaDescription.Indexes, metaDescription.Indexes[i])
+		}
+	}
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(updatedMetaDescription.Name, *updatedMetaDescription); err != nil {
+		return nil, err
+	} else {
+		return updatedMetaDescription, nil
+	}
+}
+
+func (o *RemoveIndexOperation) validate(metaDescription *description.MetaDescription) error {
+	if metaDescription.FindIndex(o.Index.Name) == nil {
+		return errors.NewValidationError(
+			migrations.MigrationErrorInvalidDescription,
+			fmt.Sprintf("Object %s has no index named %s", metaDescription.Name, o.Index.Name),
+			nil,
+		)
+	}
+	return nil
+}
+
+func NewRemoveIndexOperation(index *description.Index) *RemoveIndexOperation {
+	return &RemoveIndexOperation{Index: index}
+}
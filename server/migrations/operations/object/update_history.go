@@ -0,0 +1,32 @@
+package object
+
+import (
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+)
+
+type UpdateHistoryOperation struct {
+	History bool
+}
+
+func (o *UpdateHistoryOperation) SyncMetaDescription(metaDescriptionToApply *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	metaDescriptionToApply = metaDescriptionToApply.Clone()
+	metaDescriptionToApply.History = o.History
+
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(metaDescriptionToApply.Name, *metaDescriptionToApply); err != nil {
+		return nil, err
+	} else {
+		return metaDescriptionToApply, nil
+	}
+}
+
+//History of all objects is kept in the same table, so there is nothing to change in the database
+func (o *UpdateHistoryOperation) SyncDbDescription(metaDescriptionToApply *description.MetaDescription, transaction transactions.DbTransaction, syncer object.MetaDescriptionSyncer) (err error) {
+	return nil
+}
+
+func NewUpdateHistoryOperation(history bool) *UpdateHistoryOperation {
+	return &UpdateHistoryOperation{History: history}
+}
@@ -0,0 +1,29 @@
+package object
+
+import (
+	"custodian/server/object"
+	"custodian/server/object/description"
+)
+
+//Enables or disables soft delete of the object, the deletion mark field is added along with enabling
+//unless it is declared explicitly, disabling leaves the field to be removed by its own operation
+type UpdateSoftDeleteOperation struct {
+	SoftDelete bool
+}
+
+func (o *UpdateSoftDeleteOperation) SyncMetaDescription(metaDescriptionToApply *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	metaDescriptionToApply = metaDescriptionToApply.Clone()
+	metaDescriptionToApply.SoftDelete = o.SoftDelete
+	new(description.NormalizationService).NormalizeSoftDeleteField(metaDescriptionToApply)
+
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(metaDescriptionToApply.Name, *metaDescriptionToApply); err != nil {
+		return nil, err
+	} else {
+		return metaDescriptionToApply, nil
+	}
+}
+
+func NewUpdateSoftDeleteOperation(softDelete bool) *UpdateSoftDeleteOperation {
+	return &UpdateSoftDeleteOperation{SoftDelete: softDelete}
+}
@@ -0,0 +1,50 @@
+package rollup
+
+import (
+	"custodian/server/errors"
+	"custodian/server/migrations"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+
+	"fmt"
+)
+
+type AddRollupOperation struct {
+	Rollup *description.Rollup
+}
+
+func (o *AddRollupOperation) SyncMetaDescription(metaDescriptionToApply *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	metaDescriptionToApply = metaDescriptionToApply.Clone()
+	if err := o.validate(metaDescriptionToApply); err != nil {
+		return nil, err
+	}
+	metaDescriptionToApply.Rollups = append(metaDescriptionToApply.Rollups, *o.Rollup)
+
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(metaDescriptionToApply.Name, *metaDescriptionToApply); err != nil {
+		return nil, err
+	} else {
+		return metaDescriptionToApply, nil
+	}
+}
+
+func (o *AddRollupOperation) validate(metaDescription *description.MetaDescription) error {
+	if metaDescription.FindRollup(o.Rollup.Name) != nil {
+		return errors.NewValidationError(
+			migrations.MigrationErrorInvalidDescription,
+			fmt.Sprintf("Object %s already has rollup named '%s'", metaDescription.Name, o.Rollup.Name),
+			nil,
+		)
+	}
+	return nil
+}
+
+//Rollups are calculated on retrieval, so there is nothing to change in the database
+func (o *AddRollupOperation) SyncDbDescription(metaDescriptionToApply *description.MetaDescription, transaction transactions.DbTransaction, syncer object.MetaDescriptionSyncer) (err error) {
+	return nil
+}
+
+func NewAddRollupOperation(rollup *description.Rollup) *AddRollupOperation {
+	return &AddRollupOperation{Rollup: rollup}
+}
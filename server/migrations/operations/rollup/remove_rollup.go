@@ -0,0 +1,56 @@
+package rollup
+
+import (
+	"custodian/server/errors"
+	"custodian/server/migrations"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+
+	"fmt"
+)
+
+type RemoveRollupOperation struct {
+	Rollup *description.Rollup
+}
+
+func (o *RemoveRollupOperation) SyncMetaDescription(metaDescription *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	updatedMetaDescription := metaDescription.Clone()
+	if err := o.validate(updatedMetaDescription); err != nil {
+		return nil, err
+	}
+
+	updatedMetaDescription.Rollups = make([]description.Rollup, 0)
+
+	//remove rollup from the meta description
+	for i, currentRollup := range metaDescription.Rollups {
+		if currentRollup.Name != o.Rollup.Name {
+			updatedMetaDescription.Rollups = append(updatedMetaDescription.Rollups, metaDescription.Rollups[i])
+		}
+	}
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(updatedMetaDescription.Name, *updatedMetaDescription); err != nil {
+		return nil, err
+	} else {
+		return updatedMetaDescription, nil
+	}
+}
+
+func (o *RemoveRollupOperation) validate(metaDescription *description.MetaDescription) error {
+	if metaDescription.FindRollup(o.Rollup.Name) == nil {
+		return errors.NewValidationError(
+			migrations.MigrationErrorInvalidDescription,
+			fmt.Sprintf("Object %s has no rollup named %s", metaDescription.Name, o.Rollup.Name),
+			nil,
+		)
+	}
+	return nil
+}
+
+func (o *RemoveRollupOperation) SyncDbDescription(metaDescription *description.MetaDescription, transaction transactions.DbTransaction, syncer object.MetaDescriptionSyncer) (err error) {
+	return nil
+}
+
+func NewRemoveRollupOperation(rollup *description.Rollup) *RemoveRollupOperation {
+	return &RemoveRollupOperation{Rollup: rollup}
+}
@@ -0,0 +1,52 @@
+package unique
+
+import (
+	"custodian/server/errors"
+	"custodian/server/migrations"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"fmt"
+)
+
+type AddUniqueTogetherOperation struct {
+	Fields []string
+}
+
+func (o *AddUniqueTogetherOperation) SyncMetaDescription(metaDescriptionToApply *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	metaDescriptionToApply = metaDescriptionToApply.Clone()
+	if err := o.validate(metaDescriptionToApply); err != nil {
+		return nil, err
+	}
+	metaDescriptionToApply.UniqueTogether = append(metaDescriptionToApply.UniqueTogether, append([]string{}, o.Fields...))
+
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(metaDescriptionToApply.Name, *metaDescriptionToApply); err != nil {
+		return nil, err
+	} else {
+		return metaDescriptionToApply, nil
+	}
+}
+
+func (o *AddUniqueTogetherOperation) validate(metaDescription *description.MetaDescription) error {
+	if metaDescription.FindUniqueTogether(o.Fields) >= 0 {
+		return errors.NewValidationError(
+			migrations.MigrationErrorInvalidDescription,
+			fmt.Sprintf("Object %s already has fields %v unique together", metaDescription.Name, o.Fields),
+			nil,
+		)
+	}
+	for _, fieldName := range o.Fields {
+		if metaDescription.FindField(fieldName) == nil {
+			return errors.NewValidationError(
+				migrations.MigrationErrorInvalidDescription,
+				fmt.Sprintf("Object %s has no field named %s to be unique together with %v", metaDescription.Name, fieldName, o.Fields),
+				nil,
+			)
+		}
+	}
+	return nil
+}
+
+func NewAddUniqueTogetherOperation(fields []string) *AddUniqueTogetherOperation {
+	return &AddUniqueTogetherOperation{Fields: fields}
+}
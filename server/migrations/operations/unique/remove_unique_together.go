@@ -0,0 +1,45 @@
+package unique
+
+import (
+	"custodian/server/errors"
+	"custodian/server/migrations"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"fmt"
+)
+
+type RemoveUniqueTogetherOperation struct {
+	Fields []string
+}
+
+func (o *RemoveUniqueTogetherOperation) SyncMetaDescription(metaDescription *description.MetaDescription, metaDescriptionSyncer object.MetaDescriptionSyncer) (*description.MetaDescription, error) {
+	updatedMetaDescription := metaDescription.Clone()
+	if err := o.validate(updatedMetaDescription); err != nil {
+		return nil, err
+	}
+
+	i := updatedMetaDescription.FindUniqueTogether(o.Fields)
+	updatedMetaDescription.UniqueTogether = append(updatedMetaDescription.UniqueTogether[:i], updatedMetaDescription.UniqueTogether[i+1:]...)
+
+	//sync its MetaDescription
+	if _, err := metaDescriptionSyncer.Update(updatedMetaDescription.Name, *updatedMetaDescription); err != nil {
+		return nil, err
+	} else {
+		return updatedMetaDescription, nil
+	}
+}
+
+func (o *RemoveUniqueTogetherOperation) validate(metaDescription *description.MetaDescription) error {
+	if metaDescription.FindUniqueTogether(o.Fields) < 0 {
+		return errors.NewValidationError(
+			migrations.MigrationErrorInvalidDescription,
+			fmt.Sprintf("Object %s has no fields %v unique together", metaDescription.Name, o.Fields),
+			nil,
+		)
+	}
+	return nil
+}
+
+func NewRemoveUniqueTogetherOperation(fields []string) *RemoveUniqueTogetherOperation {
+	return &RemoveUniqueTogetherOperation{Fields: fields}
+}
@@ -0,0 +1,77 @@
+package noti
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var amqpPool = newBrokerPool(dialAmqp)
+
+//AMQP notifier args: the broker URL, the exchange and the routing key template, "{{.object}}.{{.action}}" by default
+func NewAmqpNotifier(args []string, activeIfNotRoot bool) (Notifier, error) {
+	notifier, err := newBrokerNotifier(amqpPool, args, "{{.object}}.{{.action}}")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := amqp.ParseURI(notifier.address); err != nil {
+		return nil, NewNotiError(ErrBrokerFailedAddress, "Build an AMQP notifier failed. Specified URL '%s' is bad: %s", notifier.address, err.Error())
+	}
+	return notifier, nil
+}
+
+//Channel in confirm mode, so that a message is published once the broker acknowledges it
+type amqpConnection struct {
+	mutex      sync.Mutex
+	connection *amqp.Connection
+	channel    *amqp.Channel
+	confirms   chan amqp.Confirmation
+}
+
+func dialAmqp(address string) (brokerConnection, error) {
+	connection, err := amqp.DialConfig(address, amqp.Config{Dial: amqp.DefaultDial(time.Second * BROKER_TIMEOUT_IN_SEC)})
+	if err != nil {
+		return nil, err
+	}
+	channel, err := connection.Channel()
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+	if err := channel.Confirm(false); err != nil {
+		connection.Close()
+		return nil, err
+	}
+	confirms := channel.NotifyPublish(make(chan amqp.Confirmation, 1))
+	return &amqpConnection{connection: connection, channel: channel, confirms: confirms}, nil
+}
+
+func (ac *amqpConnection) publish(exchange string, key string, body []byte) error {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+	err := ac.channel.Publish(exchange, key, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case confirmation, ok := <-ac.confirms:
+		if !ok {
+			return fmt.Errorf("channel is closed")
+		} else if !confirmation.Ack {
+			return fmt.Errorf("message is not acknowledged by the broker")
+		}
+		return nil
+	case <-time.After(time.Second * BROKER_TIMEOUT_IN_SEC):
+		return fmt.Errorf("confirmation timed out")
+	}
+}
+
+func (ac *amqpConnection) close() error {
+	return ac.connection.Close()
+}
@@ -0,0 +1,135 @@
+package noti
+
+import (
+	"custodian/logger"
+	"encoding/json"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+var BROKER_TIMEOUT_IN_SEC time.Duration = 10
+
+const (
+	ErrBrokerNoAddressFound     = "broker_no_address_found"
+	ErrBrokerNoDestinationFound = "broker_no_destination_found"
+	ErrBrokerFailedAddress      = "broker_failed_address"
+	ErrBrokerFailedKeyTemplate  = "broker_failed_key_template"
+	ErrBrokerDelivery           = "broker_delivery"
+)
+
+//Connection to the message broker, it is shared by all notifiers of the broker
+type brokerConnection interface {
+	publish(destination string, key string, body []byte) error
+	close() error
+}
+
+type brokerDialer func(address string) (brokerConnection, error)
+
+//Pool of broker connections, the connection is dialed on the first use and is dialed again once it fails
+type brokerPool struct {
+	mutex       sync.Mutex
+	dial        brokerDialer
+	connections map[string]brokerConnection
+}
+
+func newBrokerPool(dial brokerDialer) *brokerPool {
+	return &brokerPool{dial: dial, connections: make(map[string]brokerConnection)}
+}
+
+func (pool *brokerPool) get(address string) (brokerConnection, error) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if connection, ok := pool.connections[address]; ok {
+		return connection, nil
+	}
+	connection, err := pool.dial(address)
+	if err != nil {
+		return nil, err
+	}
+	pool.connections[address] = connection
+	return connection, nil
+}
+
+//Close the failed connection, unless it is already replaced by another one
+func (pool *brokerPool) drop(address string, connection brokerConnection) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if pool.connections[address] == connection {
+		delete(pool.connections, address)
+	}
+	connection.close()
+}
+
+//Publish the message, it is published once again with a new connection if the current one fails
+func (pool *brokerPool) publish(address string, destination string, key string, body []byte) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var connection brokerConnection
+		if connection, err = pool.get(address); err != nil {
+			return err
+		}
+		if err = connection.publish(destination, key, body); err == nil {
+			return nil
+		}
+		logger.Error("Failed to publish notification to '%s': %s", destination, err.Error())
+		pool.drop(address, connection)
+	}
+	return err
+}
+
+//Notifier which publishes events to the destination of the broker, args are the address of the broker,
+//the destination and the optional template of the message key which is executed with the event
+type brokerNotifier struct {
+	pool        *brokerPool
+	address     string
+	destination string
+	key         *template.Template
+}
+
+func newBrokerNotifier(pool *brokerPool, args []string, defaultKey string) (*brokerNotifier, error) {
+	if len(args) < 1 || args[0] == "" {
+		return nil, NewNotiError(ErrBrokerNoAddressFound, "Build a broker notifier failed. No broker address found in arguments")
+	}
+	if len(args) < 2 || args[1] == "" {
+		return nil, NewNotiError(ErrBrokerNoDestinationFound, "Build a broker notifier failed. No destination found in arguments")
+	}
+	keyTemplate := defaultKey
+	if len(args) > 2 {
+		keyTemplate = args[2]
+	}
+	key, err := template.New("key").Option("missingkey=zero").Parse(keyTemplate)
+	if err != nil {
+		return nil, NewNotiError(ErrBrokerFailedKeyTemplate, "Build a broker notifier failed. Key template '%s' is bad: %s", keyTemplate, err.Error())
+	}
+	return &brokerNotifier{pool: pool, address: args[0], destination: args[1], key: key}, nil
+}
+
+func (bn *brokerNotifier) Deliver(event *Event) error {
+	body, _ := json.Marshal(event.Obj())
+	var key strings.Builder
+	if err := bn.key.Execute(&key, event.Obj()); err != nil {
+		return NewNotiError(ErrBrokerFailedKeyTemplate, "Failed to build the key of notification: %s", err.Error())
+	}
+	if err := bn.pool.publish(bn.address, bn.destination, key.String(), body); err != nil {
+		return NewNotiError(ErrBrokerDelivery, "Failed to publish notification to '%s': %s", bn.destination, err.Error())
+	}
+	return nil
+}
+
+func (bn *brokerNotifier) NewNotification() chan *Event {
+	in := make(chan *Event, 100)
+	go func() {
+		for event := range in {
+			//error events have nothing to publish
+			if event.err != nil {
+				continue
+			}
+			if err := bn.Deliver(event); err != nil {
+				logger.Error("Error sending notification: %s", err.Error())
+			}
+		}
+	}()
+	return in
+}
@@ -0,0 +1,29 @@
+package noti
+
+import "time"
+
+var HOOK_TIMEOUT_IN_SEC time.Duration = 10
+
+const (
+	ErrHookCall = "hook_call"
+)
+
+//Notifier which is called synchronously before the change is committed, its response decides on the change
+type Hook interface {
+	Call(event *Event, timeout time.Duration) (*HookResponse, error)
+}
+
+//Response of the hook, data is the decoded JSON object of the response body if it is given
+type HookResponse struct {
+	Status int
+	Data   map[string]interface{}
+}
+
+func (response *HookResponse) Succeeded() bool {
+	return response.Status >= 200 && response.Status < 300
+}
+
+//Hook declines the change with the client error status
+func (response *HookResponse) Rejected() bool {
+	return response.Status >= 400 && response.Status < 500
+}
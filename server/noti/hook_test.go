@@ -0,0 +1,76 @@
+package noti
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hooks", func() {
+	var status int
+	var response string
+	var delay time.Duration
+	var server *httptest.Server
+	event := NewObjectEvent(map[string]interface{}{"action": "create", "object": "a", "current": map[string]interface{}{"name": "first"}}, true)
+
+	BeforeEach(func() {
+		status, response, delay = http.StatusOK, "", 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			w.WriteHeader(status)
+			w.Write([]byte(response))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	havingHook := func() Hook {
+		notifier, err := NewRestNotifier([]string{server.URL}, true)
+		Expect(err).To(BeNil())
+		return notifier.(Hook)
+	}
+
+	It("returns the data of the response", func() {
+		response = `{"name": "second"}`
+
+		result, err := havingHook().Call(event, time.Second)
+		Expect(err).To(BeNil())
+		Expect(result.Succeeded()).To(BeTrue())
+		Expect(result.Data).To(Equal(map[string]interface{}{"name": "second"}))
+	})
+
+	It("accepts the empty response", func() {
+		result, err := havingHook().Call(event, time.Second)
+		Expect(err).To(BeNil())
+		Expect(result.Succeeded()).To(BeTrue())
+		Expect(result.Data).To(BeNil())
+	})
+
+	It("returns the rejection", func() {
+		status, response = http.StatusUnprocessableEntity, `{"msg": "Name is taken"}`
+
+		result, err := havingHook().Call(event, time.Second)
+		Expect(err).To(BeNil())
+		Expect(result.Rejected()).To(BeTrue())
+		Expect(result.Data["msg"]).To(Equal("Name is taken"))
+	})
+
+	It("fails on the malformed response", func() {
+		response = `[1, 2]`
+
+		_, err := havingHook().Call(event, time.Second)
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("fails on timeout", func() {
+		delay = 200 * time.Millisecond
+
+		_, err := havingHook().Call(event, 50*time.Millisecond)
+		Expect(err).NotTo(BeNil())
+	})
+})
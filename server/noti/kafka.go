@@ -0,0 +1,41 @@
+package noti
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var kafkaPool = newBrokerPool(dialKafka)
+
+//Kafka notifier args: comma-separated broker addresses, the topic and the message key template, "{{.object}}" by default
+func NewKafkaNotifier(args []string, activeIfNotRoot bool) (Notifier, error) {
+	return newBrokerNotifier(kafkaPool, args, "{{.object}}")
+}
+
+//Writer keeps connections to the brokers and reconnects on its own. Messages of the same key go to the same partition
+type kafkaConnection struct {
+	writer *kafka.Writer
+}
+
+func dialKafka(address string) (brokerConnection, error) {
+	return &kafkaConnection{writer: &kafka.Writer{
+		Addr:         kafka.TCP(strings.Split(address, ",")...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
+		WriteTimeout: time.Second * BROKER_TIMEOUT_IN_SEC,
+	}}, nil
+}
+
+func (kc *kafkaConnection) publish(topic string, key string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*BROKER_TIMEOUT_IN_SEC)
+	defer cancel()
+	return kc.writer.WriteMessages(ctx, kafka.Message{Topic: topic, Key: []byte(key), Value: body})
+}
+
+func (kc *kafkaConnection) close() error {
+	return kc.writer.Close()
+}
@@ -0,0 +1,20 @@
+package noti
+
+import (
+	"github.com/onsi/ginkgo/reporters"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestNoti(t *testing.T) {
+	RegisterFailHandler(Fail)
+	if ci := os.Getenv("CI"); ci != "" {
+		teamcityReporter := reporters.NewTeamCityReporter(os.Stdout)
+		RunSpecsWithCustomReporters(t, "Notifications Suite", []Reporter{teamcityReporter})
+	} else {
+		RunSpecs(t, "Notifications Suite")
+	}
+}
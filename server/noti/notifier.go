@@ -31,8 +31,10 @@ func asProtocol(name string) (Protocol, bool) {
 }
 
 var (
-	REST = protocol_iota("REST")
-	TEST = protocol_iota("TEST")
+	REST  = protocol_iota("REST")
+	TEST  = protocol_iota("TEST")
+	AMQP  = protocol_iota("AMQP")
+	KAFKA = protocol_iota("KAFKA")
 )
 
 func (p *Protocol) MarshalJSON() ([]byte, error) {
@@ -56,8 +58,10 @@ func (p *Protocol) UnmarshalJSON(b []byte) error {
 }
 
 var NotifierFactories = map[Protocol]Factory{
-	REST: NewRestNotifier,
-	TEST: NewTestNotifier,
+	REST:  NewRestNotifier,
+	TEST:  NewTestNotifier,
+	AMQP:  NewAmqpNotifier,
+	KAFKA: NewKafkaNotifier,
 }
 
 
@@ -104,4 +108,20 @@ type Notifier interface {
 	NewNotification() chan *Event
 }
 
-type Factory func(args []string, activeIfNotRoot bool) (Notifier, error)
\ No newline at end of file
+type Factory func(args []string, activeIfNotRoot bool) (Notifier, error)
+
+//Notifier which delivers events synchronously, so that a failed delivery can be retried later
+type Deliverer interface {
+	Deliver(event *Event) error
+}
+
+//Delivers the event with the notifier, notifiers which cannot deliver synchronously get the event queued
+func Deliver(notifier Notifier, event *Event) error {
+	if deliverer, ok := notifier.(Deliverer); ok {
+		return deliverer.Deliver(event)
+	}
+	notification := notifier.NewNotification()
+	notification <- event
+	close(notification)
+	return nil
+}
@@ -5,9 +5,10 @@ import (
 	"custodian/logger"
 	"custodian/server/auth"
 	"encoding/json"
-	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"text/template"
 	"time"
 )
 
@@ -15,8 +16,10 @@ var REST_REDELIVERY_PAUSE_IN_SEC time.Duration = 10
 var REST_MAX_REDILIVERY_ATTEMPTS byte = 3
 
 const (
-	ErrRESTNoURLFound = "rest_no_url_found"
-	ErrRESTFailedURL  = "rest_failed_url"
+	ErrRESTNoURLFound     = "rest_no_url_found"
+	ErrRESTFailedURL      = "rest_failed_url"
+	ErrRESTDelivery       = "rest_delivery"
+	ErrRESTFailedTemplate = "rest_failed_template"
 )
 
 var restClient = &http.Client{
@@ -26,6 +29,10 @@ var restClient = &http.Client{
 type restNotifier struct {
 	url             string
 	activeIfNotRoot bool
+	headers         map[string]string
+	payload         *template.Template
+	sign            bool
+	secret          string
 }
 
 func NewRestNotifier(args []string, activeIfNotRoot bool) (Notifier, error) {
@@ -39,6 +46,32 @@ func NewRestNotifier(args []string, activeIfNotRoot bool) (Notifier, error) {
 	return &restNotifier{url: args[0], activeIfNotRoot: activeIfNotRoot}, nil
 }
 
+func (rn *restNotifier) Configure(options WebhookOptions) error {
+	if options.Template != "" {
+		payload, err := ParsePayloadTemplate(options.Template)
+		if err != nil {
+			return NewNotiError(ErrRESTFailedTemplate, "Build a rest notifier failed. Payload template is bad: %s", err.Error())
+		}
+		rn.payload = payload
+	}
+	rn.headers = options.Headers
+	rn.sign = options.Sign
+	rn.secret = options.Secret
+	return nil
+}
+
+//Build the payload of the event, it is the event itself unless the template is given
+func (rn *restNotifier) buildPayload(event *Event) ([]byte, error) {
+	if rn.payload == nil {
+		return json.Marshal(event.Obj())
+	}
+	var body bytes.Buffer
+	if err := rn.payload.Execute(&body, event.Obj()); err != nil {
+		return nil, NewNotiError(ErrRESTFailedTemplate, "Failed to build the payload of notification: %s", err.Error())
+	}
+	return body.Bytes(), nil
+}
+
 func (rn *restNotifier) redelivery(body []byte, attempt byte) {
 	timer := time.NewTimer(time.Second * REST_REDELIVERY_PAUSE_IN_SEC)
 	logger.Info("Scheduled '%d' attempt of re-delivery notification for '%s' URL in '%d' seconds", attempt, rn.url, REST_REDELIVERY_PAUSE_IN_SEC)
@@ -52,7 +85,7 @@ func (rn *restNotifier) redelivery(body []byte, attempt byte) {
 			logger.Error("Can't schedule re-delivery for '%s' URL. Achived max re-delivery attempts '%d'", rn.url, REST_MAX_REDILIVERY_ATTEMPTS)
 		}
 	}
-	resp, err := postCallbackData(rn.url, bytes.NewReader(body))
+	resp, err := rn.postCallbackData(body)
 	if err != nil {
 		logger.Error("Error sending notification: %s", err.Error())
 		tryAgain()
@@ -67,8 +100,12 @@ func (rn *restNotifier) redelivery(body []byte, attempt byte) {
 
 func (rn *restNotifier) start(in chan *Event) {
 	for event := range in {
-		body, _ := json.Marshal(event.Obj())
-		resp, err := postCallbackData(rn.url, bytes.NewReader(body))
+		body, err := rn.buildPayload(event)
+		if err != nil {
+			logger.Error("Error sending notification: %s", err.Error())
+			continue
+		}
+		resp, err := rn.postCallbackData(body)
 
 		failed := false
 		if err != nil {
@@ -89,6 +126,48 @@ func (rn *restNotifier) start(in chan *Event) {
 	}
 }
 
+//Posts the event to the URL, the event is not redelivered on failure
+func (rn *restNotifier) Deliver(event *Event) error {
+	body, err := rn.buildPayload(event)
+	if err != nil {
+		return err
+	}
+	resp, err := rn.postCallbackData(body)
+	if err != nil {
+		return NewNotiError(ErrRESTDelivery, "Error sending notification: %s", err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return NewNotiError(ErrRESTDelivery, "Received an invalid response code '%d' from the '%s' notified server", resp.StatusCode, rn.url)
+	}
+	return nil
+}
+
+//Posts the event and waits for the response within the timeout, the non-empty response body must be a JSON object
+func (rn *restNotifier) Call(event *Event, timeout time.Duration) (*HookResponse, error) {
+	body, err := rn.buildPayload(event)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(rn.newCallbackRequest(body))
+	if err != nil {
+		return nil, NewNotiError(ErrHookCall, "Error calling hook: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	responseBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewNotiError(ErrHookCall, "Error reading response of the '%s' hook: %s", rn.url, err.Error())
+	}
+	response := &HookResponse{Status: resp.StatusCode}
+	if len(bytes.TrimSpace(responseBody)) > 0 {
+		if err := json.Unmarshal(responseBody, &response.Data); err != nil && response.Succeeded() {
+			return nil, NewNotiError(ErrHookCall, "Received a malformed response from the '%s' hook: %s", rn.url, err.Error())
+		}
+	}
+	return response, nil
+}
+
 func (rn *restNotifier) NewNotification() chan *Event {
 	// Use buffer to reduce the effect of network latency on process
 	in := make(chan *Event, 100)
@@ -96,15 +175,26 @@ func (rn *restNotifier) NewNotification() chan *Event {
 	return in
 }
 
-func postCallbackData(url string, body io.Reader) (*http.Response, error){
-	callbackRequest, _ := http.NewRequest("POST", url, body)
+func (rn *restNotifier) postCallbackData(body []byte) (*http.Response, error) {
+	return restClient.Do(rn.newCallbackRequest(body))
+}
+
+func (rn *restNotifier) newCallbackRequest(body []byte) *http.Request {
+	callbackRequest, _ := http.NewRequest("POST", rn.url, bytes.NewReader(body))
 	callbackRequest.Header.Add("Content-Type", "application/json")
 
 	serviceToken, err := auth.GetServiceToken()
 
 	if err == nil {
-		callbackRequest.Header.Add("Authorization", "Service " +serviceToken)
+		callbackRequest.Header.Add("Authorization", "Service "+serviceToken)
 	}
 
-	return restClient.Do(callbackRequest)
+	//custom headers override the default ones
+	for name, value := range rn.headers {
+		callbackRequest.Header.Set(name, value)
+	}
+	if rn.sign {
+		callbackRequest.Header.Set(SignatureHeader, SignPayload(body, rn.secret))
+	}
+	return callbackRequest
 }
\ No newline at end of file
@@ -13,4 +13,9 @@ func NewTestNotifier(args []string, activeIfNotRoot bool) (Notifier, error) {
 
 func (rn *TestNotifier) NewNotification() chan *Event {
 	return rn.Events
-}
\ No newline at end of file
+}
+
+func (rn *TestNotifier) Deliver(event *Event) error {
+	rn.Events <- event
+	return nil
+}
@@ -0,0 +1,48 @@
+package noti
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"custodian/server/auth"
+	"encoding/hex"
+	"encoding/json"
+	"text/template"
+)
+
+//Header of the payload signature
+const SignatureHeader = "X-Custodian-Signature"
+
+//Settings of webhooks. The payload template is executed with the notification instead of posting it as is,
+//the payload is signed with the secret or with the service secret if no secret is given
+type WebhookOptions struct {
+	Headers  map[string]string
+	Template string
+	Sign     bool
+	Secret   string
+}
+
+//Notifier which posts events over HTTP
+type WebhookNotifier interface {
+	Notifier
+	Configure(options WebhookOptions) error
+}
+
+//Parse the payload template, "json" function encodes the value as JSON
+func ParsePayloadTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Option("missingkey=zero").Funcs(template.FuncMap{"json": encodeJson}).Parse(text)
+}
+
+func encodeJson(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+//Signature is HMAC-SHA256 of the payload if the secret is given, otherwise the payload is signed by the service signer
+func SignPayload(payload []byte, secret string) string {
+	if secret != "" {
+		signature := hmac.New(sha256.New, []byte(secret))
+		signature.Write(payload)
+		return "sha256=" + hex.EncodeToString(signature.Sum(nil))
+	}
+	return "service=" + auth.Sign(string(payload))
+}
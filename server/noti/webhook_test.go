@@ -0,0 +1,82 @@
+package noti
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhooks", func() {
+	var request *http.Request
+	var body []byte
+	var server *httptest.Server
+	event := NewObjectEvent(map[string]interface{}{"action": "create", "object": "a", "current": map[string]interface{}{"id": 1, "name": "first"}}, true)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			body, _ = ioutil.ReadAll(r.Body)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	havingWebhook := func(options WebhookOptions) Notifier {
+		notifier, err := NewRestNotifier([]string{server.URL}, true)
+		Expect(err).To(BeNil())
+		Expect(notifier.(WebhookNotifier).Configure(options)).To(BeNil())
+		return notifier
+	}
+
+	It("posts the payload built by the template", func() {
+		notifier := havingWebhook(WebhookOptions{Template: `{"event": "{{.object}}_{{.action}}", "name": {{json .current.name}}}`})
+
+		Expect(Deliver(notifier, event)).To(BeNil())
+		Expect(string(body)).To(Equal(`{"event": "a_create", "name": "first"}`))
+	})
+
+	It("adds custom headers", func() {
+		notifier := havingWebhook(WebhookOptions{Headers: map[string]string{"X-Source": "custodian", "Content-Type": "application/vnd.event+json"}})
+
+		Expect(Deliver(notifier, event)).To(BeNil())
+		Expect(request.Header.Get("X-Source")).To(Equal("custodian"))
+		Expect(request.Header.Get("Content-Type")).To(Equal("application/vnd.event+json"))
+	})
+
+	It("signs the payload with the secret", func() {
+		notifier := havingWebhook(WebhookOptions{Sign: true, Secret: "secret"})
+
+		Expect(Deliver(notifier, event)).To(BeNil())
+		signature := hmac.New(sha256.New, []byte("secret"))
+		signature.Write(body)
+		Expect(request.Header.Get(SignatureHeader)).To(Equal("sha256=" + hex.EncodeToString(signature.Sum(nil))))
+	})
+
+	It("signs the payload by the service signer if no secret is given", func() {
+		notifier := havingWebhook(WebhookOptions{Sign: true})
+
+		Expect(Deliver(notifier, event)).To(BeNil())
+		Expect(request.Header.Get(SignatureHeader)).To(Equal(SignPayload(body, "")))
+		Expect(request.Header.Get(SignatureHeader)).To(HavePrefix("service="))
+	})
+
+	It("does not sign the payload by default", func() {
+		notifier := havingWebhook(WebhookOptions{})
+
+		Expect(Deliver(notifier, event)).To(BeNil())
+		Expect(request.Header.Get(SignatureHeader)).To(BeEmpty())
+	})
+
+	It("rejects the wrong template", func() {
+		notifier, _ := NewRestNotifier([]string{server.URL}, true)
+		Expect(notifier.(WebhookNotifier).Configure(WebhookOptions{Template: "{{.object"})).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,167 @@
+package object
+
+import (
+	"custodian/server/auth"
+	errors2 "custodian/server/errors"
+	"custodian/server/object/errors"
+	"fmt"
+)
+
+//Methods of batch operations
+const (
+	BatchMethodCreate = "create"
+	BatchMethodUpdate = "update"
+	BatchMethodDelete = "delete"
+)
+
+//Key of the object which references the record created earlier in the batch, eg: {"$ref": "order"}
+const batchRefKey = "$ref"
+
+//Operation of the batch. Key is required to update or delete the record, the created or updated record can be named with Ref
+//to use its key in the following operations of the batch
+type BatchOperation struct {
+	Method string                 `json:"method"`
+	Object string                 `json:"object"`
+	Key    interface{}            `json:"key,omitempty"`
+	Ref    string                 `json:"ref,omitempty"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+}
+
+//Parses operations of the batch from the list of JSON objects
+func ParseBatchOperations(list []map[string]interface{}) ([]*BatchOperation, error) {
+	operations := make([]*BatchOperation, len(list))
+	for i, item := range list {
+		operation := &BatchOperation{Key: item["key"]}
+		method, _ := item["method"].(string)
+		objectName, _ := item["object"].(string)
+		ref, refOk := item["ref"].(string)
+		data, dataOk := item["data"].(map[string]interface{})
+		switch {
+		case method != BatchMethodCreate && method != BatchMethodUpdate && method != BatchMethodDelete:
+			return nil, newBatchOperationError(i, "method must be one of: create, update, delete")
+		case objectName == "":
+			return nil, newBatchOperationError(i, "object is required")
+		case method != BatchMethodCreate && operation.Key == nil:
+			return nil, newBatchOperationError(i, "key is required")
+		case method != BatchMethodDelete && !dataOk:
+			return nil, newBatchOperationError(i, "data must be an object")
+		case item["ref"] != nil && !refOk:
+			return nil, newBatchOperationError(i, "ref must be a string")
+		}
+		operation.Method, operation.Object, operation.Ref, operation.Data = method, objectName, ref, data
+		operations[i] = operation
+	}
+	return operations, nil
+}
+
+func newBatchOperationError(index int, msg string) error {
+	return errors2.NewValidationError(errors.ErrWrongBatchOperation, fmt.Sprintf("Operation %d: %s", index, msg), nil)
+}
+
+//Executes operations one by one within one transaction, nothing is changed if any of them fails. The authorize callback is called
+//before each operation with the record being updated or deleted, or nil for the created one. Notifications are sent after the commit.
+//Returns data of the created, updated and deleted records in the order of operations
+func (processor *Processor) ExecuteBatch(operations []*BatchOperation, authorize func(*BatchOperation, *Record) error, user auth.User) ([]map[string]interface{}, error) {
+	results := make([]map[string]interface{}, len(operations))
+	refs := make(map[string]interface{})
+	err := processor.atomically(func() error {
+		for i, operation := range operations {
+			record, err := processor.executeBatchOperation(operation, refs, authorize, user)
+			if err != nil {
+				return wrapBatchOperationError(i, err)
+			}
+			if operation.Ref != "" {
+				refs[operation.Ref] = record.Pk()
+			}
+			results[i] = record.GetData()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (processor *Processor) executeBatchOperation(operation *BatchOperation, refs map[string]interface{}, authorize func(*BatchOperation, *Record) error, user auth.User) (*Record, error) {
+	objectMeta, err := processor.GetMeta(operation.Object)
+	if err != nil {
+		return nil, err
+	}
+	data, err := resolveBatchRefs(operation.Data, refs)
+	if err != nil {
+		return nil, err
+	}
+	if operation.Method == BatchMethodCreate {
+		if err := authorize(operation, nil); err != nil {
+			return nil, err
+		}
+		return processor.CreateRecord(operation.Object, data.(map[string]interface{}), user)
+	}
+
+	keyValue, err := resolveBatchRefs(operation.Key, refs)
+	if err != nil {
+		return nil, err
+	}
+	key, err := objectMeta.Key.ValueAsString(keyValue)
+	if err != nil {
+		return nil, err
+	}
+	record, err := processor.Get(operation.Object, key, nil, nil, 1, true)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, errors2.NewNotFoundError(errors2.ErrNotFound, "Record not found", nil)
+	}
+	if err := authorize(operation, record); err != nil {
+		return nil, err
+	}
+
+	if operation.Method == BatchMethodDelete {
+		return processor.RemoveRecord(operation.Object, key, user)
+	}
+	return processor.UpdateRecord(operation.Object, key, data.(map[string]interface{}), user)
+}
+
+//Replaces references to the records created earlier in the batch with their keys
+func resolveBatchRefs(value interface{}, refs map[string]interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := value[batchRefKey]; ok && len(value) == 1 {
+			refName, _ := ref.(string)
+			if key, ok := refs[refName]; ok {
+				return key, nil
+			}
+			return nil, errors2.NewValidationError(errors.ErrWrongBatchOperation, fmt.Sprintf("Reference '%v' is unknown", ref), nil)
+		}
+		resolved := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			var err error
+			if resolved[k], err = resolveBatchRefs(v, refs); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(value))
+		for i, v := range value {
+			var err error
+			if resolved[i], err = resolveBatchRefs(v, refs); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	}
+	return value, nil
+}
+
+//Prefixes the message of the error with the index of the failed operation
+func wrapBatchOperationError(index int, err error) error {
+	if serverError, ok := err.(*errors2.ServerError); ok {
+		return &errors2.ServerError{
+			Status: serverError.Status, Code: serverError.Code, Msg: fmt.Sprintf("Operation %d: %s", index, serverError.Msg), Data: serverError.Data,
+		}
+	}
+	return errors2.NewFatalError(ErrDMLFailed, fmt.Sprintf("Operation %d: %s", index, err.Error()), nil)
+}
@@ -0,0 +1,91 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Batch", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	customerName := utils.RandomString(8)
+	orderName := utils.RandomString(8)
+	allowAll := func(*object.BatchOperation, *object.Record) error { return nil }
+
+	BeforeEach(func() {
+		customerDescription := description.MetaDescription{
+			Name: customerName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+				{Name: "name", Type: description.FieldTypeString},
+			},
+		}
+		customerMeta, err := metaStore.NewMeta(&customerDescription)
+		Expect(err).To(BeNil())
+		Expect(metaStore.Create(customerMeta)).To(BeNil())
+
+		orderDescription := description.MetaDescription{
+			Name: orderName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+				{Name: "qty", Type: description.FieldTypeNumber},
+				{Name: "customer", Type: description.FieldTypeObject, LinkMeta: customerName, LinkType: description.LinkTypeInner},
+			},
+		}
+		orderMeta, err := metaStore.NewMeta(&orderDescription)
+		Expect(err).To(BeNil())
+		Expect(metaStore.Create(orderMeta)).To(BeNil())
+	})
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	It("executes operations referencing records created earlier in the batch", func() {
+		results, err := dataProcessor.ExecuteBatch([]*object.BatchOperation{
+			{Method: object.BatchMethodCreate, Object: customerName, Ref: "customer", Data: map[string]interface{}{"name": "Smith"}},
+			{Method: object.BatchMethodCreate, Object: orderName, Ref: "order", Data: map[string]interface{}{
+				"qty": 1, "customer": map[string]interface{}{"$ref": "customer"},
+			}},
+			{Method: object.BatchMethodUpdate, Object: orderName, Key: map[string]interface{}{"$ref": "order"}, Data: map[string]interface{}{"qty": 2}},
+		}, allowAll, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(results).To(HaveLen(3))
+		Expect(results[1]["customer"]).To(Equal(results[0]["id"]))
+		Expect(results[2]["qty"]).To(Equal(2.0))
+	})
+
+	It("rolls back all operations if any of them fails", func() {
+		_, err := dataProcessor.ExecuteBatch([]*object.BatchOperation{
+			{Method: object.BatchMethodCreate, Object: customerName, Data: map[string]interface{}{"name": "Smith"}},
+			{Method: object.BatchMethodDelete, Object: orderName, Key: 100.0},
+		}, allowAll, auth.User{})
+		Expect(err).NotTo(BeNil())
+
+		count, _, err := dataProcessor.GetBulk(customerName, "", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(0))
+	})
+
+	It("does not accept operations with unknown method", func() {
+		_, err := object.ParseBatchOperations([]map[string]interface{}{{"method": "merge", "object": customerName}})
+		Expect(err).NotTo(BeNil())
+	})
+})
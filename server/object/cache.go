@@ -10,6 +10,8 @@ import (
 type MetaCache struct {
 	mutex    sync.RWMutex
 	metaList map[string]*Meta
+	//incremented on each change of the cache, so that data derived from it can be rebuilt
+	version uint64
 }
 
 func (mc *MetaCache) Get(metaName string) *Meta {
@@ -35,15 +37,24 @@ func (mc *MetaCache) GetList() []*Meta {
 	return nil
 }
 
+//Returns the version of the cache contents
+func (mc *MetaCache) Version() uint64 {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+	return mc.version
+}
+
 func (mc *MetaCache) Set(meta *Meta) {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
+	mc.version++
 	mc.metaList[meta.Name] = meta
 }
 
 func (mc *MetaCache) Invalidate() {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
+	mc.version++
 	mc.metaList = make(map[string]*Meta, 0)
 }
 
@@ -54,12 +65,14 @@ func NewCache() *MetaCache {
 func (mc *MetaCache) Delete(metaName string) {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
+	mc.version++
 	delete(mc.metaList, metaName)
 }
 
 func (mc *MetaCache) Flush() {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
+	mc.version++
 	for metaName := range mc.metaList {
 		delete(mc.metaList, metaName)
 	}
@@ -91,7 +104,7 @@ func (mc *MetaCache) FactoryMeta(m *description.MetaDescription) (*Meta, error)
 
 func (mc *MetaCache) resolveMeta(currentMeta *Meta) error {
 	//factory fields
-	currentMeta.Fields = make([]FieldDescription, 0, len(currentMeta.MetaDescription.Fields))
+	currentMeta.Fields = make([]FieldDescription, 0, len(currentMeta.MetaDescription.Fields)+len(currentMeta.MetaDescription.Rollups))
 	for _, field := range currentMeta.MetaDescription.Fields {
 		fieldDescription, err := mc.factoryFieldDescription(field, currentMeta)
 		if err != nil {
@@ -100,6 +113,9 @@ func (mc *MetaCache) resolveMeta(currentMeta *Meta) error {
 		currentMeta.Fields = append(currentMeta.Fields, *fieldDescription)
 
 	}
+	if err := factoryRollupFields(currentMeta); err != nil {
+		return err
+	}
 
 	mc.setLinks(currentMeta)
 
@@ -108,7 +124,12 @@ func (mc *MetaCache) resolveMeta(currentMeta *Meta) error {
 		//Setting id and Notifier for action
 		// TODO action.id exists only in cache shold be unique id for each action
 		if err := description.InitAction(i, &currentMeta.MetaDescription.Actions[i]); err == nil {
-			currentMeta.Actions = append(currentMeta.Actions, &currentMeta.MetaDescription.Actions[i])
+			//hooks are called by the processor before the change instead of being notified about it
+			if currentMeta.MetaDescription.Actions[i].Hook {
+				currentMeta.Hooks = append(currentMeta.Hooks, &currentMeta.MetaDescription.Actions[i])
+			} else {
+				currentMeta.Actions = append(currentMeta.Actions, &currentMeta.MetaDescription.Actions[i])
+			}
 		}
 	}
 
@@ -117,6 +138,9 @@ func (mc *MetaCache) resolveMeta(currentMeta *Meta) error {
 	if err := checkOuterLinks(currentMeta); err != nil {
 		return err
 	}
+	if err := checkFormulas(currentMeta); err != nil {
+		return err
+	}
 	return nil
 
 }
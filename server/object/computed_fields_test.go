@@ -0,0 +1,162 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/errors"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	objectErrors "custodian/server/object/errors"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Computed fields", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	havingObjectsWithComputedFields := func() (*object.Meta, *object.Meta) {
+		customerDescription := description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name: "name",
+					Type: description.FieldTypeString,
+				},
+			},
+		}
+		customerMeta, err := metaStore.NewMeta(&customerDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(customerMeta)
+		Expect(err).To(BeNil())
+
+		orderDescription := description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name: "qty",
+					Type: description.FieldTypeNumber,
+				},
+				{
+					Name: "price",
+					Type: description.FieldTypeNumber,
+				},
+				{
+					Name:     "customer",
+					Type:     description.FieldTypeObject,
+					LinkMeta: customerMeta.Name,
+					LinkType: description.LinkTypeInner,
+					OnDelete: "cascade",
+				},
+				{
+					Name:     "total",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Formula:  "qty * price",
+					Stored:   true,
+				},
+				{
+					Name:     "customer_name",
+					Type:     description.FieldTypeString,
+					Optional: true,
+					Formula:  "customer.name",
+				},
+			},
+		}
+		orderMeta, err := metaStore.NewMeta(&orderDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(orderMeta)
+		Expect(err).To(BeNil())
+		return customerMeta, orderMeta
+	}
+
+	It("Computes stored and virtual fields", func() {
+		customerMeta, orderMeta := havingObjectsWithComputedFields()
+		customer, err := dataProcessor.CreateRecord(customerMeta.Name, map[string]interface{}{"name": "Smith"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		order, err := dataProcessor.CreateRecord(orderMeta.Name, map[string]interface{}{"qty": 2, "price": 3.5, "customer": customer.Pk()}, auth.User{})
+		Expect(err).To(BeNil())
+
+		record, err := dataProcessor.Get(orderMeta.Name, order.PkAsString(), nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(record.Data["total"]).To(Equal(7.0))
+		Expect(record.Data["customer_name"]).To(Equal("Smith"))
+	})
+
+	It("Queries and sorts records by computed fields", func() {
+		customerMeta, orderMeta := havingObjectsWithComputedFields()
+		for _, name := range []string{"Smith", "Adams"} {
+			customer, err := dataProcessor.CreateRecord(customerMeta.Name, map[string]interface{}{"name": name}, auth.User{})
+			Expect(err).To(BeNil())
+			_, err = dataProcessor.CreateRecord(orderMeta.Name, map[string]interface{}{"qty": 1, "price": len(name), "customer": customer.Pk()}, auth.User{})
+			Expect(err).To(BeNil())
+		}
+
+		_, records, err := dataProcessor.GetBulk(orderMeta.Name, "eq(customer_name,Adams)", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Data["customer_name"]).To(Equal("Adams"))
+
+		_, records, err = dataProcessor.GetBulk(orderMeta.Name, "gt(total,0),sort(customer_name)", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Data["customer_name"]).To(Equal("Adams"))
+		Expect(records[1].Data["customer_name"]).To(Equal("Smith"))
+	})
+
+	It("Doesn't allow to set values of computed fields", func() {
+		customerMeta, orderMeta := havingObjectsWithComputedFields()
+		customer, err := dataProcessor.CreateRecord(customerMeta.Name, map[string]interface{}{"name": "Smith"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		_, err = dataProcessor.CreateRecord(orderMeta.Name, map[string]interface{}{"qty": 1, "price": 1, "customer": customer.Pk(), "total": 10}, auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(objectErrors.ErrComputedFieldReadOnly))
+	})
+
+	It("Doesn't allow stored fields to refer to linked objects", func() {
+		metaDescription := description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber},
+				{Name: "total", Type: description.FieldTypeNumber, Formula: "customer.name", Stored: true},
+			},
+		}
+		_, err := (&description.MetaValidationService{}).Validate(&metaDescription)
+		Expect(err).NotTo(BeNil())
+	})
+})
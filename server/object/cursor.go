@@ -0,0 +1,123 @@
+package object
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"custodian/server/object/description"
+
+	rqlParser "github.com/Q-CIS-DEV/go-rql-parser"
+)
+
+//Cursor of the keyset pagination, it holds the sort order and values of the sort fields of the last record on the page
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+func decodeCursor(encoded string) (*cursor, error) {
+	document, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, NewRqlError(ErrRQLWrongCursor, "Cursor '%s' is malformed", encoded)
+	}
+	c := new(cursor)
+	if err := json.Unmarshal(document, c); err != nil {
+		return nil, NewRqlError(ErrRQLWrongCursor, "Cursor '%s' is malformed", encoded)
+	}
+	return c, nil
+}
+
+func (c *cursor) encode() string {
+	document, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(document)
+}
+
+//Column of the sort order the keyset predicate is built on
+type keysetColumn struct {
+	Field *FieldDescription
+	Expr  string
+	Desc  bool
+}
+
+//Key fields are never NULL, even if they are optional since they have a default value
+func (column keysetColumn) isNullable() bool {
+	if column.Field.Rollup != nil {
+		return true
+	}
+	return column.Field.Optional && column.Field.Name != column.Field.Meta.Key.Name
+}
+
+//Returns the sort order in the RQL notation, eg: "-created,+id"
+func keysetSortString(sorts []rqlParser.Sort) string {
+	parts := make([]string, len(sorts))
+	for i, sort := range sorts {
+		if sort.Desc {
+			parts[i] = "-" + sort.By
+		} else {
+			parts[i] = "+" + sort.By
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+//Checks if the field can be used in the keyset predicate: its value must be fetched with the record and be comparable
+func isKeysetField(field *FieldDescription) bool {
+	if field.IsVirtual() {
+		return false
+	}
+	switch field.Type {
+	case description.FieldTypeJSON, description.FieldTypeStringArray, description.FieldTypeNumberArray:
+		return false
+	case description.FieldTypeObject:
+		return field.LinkType == description.LinkTypeInner
+	}
+	return field.IsSimple()
+}
+
+//Builds the predicate selecting records which follow the cursor position in the sort order.
+//Postgres puts NULLs last in the ascending order and first in the descending one, so that
+//for each column "after" and "equal" conditions are built with respect to NULL values
+func (ctx *context) keysetPredicate(columns []keysetColumn, values []interface{}) string {
+	alternatives := make([]string, 0, len(columns))
+	equalities := make([]string, 0, len(columns))
+	for i, column := range columns {
+		var after, equal string
+		if values[i] == nil {
+			equal = column.Expr + " IS NULL"
+			if column.Desc {
+				after = column.Expr + " IS NOT NULL"
+			}
+		} else {
+			bind := ctx.addBind(keysetBind(values[i]))
+			equal = column.Expr + " = " + bind
+			if column.Desc {
+				after = column.Expr + " < " + bind
+			} else if column.isNullable() {
+				after = "(" + column.Expr + " > " + bind + " OR " + column.Expr + " IS NULL)"
+			} else {
+				after = column.Expr + " > " + bind
+			}
+		}
+		if after != "" {
+			alternatives = append(alternatives, "("+strings.Join(append(equalities, after), " AND ")+")")
+		}
+		equalities = append(equalities, equal)
+	}
+	if len(alternatives) == 0 {
+		return "FALSE"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+//Values are bound as strings, the same way the record values are
+func keysetBind(value interface{}) interface{} {
+	switch value := value.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return value
+}
@@ -2,6 +2,8 @@ package object
 
 import (
 	"bytes"
+	"database/sql"
+	"encoding/json"
 	"custodian/logger"
 	"custodian/server/auth"
 	errors2 "custodian/server/errors"
@@ -11,6 +13,7 @@ import (
 	"custodian/server/transactions"
 	"custodian/utils"
 	"strconv"
+	"strings"
 
 	"fmt"
 
@@ -38,10 +41,20 @@ type Processor struct {
 	metaStore          *MetaStore
 	transactionManager transactions.DbTransactionManager
 	vCache             map[string]objectClassValidator
+	//notifications are kept until the batch is committed
+	deferNotifications    bool
+	deferredNotifications []func()
+	//notifications are written to the outbox along with the records instead of being pushed
+	outbox bool
 }
 
 func NewProcessor(m *MetaStore, t transactions.DbTransactionManager) (*Processor, error) {
-	return &Processor{m, t, make(map[string]objectClassValidator)}, nil
+	return &Processor{m, t, make(map[string]objectClassValidator), false, nil, false}, nil
+}
+
+//Write notifications to the outbox in the transaction of the records, they are delivered by the NotificationOutbox
+func (processor *Processor) UseOutbox() {
+	processor.outbox = true
 }
 
 type SearchContext struct {
@@ -50,6 +63,7 @@ type SearchContext struct {
 	LazyPath      string
 	OmitOuters    bool
 	DbTransaction transactions.DbTransaction
+	Pagination    *Pagination
 }
 
 func IsBackLink(m *Meta, f *FieldDescription) bool {
@@ -62,6 +76,15 @@ func IsBackLink(m *Meta, f *FieldDescription) bool {
 }
 
 func (processor *Processor) Get(objectClass, key string, includePaths []string, excludePaths []string, depth int, omitOuters bool) (*Record, error) {
+	return processor.get(objectClass, key, includePaths, excludePaths, depth, omitOuters, false)
+}
+
+//Returns the record even if it is marked as deleted
+func (processor *Processor) GetWithDeleted(objectClass, key string, includePaths []string, excludePaths []string, depth int, omitOuters bool) (*Record, error) {
+	return processor.get(objectClass, key, includePaths, excludePaths, depth, omitOuters, true)
+}
+
+func (processor *Processor) get(objectClass, key string, includePaths []string, excludePaths []string, depth int, omitOuters bool, withDeleted bool) (*Record, error) {
 	if objectMeta, e := processor.GetMeta(objectClass); e != nil {
 		return nil, e
 	} else {
@@ -75,6 +98,18 @@ func (processor *Processor) Get(objectClass, key string, includePaths []string,
 
 			ctx := SearchContext{DepthLimit: depth, processor: processor, LazyPath: "/custodian/data", DbTransaction: transaction, OmitOuters: omitOuters}
 
+			//records marked as deleted are hidden
+			if objectMeta.SoftDelete && !withDeleted {
+				deletedField := objectMeta.FindField(description.SoftDeleteFieldName)
+				if obj, e := processor.GetSystem(objectMeta, []*FieldDescription{deletedField}, objectMeta.Key.Name, pk, transaction); e != nil {
+					transaction.Rollback()
+					return nil, e
+				} else if obj == nil || obj[description.SoftDeleteFieldName] != nil {
+					transaction.Rollback()
+					return nil, nil
+				}
+			}
+
 			//
 			root := &Node{
 				KeyField:       objectMeta.Key,
@@ -114,6 +149,24 @@ func (processor *Processor) Get(objectClass, key string, includePaths []string,
 }
 
 func (processor *Processor) GetBulk(objectName string, filter string, includePaths []string, excludePaths []string, depth int, omitOuters bool) (int, []*Record, error) {
+	return processor.getBulk(objectName, filter, nil, includePaths, excludePaths, depth, omitOuters)
+}
+
+//Returns the page of records described by the pagination, the state of the next page is set to the pagination
+func (processor *Processor) GetBulkPage(objectName string, filter string, pagination *Pagination, includePaths []string, excludePaths []string, depth int, omitOuters bool) (int, []*Record, error) {
+	return processor.getBulk(objectName, filter, pagination, includePaths, excludePaths, depth, omitOuters)
+}
+
+func (processor *Processor) getBulk(objectName string, filter string, pagination *Pagination, includePaths []string, excludePaths []string, depth int, omitOuters bool) (int, []*Record, error) {
+	rqlNode, err := rqlParser.NewParser().Parse(filter)
+	if err != nil {
+		return 0, nil, errors2.NewValidationError(errors.ErrWrongRQL, err.Error(), nil)
+	}
+	return processor.getBulkByRql(objectName, rqlNode, pagination, includePaths, excludePaths, depth, omitOuters)
+}
+
+//Returns records matching the parsed RQL, so that values of the conditions can be given as is without being escaped
+func (processor *Processor) getBulkByRql(objectName string, rqlNode *rqlParser.RqlRootNode, pagination *Pagination, includePaths []string, excludePaths []string, depth int, omitOuters bool) (int, []*Record, error) {
 	if businessObject, ok, e := processor.metaStore.Get(objectName, true); e != nil {
 		return 0, nil, e
 	} else if !ok {
@@ -125,7 +178,7 @@ func (processor *Processor) GetBulk(objectName string, filter string, includePat
 		if e != nil {
 			return 0, nil, e
 		}
-		searchContext := SearchContext{DepthLimit: depth, processor: processor, LazyPath: "/custodian/data/bulk", DbTransaction: transaction, OmitOuters: omitOuters}
+		searchContext := SearchContext{DepthLimit: depth, processor: processor, LazyPath: "/custodian/data/bulk", DbTransaction: transaction, OmitOuters: omitOuters, Pagination: pagination}
 
 		//make and apply retrieves policy
 		retrievePolicy := new(AggregatedRetrievePolicyFactory).Factory(includePaths, excludePaths)
@@ -151,15 +204,6 @@ func (processor *Processor) GetBulk(objectName string, filter string, includePat
 		// 	return 0, nil, errors2.NewValidationError(errors.ErrWrongRQL, err.Error(), nil)
 		// }
 
-		parser := rqlParser.NewParser()
-
-		rqlNode, err := parser.Parse(filter)
-		if err != nil {
-
-			transaction.Rollback()
-			return 0, nil, errors2.NewValidationError(errors.ErrWrongRQL, err.Error(), nil)
-		}
-
 		records, recordsCount, e := root.ResolveByRql(searchContext, rqlNode)
 
 		if e != nil {
@@ -173,6 +217,70 @@ func (processor *Processor) GetBulk(objectName string, filter string, includePat
 	}
 }
 
+//Returns records with the given keys, keys are matched by the key field of the object without being interpreted as RQL
+func (processor *Processor) GetBulkByKeys(objectName string, keys []interface{}, includePaths []string, excludePaths []string, depth int, omitOuters bool) (int, []*Record, error) {
+	objectMeta, err := processor.GetMeta(objectName)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(keys) == 0 {
+		return 0, []*Record{}, nil
+	}
+	keysAsStrings := make([]string, len(keys))
+	for i, key := range keys {
+		if keysAsStrings[i], err = objectMeta.Key.ValueAsString(key); err != nil {
+			return 0, nil, err
+		}
+	}
+	return processor.getBulkByRql(objectName, newRqlRootNode(newInRqlNode(objectMeta.Key.Name, keysAsStrings)), nil, includePaths, excludePaths, depth, omitOuters)
+}
+
+func (processor *Processor) Aggregate(objectName string, filter string) (int, []map[string]interface{}, error) {
+	businessObject, err := processor.GetMeta(objectName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	parser := rqlParser.NewParser()
+	rqlNode, err := parser.Parse(filter)
+	if err != nil {
+		return 0, nil, errors2.NewValidationError(errors.ErrWrongRQL, err.Error(), nil)
+	}
+
+	aggregation, err := ExtractAggregation(rqlNode)
+	if err != nil {
+		return 0, nil, err
+	} else if aggregation == nil {
+		return 0, nil, errors2.NewValidationError(errors.ErrWrongRQL, "Neither 'aggregate' nor 'groupby' rql function is specified", nil)
+	}
+
+	transaction, err := processor.transactionManager.BeginTransaction()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	root := &Node{
+		KeyField:     businessObject.Key,
+		Meta:         businessObject,
+		ChildNodes:   *NewChildNodes(),
+		Depth:        1,
+		OnlyLink:     false,
+		Plural:       false,
+		Parent:       nil,
+		Type:         NodeTypeRegular,
+		SelectFields: *NewSelectFields(businessObject.Key, businessObject.TableFields()),
+	}
+
+	recordsData, count, err := processor.GetRqlAggregation(root, rqlNode, aggregation, transaction)
+	if err != nil {
+		transaction.Rollback()
+		return 0, nil, err
+	}
+
+	transaction.Commit()
+	return count, recordsData, nil
+}
+
 type DNode struct {
 	KeyField   *FieldDescription
 	Meta       *Meta
@@ -239,12 +347,44 @@ func SetRecordOwner(objectMeta *Meta, recordData map[string]interface{}, user au
 
 // CreateRecord create object record in database
 func (processor *Processor) CreateRecord(objectName string, recordData map[string]interface{}, user auth.User) (*Record, error) {
+	return processor.createRecord(objectName, recordData, nil, user)
+}
+
+//Creates the record or updates the existing one having the same values of the upsert fields, the key is used if no fields are given.
+//The authorize callback is called with the existing record and the data before the record is updated, it can be nil
+func (processor *Processor) UpsertRecord(objectName string, recordData map[string]interface{}, upsertFields []string, authorize func(*Record, map[string]interface{}) error, user auth.User) (*Record, error) {
+	objectMeta, err := processor.GetMeta(objectName)
+	if err != nil {
+		return nil, err
+	}
+	if len(upsertFields) == 0 {
+		upsertFields = []string{objectMeta.Key.Name}
+	}
+	if err := objectMeta.checkUpsertFields(upsertFields); err != nil {
+		return nil, err
+	}
+	return processor.createRecord(objectName, recordData, &upsertSettings{upsertFields, authorize}, user)
+}
+
+func (processor *Processor) createRecord(objectName string, recordData map[string]interface{}, upsert *upsertSettings, user auth.User) (*Record, error) {
+	if processor.requiresTransaction() {
+		var record *Record
+		err := processor.atomically(func() (err error) {
+			record, err = processor.createRecord(objectName, recordData, upsert, user)
+			return err
+		})
+		return record, err
+	}
 	// get MetaDescription
 	objectMeta, err := processor.GetMeta(objectName)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := processor.callCreateHooks(objectMeta, upsert, recordData, user); err != nil {
+		return nil, err
+	}
+
 	// extract processing node
 	recordProcessingNode, err := new(RecordProcessingTreeBuilder).Build(&Record{Meta: objectMeta, Data: recordData, processor: processor}, processor)
 	if err != nil {
@@ -267,6 +407,7 @@ func (processor *Processor) CreateRecord(objectName string, recordData map[strin
 			if _, err := processor.createRecordSet(
 				recordSetOperation.RecordSet,
 				isRoot,
+				rootUpsert(isRoot, upsert),
 				recordSetNotificationPool,
 			); err != nil {
 				return nil, err
@@ -310,12 +451,47 @@ func (processor *Processor) CreateRecord(objectName string, recordData map[strin
 		}
 	}
 
-	recordSetNotificationPool.Push(description.MethodCreate, user)
+	if err = processor.pushNotifications(recordSetNotificationPool, description.MethodCreate, user); err != nil {
+		return nil, err
+	}
+	if upsert != nil {
+		if err = processor.pushNotifications(recordSetNotificationPool, description.MethodUpdate, user); err != nil {
+			return nil, err
+		}
+	}
 
 	return NewRecord(objectMeta, recordData, processor), nil
 }
 
 func (processor *Processor) BulkCreateRecords(objectName string, recordData []map[string]interface{}, user auth.User) ([]*Record, error) {
+	return processor.bulkCreateRecords(objectName, recordData, nil, user)
+}
+
+//Creates the records or updates the existing ones having the same values of the upsert fields, the key is used if no fields are given.
+//The authorize callback is called with each existing record and its data before the record is updated, it can be nil
+func (processor *Processor) BulkUpsertRecords(objectName string, recordData []map[string]interface{}, upsertFields []string, authorize func(*Record, map[string]interface{}) error, user auth.User) ([]*Record, error) {
+	objectMeta, err := processor.GetMeta(objectName)
+	if err != nil {
+		return nil, err
+	}
+	if len(upsertFields) == 0 {
+		upsertFields = []string{objectMeta.Key.Name}
+	}
+	if err := objectMeta.checkUpsertFields(upsertFields); err != nil {
+		return nil, err
+	}
+	return processor.bulkCreateRecords(objectName, recordData, &upsertSettings{upsertFields, authorize}, user)
+}
+
+func (processor *Processor) bulkCreateRecords(objectName string, recordData []map[string]interface{}, upsert *upsertSettings, user auth.User) ([]*Record, error) {
+	if processor.requiresTransaction() {
+		var records []*Record
+		err := processor.atomically(func() (err error) {
+			records, err = processor.bulkCreateRecords(objectName, recordData, upsert, user)
+			return err
+		})
+		return records, err
+	}
 
 	// get MetaDescription
 	objectMeta, err := processor.GetMeta(objectName)
@@ -332,6 +508,9 @@ func (processor *Processor) BulkCreateRecords(objectName string, recordData []ma
 	rootRecordSets := make([]interface{}, 0)
 	for _, record := range recordData {
 		SetRecordOwner(objectMeta, record, user)
+		if err := processor.callCreateHooks(objectMeta, upsert, record, user); err != nil {
+			return nil, err
+		}
 		// extract processing node
 		recordProcessingNode, err = new(RecordProcessingTreeBuilder).Build(
 			&Record{Meta: objectMeta, Data: record, processor: processor}, processor,
@@ -348,6 +527,7 @@ func (processor *Processor) BulkCreateRecords(objectName string, recordData []ma
 				if _, err := processor.createRecordSet(
 					recordSetOperation.RecordSet,
 					isRoot,
+					rootUpsert(isRoot, upsert),
 					recordSetNotificationPool,
 				); err != nil {
 					return nil, err
@@ -393,17 +573,34 @@ func (processor *Processor) BulkCreateRecords(objectName string, recordData []ma
 		return nil, err
 	}
 
-	recordSetNotificationPool.Push(description.MethodCreate, user)
+	if err = processor.pushNotifications(recordSetNotificationPool, description.MethodCreate, user); err != nil {
+		return nil, err
+	}
+	if upsert != nil {
+		if err = processor.pushNotifications(recordSetNotificationPool, description.MethodUpdate, user); err != nil {
+			return nil, err
+		}
+	}
 
 	return result, nil
 }
 
 func (processor *Processor) UpdateRecord(objectName, key string, recordData map[string]interface{}, user auth.User) (updatedRecord *Record, err error) {
+	if processor.requiresTransaction() {
+		err = processor.atomically(func() (err error) {
+			updatedRecord, err = processor.UpdateRecord(objectName, key, recordData, user)
+			return err
+		})
+		return updatedRecord, err
+	}
 	// get MetaDescription
 	objectMeta, err := processor.GetMeta(objectName)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkCasValue(objectMeta, recordData); err != nil {
+		return nil, err
+	}
 	// get and fill key value
 	if pkValue, e := objectMeta.Key.ValueFromString(key); e != nil {
 		return nil, e
@@ -411,6 +608,17 @@ func (processor *Processor) UpdateRecord(objectName, key string, recordData map[
 		//recordData data must contain valid recordData`s PK value
 		recordData[objectMeta.Key.Name] = pkValue
 	}
+	if len(objectMeta.Hooks) > 0 {
+		previous, err := processor.Get(objectName, key, nil, nil, 1, true)
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil {
+			if err := processor.callHooks(objectMeta, description.MethodUpdate, previous.GetData(), recordData, user); err != nil {
+				return nil, err
+			}
+		}
+	}
 	// extract processing node
 	recordProcessingNode, err := new(RecordProcessingTreeBuilder).Build(&Record{Meta: objectMeta, Data: recordData, processor: processor}, processor)
 	if err != nil {
@@ -438,6 +646,7 @@ func (processor *Processor) UpdateRecord(objectName, key string, recordData map[
 			if _, err := processor.createRecordSet(
 				recordSetOperation.RecordSet,
 				isRoot,
+				nil,
 				recordSetNotificationPool,
 			); err != nil {
 				return nil, err
@@ -473,12 +682,19 @@ func (processor *Processor) UpdateRecord(objectName, key string, recordData map[
 		}
 	}
 
-	recordSetNotificationPool.Push(description.MethodUpdate, user)
+	if err = processor.pushNotifications(recordSetNotificationPool, description.MethodUpdate, user); err != nil {
+		return nil, err
+	}
 
 	return rootRecordSet.Records[0], nil
 }
 
 func (processor *Processor) BulkUpdateRecords(objectName string, next func() (map[string]interface{}, error), sink func(map[string]interface{}) error, user auth.User) (err error) {
+	if processor.requiresTransaction() {
+		return processor.atomically(func() error {
+			return processor.BulkUpdateRecords(objectName, next, sink, user)
+		})
+	}
 
 	//get MetaDescription
 	objectMeta, err := processor.GetMeta(objectName)
@@ -495,6 +711,22 @@ func (processor *Processor) BulkUpdateRecords(objectName string, next func() (ma
 	if err != nil {
 		return err
 	}
+	for _, record := range records {
+		if err = checkCasValue(objectMeta, record.Data); err != nil {
+			return err
+		}
+		if len(objectMeta.Hooks) > 0 {
+			previous, err := processor.Get(objectName, record.PkAsString(), nil, nil, 1, true)
+			if err != nil {
+				return err
+			}
+			if previous != nil {
+				if err := processor.callHooks(objectMeta, description.MethodUpdate, previous.GetData(), record.Data, user); err != nil {
+					return err
+				}
+			}
+		}
+	}
 
 	//assemble RecordSetOperations
 	var recordProcessingNode *RecordProcessingNode
@@ -522,6 +754,7 @@ func (processor *Processor) BulkUpdateRecords(objectName string, next func() (ma
 				if _, err := processor.createRecordSet(
 					recordSetOperation.RecordSet,
 					isRoot,
+					nil,
 					recordSetNotificationPool,
 				); err != nil {
 					return err
@@ -557,7 +790,9 @@ func (processor *Processor) BulkUpdateRecords(objectName string, next func() (ma
 	// feed updated data to the sink
 	processor.feedRecordSets(rootRecordSets, sink)
 
-	recordSetNotificationPool.Push(description.MethodUpdate, user)
+	if err = processor.pushNotifications(recordSetNotificationPool, description.MethodUpdate, user); err != nil {
+		return err
+	}
 
 	return nil
 
@@ -565,6 +800,14 @@ func (processor *Processor) BulkUpdateRecords(objectName string, next func() (ma
 
 //TODO: Refactor this method similarly to UpdateRecord, so notifications could be tested properly, it should affect PrepareDeletes method
 func (processor *Processor) RemoveRecord(objectName string, key string, user auth.User) (*Record, error) {
+	if processor.requiresTransaction() {
+		var record *Record
+		err := processor.atomically(func() (err error) {
+			record, err = processor.RemoveRecord(objectName, key, user)
+			return err
+		})
+		return record, err
+	}
 	var err error
 
 	//get pk
@@ -575,7 +818,6 @@ func (processor *Processor) RemoveRecord(objectName string, key string, user aut
 	if recordToRemove == nil {
 		return nil, errors2.NewNotFoundError("RecordNotFound", "Record not found", nil)
 	}
-
 	// create notification pool
 	recordSetNotificationPool := NewRecordSetNotificationPool()
 	defer func() { recordSetNotificationPool.CompleteSend(err) }()
@@ -591,6 +833,11 @@ func (processor *Processor) RemoveRecord(objectName string, key string, user aut
 		dbTransaction.Rollback()
 		return nil, err
 	}
+	//hooks are called for records removed by cascade as well
+	if err = processor.callRemovalHooks(removalRootNode, user); err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
 
 	err = processor.PerformRemove(removalRootNode, dbTransaction, recordSetNotificationPool)
 	if err != nil {
@@ -602,13 +849,135 @@ func (processor *Processor) RemoveRecord(objectName string, key string, user aut
 	dbTransaction.Commit()
 	// push notifications if needed
 
-	recordSetNotificationPool.Push(description.MethodRemove, user)
+	if err = processor.pushNotifications(recordSetNotificationPool, description.MethodRemove, user); err != nil {
+		return nil, err
+	}
 
 	return removalRootNode.Record, nil
 }
 
+//Restore the record marked as deleted along with records which were deleted with it by cascade
+func (processor *Processor) RestoreRecord(objectName string, key string, user auth.User) (restoredRecord *Record, err error) {
+	if processor.requiresTransaction() {
+		err = processor.atomically(func() (err error) {
+			restoredRecord, err = processor.RestoreRecord(objectName, key, user)
+			return err
+		})
+		return restoredRecord, err
+	}
+	objectMeta, err := processor.GetMeta(objectName)
+	if err != nil {
+		return nil, err
+	}
+	if !objectMeta.SoftDelete {
+		return nil, errors2.NewValidationError(ErrSoftDeleteDisabled, fmt.Sprintf("Object '%s' does not support soft delete", objectName), nil)
+	}
+
+	recordToRestore, err := processor.GetWithDeleted(objectName, key, nil, nil, 1, true)
+	if err != nil {
+		return nil, err
+	}
+	if recordToRestore == nil {
+		return nil, errors2.NewNotFoundError("RecordNotFound", "Record not found", nil)
+	}
+	deletedAt, ok := recordToRestore.Data[description.SoftDeleteFieldName].(string)
+	if !ok {
+		return nil, errors2.NewValidationError(ErrRecordNotDeleted, "Record is not deleted", nil)
+	}
+
+	recordsToRestore, err := processor.collectRecordsDeletedWith(recordToRestore, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	//restoration is the update of the deletion mark, so it can only be rejected by hooks
+	for _, record := range recordsToRestore {
+		restoredData := map[string]interface{}{record.Meta.Key.Name: record.Pk(), description.SoftDeleteFieldName: nil}
+		if err = processor.callHooks(record.Meta, description.MethodUpdate, record.GetData(), restoredData, user); err != nil {
+			return nil, err
+		}
+	}
+
+	// create notification pool
+	recordSetNotificationPool := NewRecordSetNotificationPool()
+	defer func() { recordSetNotificationPool.CompleteSend(err) }()
+
+	operations := make([]transactions.Operation, 0)
+	for i, record := range recordsToRestore {
+		operation, err := processor.PrepareSoftRemoveOperation(record, true)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+
+		restoredData := map[string]interface{}{record.Meta.Key.Name: record.Pk(), description.SoftDeleteFieldName: nil}
+		recordSetNotification := NewRecordSetNotification(&RecordSet{Meta: record.Meta, Records: []*Record{NewRecord(record.Meta, restoredData, processor)}}, i == 0, description.MethodUpdate)
+		recordSetNotification.CapturePreviousState([]*Record{record})
+		recordSetNotification.CaptureCurrentState([]*Record{NewRecord(record.Meta, restoredData, processor)})
+		recordSetNotificationPool.Add(recordSetNotification)
+	}
+
+	dbTransaction, err := processor.transactionManager.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	if err = dbTransaction.Execute(operations); err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+	dbTransaction.Commit()
+
+	if err = processor.pushNotifications(recordSetNotificationPool, description.MethodUpdate, user); err != nil {
+		return nil, err
+	}
+
+	recordToRestore.Data[description.SoftDeleteFieldName] = nil
+	return recordToRestore, nil
+}
+
+//Collect the record and records of soft-deletable objects which were deleted by cascade at the same time with it
+func (processor *Processor) collectRecordsDeletedWith(record *Record, deletedAt string) ([]*Record, error) {
+	records := []*Record{record}
+	builder := new(RecordRemovalTreeBuilder)
+	pkAsString, err := record.Meta.Key.ValueAsString(record.Pk())
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range record.Meta.Fields {
+		if field.Type != description.FieldTypeArray && !(field.Type == description.FieldTypeGeneric && field.LinkType == description.LinkTypeOuter) {
+			continue
+		}
+		if !field.LinkMeta.SoftDelete || *field.OuterLinkField.OnDeleteStrategy() != description.OnDeleteCascade {
+			continue
+		}
+		filter := builder.makeFilter(field.OuterLinkField, pkAsString)
+		if field.Type == description.FieldTypeGeneric {
+			filter = builder.makeGenericFilter(field.OuterLinkField, record.Meta.Name, pkAsString)
+		}
+		filter = newRqlRootNode(filter.Node, &rqlParser.RqlNode{Op: "with_deleted"})
+		_, relatedRecords, err := processor.getBulkByRql(field.LinkMeta.Name, filter, nil, nil, nil, 1, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, relatedRecord := range relatedRecords {
+			if relatedRecord.Data[description.SoftDeleteFieldName] == deletedAt {
+				children, err := processor.collectRecordsDeletedWith(NewRecord(field.LinkMeta, relatedRecord.Data, processor), deletedAt)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, children...)
+			}
+		}
+	}
+	return records, nil
+}
+
 //TODO: Refactor this method similarly to BulkUpdateRecords, so notifications could be tested properly, it should affect PrepareDeletes method
 func (processor *Processor) BulkDeleteRecords(objectName string, next func() (map[string]interface{}, error), user auth.User) (err error) {
+	if processor.requiresTransaction() {
+		return processor.atomically(func() error {
+			return processor.BulkDeleteRecords(objectName, next, user)
+		})
+	}
 	// get MetaDescription
 	objectMeta, err := processor.GetMeta(objectName)
 	if err != nil {
@@ -635,7 +1004,6 @@ func (processor *Processor) BulkDeleteRecords(objectName string, next func() (ma
 		if recordToRemove == nil {
 			return errors2.NewNotFoundError("RecordNotFound", "Record not found", nil)
 		}
-
 		//fill node
 		dbTransaction, err := processor.transactionManager.BeginTransaction()
 		if err != nil {
@@ -647,6 +1015,10 @@ func (processor *Processor) BulkDeleteRecords(objectName string, next func() (ma
 			dbTransaction.Rollback()
 			return err
 		}
+		if err = processor.callRemovalHooks(removalRootNode, user); err != nil {
+			dbTransaction.Rollback()
+			return err
+		}
 
 		err = processor.PerformRemove(removalRootNode, dbTransaction, recordSetNotificationPool)
 		if err != nil {
@@ -658,13 +1030,71 @@ func (processor *Processor) BulkDeleteRecords(objectName string, next func() (ma
 		dbTransaction.Commit()
 		// push notifications if needed
 
-		recordSetNotificationPool.Push(description.MethodRemove, user)
+		if err = processor.pushNotifications(recordSetNotificationPool, description.MethodRemove, user); err != nil {
+			return err
+		}
 
 	}
 
 	// push notifications if needed
-	recordSetNotificationPool.Push(description.MethodRemove, user)
+	if err = processor.pushNotifications(recordSetNotificationPool, description.MethodRemove, user); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//record history and push notifications of the given method
+func (processor *Processor) pushNotifications(recordSetNotificationPool *RecordSetNotificationPool, method description.Method, user auth.User) error {
+	//history is committed or rolled back along with the records
+	if err := processor.recordHistory(recordSetNotificationPool, method, user); err != nil {
+		return err
+	}
+	if processor.outbox {
+		//notifications are committed or rolled back along with the records
+		return processor.writeOutbox(recordSetNotificationPool, method, user)
+	}
+	if processor.deferNotifications {
+		processor.deferredNotifications = append(processor.deferredNotifications, func() {
+			recordSetNotificationPool.Push(method, user)
+		})
+		return nil
+	}
+	recordSetNotificationPool.Push(method, user)
+	return nil
+}
+
+//History and outbox entries are committed along with the records, so each change needs a transaction unless it is a part of one
+func (processor *Processor) requiresTransaction() bool {
+	return !processor.transactionManager.InSharedTransaction()
+}
 
+//Runs operations of the processor in one transaction, nothing is changed if any of them fails.
+//Notifications of the operations are sent only after the commit
+func (processor *Processor) atomically(operations func() error) error {
+	//operations are already a part of the shared transaction
+	if processor.transactionManager.InSharedTransaction() {
+		return operations()
+	}
+	if _, err := processor.transactionManager.BeginSharedTransaction(); err != nil {
+		return err
+	}
+	processor.deferNotifications = true
+	defer func() {
+		processor.deferNotifications = false
+		processor.deferredNotifications = nil
+	}()
+
+	if err := operations(); err != nil {
+		processor.transactionManager.CompleteSharedTransaction(false)
+		return err
+	}
+	if err := processor.transactionManager.CompleteSharedTransaction(true); err != nil {
+		return errors2.NewFatalError(ErrCommitFailed, err.Error(), nil)
+	}
+	for _, push := range processor.deferredNotifications {
+		push()
+	}
 	return nil
 }
 
@@ -708,8 +1138,11 @@ func (processor *Processor) updateRecordSet(recordSet *RecordSet, isRoot bool, r
 	// create notification, capture current recordData state and Add notification to notification pool
 	recordSetNotification := NewRecordSetNotification(recordSet, isRoot, description.MethodUpdate)
 	if recordSetNotification.ShouldBeProcessed(description.MethodUpdate) {
-		previous, _ := processor.Get(recordSet.Meta.Name, recordSet.Records[0].PkAsString(), nil, nil, 1, true)
-		recordSetNotification.CapturePreviousState([]*Record{previous})
+		previousState := make([]*Record, len(recordSet.Records))
+		for i, record := range recordSet.Records {
+			previousState[i], _ = processor.Get(recordSet.Meta.Name, record.PkAsString(), nil, nil, 1, true)
+		}
+		recordSetNotification.CapturePreviousState(previousState)
 	}
 
 	var operations = make([]transactions.Operation, 0)
@@ -743,17 +1176,26 @@ func (processor *Processor) updateRecordSet(recordSet *RecordSet, isRoot bool, r
 }
 
 // perform create and return list of records
-func (processor *Processor) createRecordSet(recordSet *RecordSet, isRoot bool, recordSetNotificationPool *RecordSetNotificationPool) (*RecordSet, error) {
+//Records conflicting with the existing ones by the upsert fields are updated and notified about as updated ones
+func (processor *Processor) createRecordSet(recordSet *RecordSet, isRoot bool, upsert *upsertSettings, recordSetNotificationPool *RecordSetNotificationPool) (*RecordSet, error) {
 	recordSet.PrepareData(RecordOperationTypeCreate)
-	// create notification, capture current recordData state and Add notification to notification pool
-	recordSetNotification := NewRecordSetNotification(recordSet, isRoot, description.MethodCreate)
-	if recordSetNotification.ShouldBeProcessed(description.MethodCreate) {
-		recordSetNotification.CapturePreviousState(make([]*Record, len(recordSet.Records)))
-	}
+	inserted := make([]bool, len(recordSet.Records))
+	targets := make([]*Record, len(recordSet.Records))
 
 	var operations = make([]transactions.Operation, 0)
 
-	if operation, e := processor.PrepareCreateOperation(recordSet.Meta, recordSet.RawData()); e != nil {
+	var upsertFields []string
+	if upsert != nil {
+		upsertFields = upsert.fields
+		//previous states of the records being updated are read within the transaction of the upsert
+		operations = append(operations, func(dbTransaction transactions.DbTransaction) (err error) {
+			if targets, err = processor.findUpsertTargets(recordSet, upsertFields, dbTransaction); err != nil {
+				return err
+			}
+			return checkUpsertTargets(recordSet, targets, upsert.authorize)
+		})
+	}
+	if operation, e := processor.PrepareUpsertOperation(recordSet.Meta, recordSet.RawData(), upsertFields, inserted); e != nil {
 		return nil, e
 	} else {
 		operations = append(operations, operation)
@@ -772,9 +1214,33 @@ func (processor *Processor) createRecordSet(recordSet *RecordSet, isRoot bool, r
 	dbTransaction.Commit()
 	recordSet.MergeData()
 
-	// create notification, capture current recordData state and Add notification to notification pool
-	if recordSetNotification.ShouldBeProcessed(description.MethodCreate) {
-		recordSetNotification.CaptureCurrentState(recordSet.Records)
+	if upsert == nil {
+		for i := range inserted {
+			inserted[i] = true
+		}
+	}
+	//inserted and updated records are notified about separately, the record is inserted or updated as the database reports
+	created := &RecordSet{Meta: recordSet.Meta, Records: make([]*Record, 0)}
+	updated := &RecordSet{Meta: recordSet.Meta, Records: make([]*Record, 0)}
+	updatedPreviousState := make([]*Record, 0)
+	for i, record := range recordSet.Records {
+		if inserted[i] {
+			created.Records = append(created.Records, record)
+		} else {
+			updated.Records = append(updated.Records, record)
+			updatedPreviousState = append(updatedPreviousState, targets[i])
+		}
+	}
+	if len(created.Records) > 0 {
+		recordSetNotification := NewRecordSetNotification(created, isRoot, description.MethodCreate)
+		recordSetNotification.CapturePreviousState(make([]*Record, len(created.Records)))
+		recordSetNotification.CaptureCurrentState(created.Records)
+		recordSetNotificationPool.Add(recordSetNotification)
+	}
+	if len(updated.Records) > 0 {
+		recordSetNotification := NewRecordSetNotification(updated, isRoot, description.MethodUpdate)
+		recordSetNotification.CapturePreviousState(updatedPreviousState)
+		recordSetNotification.CaptureCurrentState(updated.Records)
 		recordSetNotificationPool.Add(recordSetNotification)
 	}
 
@@ -807,7 +1273,7 @@ func (processor *Processor) PrepareUpdateOperation(m *Meta, recordValues []map[s
 			updateInfo.Filters = append(updateInfo.Filters, newBind(fieldName, currentColumnIndex))
 			valueExtractors = append(valueExtractors, identityVal)
 			//cas column
-		} else if fieldName == "cas" {
+		} else if fieldName == description.CasFieldName {
 
 			currentColumnIndex++
 			updateFields = append(updateFields, fieldName)
@@ -907,6 +1373,9 @@ func (processor *Processor) PrepareUpdateOperation(m *Meta, recordValues []map[s
 			if uo, err := stmt.ParsedSingleQuery(binds, rFields); err == nil {
 				updateNodes(recordValues[i], uo)
 			} else {
+				if _, ok := recordValues[i][description.CasFieldName]; ok {
+					return processor.casConflictError(m, recordValues[i], err, dbTransaction)
+				}
 				return err
 			}
 		}
@@ -915,7 +1384,53 @@ func (processor *Processor) PrepareUpdateOperation(m *Meta, recordValues []map[s
 	}, nil
 }
 
+//Record is not updated if its version differs from the one provided, this case is reported as a conflict
+//with the actual version in the error data
+func (processor *Processor) casConflictError(m *Meta, recordValues map[string]interface{}, err error, dbTransaction transactions.DbTransaction) error {
+	if serverError, ok := err.(*errors2.ServerError); !ok || serverError.Code != ErrNotFound {
+		return err
+	}
+	casField := m.FindField(description.CasFieldName)
+	if casField == nil {
+		return err
+	}
+	current, e := processor.GetSystem(m, []*FieldDescription{casField}, m.Key.Name, recordValues[m.Key.Name], dbTransaction)
+	if e != nil || current == nil {
+		return err
+	}
+	return newCasConflictError(m, current[description.CasFieldName])
+}
+
+func newCasConflictError(m *Meta, actualCas interface{}) error {
+	return errors2.NewConflictError(
+		ErrCasConflict,
+		fmt.Sprintf("Record of '%s' has been modified concurrently, its actual cas value is %v", m.Name, actualCas),
+		map[string]interface{}{description.CasFieldName: actualCas},
+	)
+}
+
+//CAS-enabled objects can be updated only if the expected version of the record is specified
+func checkCasValue(m *Meta, recordData map[string]interface{}) error {
+	if !m.Cas {
+		return nil
+	}
+	if _, ok := recordData[description.CasFieldName].(float64); !ok {
+		return errors2.NewValidationError(
+			ErrCasValueAbsent,
+			fmt.Sprintf("Object '%s' is CAS-enabled, numeric '%s' value must be specified to update its records", m.Name, description.CasFieldName),
+			nil,
+		)
+	}
+	return nil
+}
+
 func (processor *Processor) PrepareCreateOperation(m *Meta, recordsValues []map[string]interface{}) (transactions.Operation, error) {
+	return processor.PrepareUpsertOperation(m, recordsValues, nil, nil)
+}
+
+//Records conflicting with the existing ones by the upsert fields are updated, they are inserted if no upsert fields are given
+//Inserted is filled with flags telling whether the record is inserted or the conflicting one is updated
+func (processor *Processor) PrepareUpsertOperation(m *Meta, recordsValues []map[string]interface{}, upsertFields []string, inserted []bool) (transactions.Operation, error) {
 	if len(recordsValues) == 0 {
 		return emptyOperation, nil
 	}
@@ -926,7 +1441,26 @@ func (processor *Processor) PrepareCreateOperation(m *Meta, recordsValues []map[
 	insertFields, insertValuesPattern := utils.GetMapKeysValues(recordsValues[0])
 	insertColumns := getColumnsToInsert(insertFields, insertValuesPattern)
 
-	insertInfo := dml_info.NewInsertInfo(GetTableName(m.Name), insertColumns, getFieldsColumnsNames(fields), len(recordsValues))
+	//the creation time of the updated records is kept
+	updateColumns := make([]string, 0, len(insertColumns))
+	for _, column := range insertColumns {
+		if f := m.FindField(column); f == nil || !f.NowOnCreate {
+			updateColumns = append(updateColumns, column)
+		}
+	}
+	//the version of the record of CAS-enabled object is increased and the record is updated only if the version is not changed
+	versionColumn := ""
+	if m.Cas && len(upsertFields) > 0 {
+		versionColumn = description.CasFieldName
+		if !utils.Contains(updateColumns, versionColumn) {
+			updateColumns = append(updateColumns, versionColumn)
+		}
+	}
+	insertInfo := dml_info.NewUpsertInfo(GetTableName(m.Name), insertColumns, getFieldsColumnsNames(fields), len(recordsValues), upsertFields, updateColumns, versionColumn)
+	returnFields := fields
+	if len(upsertFields) > 0 {
+		returnFields = append(fields, &FieldDescription{Field: &description.Field{Name: dml_info.UpsertInsertedColumn, Type: description.FieldTypeBool}})
+	}
 	var insertDML bytes.Buffer
 	if err := parsedTemplInsert.Execute(&insertDML, insertInfo); err != nil {
 		return nil, errors2.NewFatalError(ErrTemplateFailed, err.Error(), nil)
@@ -936,7 +1470,7 @@ func (processor *Processor) PrepareCreateOperation(m *Meta, recordsValues []map[
 	for _, field := range insertFields {
 		if f := m.FindField(field); f != nil {
 			def := f.Default()
-			if d, ok := def.(description.DefExpr); ok && d.Func == "nextval" && f.Type == description.FieldTypeNumber {
+			if d, ok := def.(description.DefExpr); ok && d.Func == "nextval" && (f.Type == description.FieldTypeNumber || f.Type == description.FieldTypeInteger) {
 				if err := parsedTemplFixSequense.Execute(&fixSeqDML, map[string]interface{}{
 					"Table": insertInfo.Table,
 					"Field": field,
@@ -962,9 +1496,9 @@ func (processor *Processor) PrepareCreateOperation(m *Meta, recordsValues []map[
 			return err
 		}
 		defer stmt.Close()
-		dbObjs, err := stmt.ParsedQuery(binds, fields)
+		dbObjs, err := stmt.ParsedQuery(binds, returnFields)
 		if err != nil {
-			if err, ok := err.(*errors2.ServerError); ok && err.Code == ErrValueDuplication {
+			if err, ok := err.(*errors2.ServerError); ok && err.Code == ErrValueDuplication && err.Data != nil {
 				//dupTransaction, _ := dbTransaction.(*PgTransaction).Manager.BeginTransaction()
 				duplicates, dup_error := processor.GetAll(m, m.TableFields(), err.Data.(map[string]interface{}), dbTransaction)
 				//dupTransaction.Rollback()
@@ -975,11 +1509,19 @@ func (processor *Processor) PrepareCreateOperation(m *Meta, recordsValues []map[
 			}
 			return err
 		}
+		//the conflicting row is not returned if its version is changed since it was read
+		if len(dbObjs) < len(recordsValues) {
+			return errors2.NewConflictError(ErrCasConflict, fmt.Sprintf("Records of '%s' have been modified concurrently", m.Name), nil)
+		}
 		if _, err := dbTransaction.(*PgTransaction).Exec(fixSeqDML.String()); err != nil {
 			return err
 		}
 
 		for i := 0; i < len(recordsValues); i++ {
+			if i < len(inserted) {
+				inserted[i], _ = dbObjs[i][dml_info.UpsertInsertedColumn].(bool)
+			}
+			delete(dbObjs[i], dml_info.UpsertInsertedColumn)
 			updateNodes(recordsValues[i], dbObjs[i])
 		}
 		return nil
@@ -1009,9 +1551,25 @@ func (processor *Processor) GetAll(m *Meta, fields []*FieldDescription, filters
 	if fields == nil {
 		fields = m.TableFields()
 	}
-	filterKeys, filterValues := GetMapKeysStrValues(filters)
+	nullFilterKeys := make([]string, 0)
+	valueFilters := make(map[string]interface{})
+	for key, value := range filters {
+		if value == nil {
+			nullFilterKeys = append(nullFilterKeys, key)
+		} else {
+			valueFilters[key] = value
+		}
+	}
+	filterKeys, filterValues := GetMapKeysStrValues(valueFilters)
 
 	selectInfo := NewSelectInfo(m, fields, filterKeys)
+	//"=NULL" condition is never true, so nil values are matched with "IS NULL"
+	for _, key := range nullFilterKeys {
+		if selectInfo.Where != "" {
+			selectInfo.Where += " AND "
+		}
+		selectInfo.Where += dml_info.EscapeColumn(key) + " IS NULL"
+	}
 	var q bytes.Buffer
 	if err := selectInfo.sql(&q); err != nil {
 		return nil, errors2.NewFatalError(ErrTemplateFailed, err.Error(), nil)
@@ -1056,7 +1614,11 @@ func (processor *Processor) PerformRemove(recordNode *RecordRemovalNode, dbTrans
 		}
 		recordSetNotification = NewRecordSetNotification(&RecordSet{Meta: recordNode.Record.Meta, Records: []*Record{recordNode.Record}}, false, description.MethodUpdate)
 	default:
-		operation, err = processor.PrepareRemoveOperation(recordNode.Record)
+		if recordNode.SoftDelete {
+			operation, err = processor.PrepareSoftRemoveOperation(recordNode.Record, false)
+		} else {
+			operation, err = processor.PrepareRemoveOperation(recordNode.Record)
+		}
 		if err != nil {
 			return err
 		}
@@ -1108,31 +1670,173 @@ func (processor *Processor) PrepareRemoveOperation(record *Record) (transactions
 
 }
 
+//Marks the record as deleted or restores it. Deletion time is the time the transaction started at, so all
+//records removed along with the root one have the same mark and can be restored together
+func (processor *Processor) PrepareSoftRemoveOperation(record *Record, restore bool) (transactions.Operation, error) {
+	var query bytes.Buffer
+	value := "now()"
+	if restore {
+		value = "NULL"
+	}
+	updateInfo := &dml_info.UpdateInfo{
+		Table:   GetTableName(record.Meta.Name),
+		Values:  []string{dml_info.EscapeColumn(description.SoftDeleteFieldName) + "=" + value},
+		Filters: []string{dml_info.EscapeColumn(record.Meta.Key.Name) + "=" + dml_info.BindValues(1, 1)},
+	}
+	operation := func(dbTransaction transactions.DbTransaction) error {
+		stmt, err := dbTransaction.(*PgTransaction).Prepare(query.String())
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		if _, err = stmt.Exec(record.Pk()); err != nil {
+			return errors2.NewFatalError(ErrDMLFailed, err.Error(), nil)
+		}
+		return nil
+	}
+	if err := parsedTemplUpdate.Execute(&query, updateInfo); err != nil {
+		return nil, errors2.NewFatalError(ErrTemplateFailed, err.Error(), nil)
+	}
+	return operation, nil
+}
+
 func (processor *Processor) GetRql(dataNode *Node, rqlRoot *rqlParser.RqlRootNode, fields []*FieldDescription, dbTransaction transactions.DbTransaction) ([]map[string]interface{}, int, error) {
+	return processor.GetRqlPage(dataNode, rqlRoot, fields, nil, dbTransaction)
+}
+
+//Fetches the page of records following the cursor of the pagination and counts records according to its count mode,
+//the state of the next page is set to the pagination
+func (processor *Processor) GetRqlPage(dataNode *Node, rqlRoot *rqlParser.RqlRootNode, fields []*FieldDescription, pagination *Pagination, dbTransaction transactions.DbTransaction) ([]map[string]interface{}, int, error) {
 	tx := dbTransaction.Transaction()
 	tableAlias := string(dataNode.Meta.Name[0])
-	translator := NewSqlTranslator(rqlRoot)
+	translator := NewPaginatedSqlTranslator(rqlRoot, pagination)
 	sqlQuery, err := translator.query(tableAlias, dataNode)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	countMode := pagination.countMode()
+	limit := sqlQuery.Limit
+	pageSize, err := strconv.Atoi(sqlQuery.Limit)
+	checksMore := countMode == CountHasMore && err == nil
+	if checksMore {
+		//one more record is fetched to find out if there are records after the page
+		limit = strconv.Itoa(pageSize + 1)
+	}
+
+	where, binds := sqlQuery.pageWhere()
+	from := GetTableName(dataNode.Meta.Name) + " " + tableAlias
 	selectInfo := &SelectInfo{
-		From:   GetTableName(dataNode.Meta.Name) + " " + tableAlias,
+		From:   from,
 		Cols:   fieldsToCols(fields, tableAlias),
-		Where:  sqlQuery.Where,
+		Where:  where,
 		Order:  sqlQuery.Sort,
-		Limit:  sqlQuery.Limit,
+		Limit:  limit,
 		Offset: sqlQuery.Offset,
 	}
 
+	//records data
+	var queryString bytes.Buffer
+	if err := selectInfo.sql(&queryString); err != nil {
+		return nil, 0, errors2.NewFatalError(ErrTemplateFailed, err.Error(), nil)
+	}
+	statement, err := NewStmt(tx, queryString.String())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer statement.Close()
+
+	recordsData, err := statement.ParsedQuery(binds, fields)
+	if err != nil {
+		return nil, 0, err
+	}
+	if checksMore && len(recordsData) > pageSize {
+		recordsData = recordsData[:pageSize]
+		pagination.HasMore = true
+	}
+	//count data
+	count, err := countRecords(tx, from, sqlQuery, countMode)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pagination != nil && (!checksMore || pagination.HasMore) {
+		pagination.Next = sqlQuery.nextCursor(recordsData)
+	}
+	return recordsData, count, nil
+}
+
+//Counts records matching the query, the estimated count is taken from the query plan built on the table statistics
+func countRecords(tx *sql.Tx, from string, sqlQuery *SqlQuery, countMode CountMode) (int, error) {
+	if countMode == CountNone || countMode == CountHasMore {
+		return 0, nil
+	}
+
 	countInfo := &SelectInfo{
-		From:  GetTableName(dataNode.Meta.Name) + " " + tableAlias,
+		From:  from,
 		Cols:  []string{"count(*)"},
 		Where: sqlQuery.Where,
 	}
+	if countMode == CountEstimate {
+		countInfo.Cols = []string{"1"}
+	}
+	var queryString bytes.Buffer
+	if countMode == CountEstimate {
+		queryString.WriteString("EXPLAIN (FORMAT JSON) ")
+	}
+	if err := countInfo.sql(&queryString); err != nil {
+		return 0, errors2.NewFatalError(ErrTemplateFailed, err.Error(), nil)
+	}
+	countStatement, err := NewStmt(tx, queryString.String())
+	if err != nil {
+		return 0, err
+	}
+	defer countStatement.Close()
 
-	//records data
+	if countMode != CountEstimate {
+		count := 0
+		err = countStatement.Scalar(&count, sqlQuery.Binds)
+		return count, err
+	}
+	var plan string
+	if err := countStatement.Scalar(&plan, sqlQuery.Binds); err != nil {
+		return 0, err
+	}
+	var plans []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		}
+	}
+	if err := json.Unmarshal([]byte(plan), &plans); err != nil || len(plans) == 0 {
+		return 0, errors2.NewFatalError(ErrDMLFailed, fmt.Sprintf("Query plan can't be parsed: %s", plan), nil)
+	}
+	return int(plans[0].Plan.Rows), nil
+}
+
+func (processor *Processor) GetRqlAggregation(dataNode *Node, rqlRoot *rqlParser.RqlRootNode, aggregation *Aggregation, dbTransaction transactions.DbTransaction) ([]map[string]interface{}, int, error) {
+	tx := dbTransaction.Transaction()
+	tableAlias := string(dataNode.Meta.Name[0])
+	translator := NewSqlTranslator(rqlRoot)
+	sqlQuery, err := translator.aggregationQuery(tableAlias, dataNode, aggregation)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	from := GetTableName(dataNode.Meta.Name) + " " + tableAlias
+	if len(sqlQuery.Joins) > 0 {
+		from += " " + strings.Join(sqlQuery.Joins, " ")
+	}
+
+	selectInfo := &SelectInfo{
+		From:    from,
+		Cols:    sqlQuery.Cols,
+		Where:   sqlQuery.Where,
+		GroupBy: sqlQuery.GroupBy,
+		Order:   sqlQuery.Sort,
+		Limit:   sqlQuery.Limit,
+		Offset:  sqlQuery.Offset,
+	}
+
+	//groups data
 	var queryString bytes.Buffer
 	if err := selectInfo.sql(&queryString); err != nil {
 		return nil, 0, errors2.NewFatalError(ErrTemplateFailed, err.Error(), nil)
@@ -1142,22 +1846,26 @@ func (processor *Processor) GetRql(dataNode *Node, rqlRoot *rqlParser.RqlRootNod
 		return nil, 0, err
 	}
 	defer statement.Close()
-	//count data
-	count := 0
+
+	//count of groups
+	countInfo := &SelectInfo{Cols: sqlQuery.Cols, From: from, Where: sqlQuery.Where, GroupBy: sqlQuery.GroupBy}
 	queryString.Reset()
 	if err := countInfo.sql(&queryString); err != nil {
 		return nil, 0, errors2.NewFatalError(ErrTemplateFailed, err.Error(), nil)
 	}
-	countStatement, err := NewStmt(tx, queryString.String())
+	countStatement, err := NewStmt(tx, "SELECT count(*) FROM ("+queryString.String()+") aggregation")
 	if err != nil {
 		return nil, 0, err
 	}
 	defer countStatement.Close()
 
-	recordsData, err := statement.ParsedQuery(sqlQuery.Binds, fields)
-	err = countStatement.Scalar(&count, sqlQuery.Binds)
+	recordsData, err := statement.ParsedQuery(sqlQuery.Binds, sqlQuery.Fields)
 	if err != nil {
 		return nil, 0, err
 	}
-	return recordsData, count, err
+	count := 0
+	if err := countStatement.Scalar(&count, sqlQuery.Binds); err != nil {
+		return nil, 0, err
+	}
+	return recordsData, count, nil
 }
@@ -40,13 +40,20 @@ func (dm *DbMetaDescriptionSyncer) Get(name string) (*description.MetaDescriptio
 
 	for _, col := range ddl.Columns {
 		meta.Fields = append(meta.Fields, description.Field{
-			Name:     col.Name,
-			Type:     col.Typ,
-			Optional: col.Optional,
-			Unique:   col.Unique,
+			Name:       col.Name,
+			Type:       col.Typ,
+			Optional:   col.Optional,
+			Unique:     col.Unique,
+			Searchable: col.Searchable,
+			Precision:  col.Precision,
+			Scale:      col.Scale,
 		})
 	}
 
+	for _, constraint := range ddl.UniqueTogether {
+		meta.UniqueTogether = append(meta.UniqueTogether, constraint.Columns)
+	}
+
 	transaction.Commit()
 	return &meta, true, nil
 }
@@ -3,6 +3,8 @@ package description
 import (
 	"custodian/server/errors"
 	"custodian/server/noti"
+	"os"
+	"time"
 )
 
 type Action struct {
@@ -14,6 +16,18 @@ type Action struct {
 	Name            string                 `json:"name"`
 	id              int
 	Notifier        noti.Notifier `json:"-"`
+	//webhook settings, supported by REST actions
+	Headers  map[string]string `json:"headers,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Sign     bool              `json:"sign,omitempty"`
+	//the secret is kept in the environment variable, so that it is never stored or returned with the meta
+	SecretEnv string `json:"secretEnv,omitempty"`
+	//the action fires only for records matching the condition and having any of the changed fields modified
+	Condition     string   `json:"condition,omitempty"`
+	ChangedFields []string `json:"changedFields,omitempty"`
+	//hooks are called synchronously before the change, they can modify or reject it. Timeout is in seconds
+	Hook    bool `json:"hook,omitempty"`
+	Timeout int  `json:"timeout,omitempty"`
 }
 
 func InitAction(i int, a *Action) error {
@@ -29,6 +43,12 @@ func InitAction(i int, a *Action) error {
 		return err
 	}
 
+	if webhook, ok := n.(noti.WebhookNotifier); ok {
+		if err := webhook.Configure(a.WebhookOptions()); err != nil {
+			return err
+		}
+	}
+
 	a.Notifier = n
 	return nil
 }
@@ -46,7 +66,42 @@ func (a *Action) Clone() *Action {
 		ActiveIfNotRoot: a.ActiveIfNotRoot,
 		IncludeValues:   a.IncludeValues,
 		Name:            a.Name,
+		Headers:         a.Headers,
+		Template:        a.Template,
+		Sign:            a.Sign,
+		SecretEnv:       a.SecretEnv,
+		Condition:       a.Condition,
+		ChangedFields:   a.ChangedFields,
+		Hook:            a.Hook,
+		Timeout:         a.Timeout,
+	}
+}
+
+func (a *Action) HasWebhookOptions() bool {
+	return len(a.Headers) > 0 || a.Template != "" || a.Sign || a.SecretEnv != ""
+}
+
+func (a *Action) WebhookOptions() noti.WebhookOptions {
+	return noti.WebhookOptions{Headers: a.Headers, Template: a.Template, Sign: a.Sign, Secret: a.Secret()}
+}
+
+//Returns the value of the secret environment variable
+func (a *Action) Secret() string {
+	if a.SecretEnv == "" {
+		return ""
+	}
+	return os.Getenv(a.SecretEnv)
+}
+
+func (a *Action) HasTriggers() bool {
+	return a.Condition != "" || len(a.ChangedFields) > 0
+}
+
+func (a *Action) HookTimeout() time.Duration {
+	if a.Timeout > 0 {
+		return time.Duration(a.Timeout) * time.Second
 	}
+	return noti.HOOK_TIMEOUT_IN_SEC * time.Second
 }
 
 func (a *Action) SetId(id int) {
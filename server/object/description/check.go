@@ -0,0 +1,9 @@
+package description
+
+//Object-level validation rule, the record is valid if the RQL expression is true for it,
+//eg: "or(is_null(end,true),gt(end,0))"
+type Check struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Message    string `json:"message,omitempty"`
+}
@@ -3,8 +3,15 @@ package description
 import (
 	"fmt"
 	"github.com/getlantern/deepcopy"
+	"reflect"
 )
 
+//Name of the version field of CAS-enabled objects
+const CasFieldName = "cas"
+
+//Name of the field which marks records of soft-deletable objects as deleted
+const SoftDeleteFieldName = "deleted_at"
+
 //The shadow struct of the MetaDescription struct.
 type MetaDescription struct {
 	Name    string   `json:"name"`
@@ -12,6 +19,12 @@ type MetaDescription struct {
 	Fields  []Field  `json:"fields"`
 	Actions []Action `json:"actions"`
 	Cas     bool     `json:"cas"`
+	SoftDelete bool  `json:"softDelete"`
+	History bool     `json:"history"`
+	Indexes []Index  `json:"indexes,omitempty"`
+	UniqueTogether [][]string `json:"uniqueTogether,omitempty"`
+	Checks  []Check  `json:"checks,omitempty"`
+	Rollups []Rollup `json:"rollups,omitempty"`
 	Views 	map[string]string `json:"views"`
 	Comment string `json:"comment"`
 }
@@ -40,6 +53,43 @@ func (md *MetaDescription) FindAction(actionName string) *Action {
 	return nil
 }
 
+func (md *MetaDescription) FindIndex(indexName string) *Index {
+	for i, index := range md.Indexes {
+		if index.Name == indexName {
+			return &md.Indexes[i]
+		}
+	}
+	return nil
+}
+
+//Returns the index of the unique constraint declared for the same fields in the same order or -1
+func (md *MetaDescription) FindUniqueTogether(fields []string) int {
+	for i, uniqueFields := range md.UniqueTogether {
+		if reflect.DeepEqual(uniqueFields, fields) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (md *MetaDescription) FindCheck(checkName string) *Check {
+	for i, check := range md.Checks {
+		if check.Name == checkName {
+			return &md.Checks[i]
+		}
+	}
+	return nil
+}
+
+func (md *MetaDescription) FindRollup(rollupName string) *Rollup {
+	for i, rollup := range md.Rollups {
+		if rollup.Name == rollupName {
+			return &md.Rollups[i]
+		}
+	}
+	return nil
+}
+
 func (md *MetaDescription) ForExport() MetaDescription {
 	metaCopy := MetaDescription{}
 	deepcopy.Copy(&metaCopy, *md)
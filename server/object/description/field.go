@@ -3,6 +3,9 @@ package description
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"regexp"
 )
 
 //Types description
@@ -20,8 +23,16 @@ const (
 	FieldTypeTime
 	FieldTypeGeneric
 	FieldTypeEnum
+	FieldTypeInteger
+	FieldTypeDecimal
+	FieldTypeUUID
+	FieldTypeJSON
+	FieldTypeStringArray
+	FieldTypeNumberArray
 )
 
+var uuidRe = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
 type FieldMode int
 
 const (
@@ -53,6 +64,18 @@ func AsFieldType(s string) (FieldType, bool) {
 		return FieldTypeTime, true
 	case "enum":
 		return FieldTypeEnum, true
+	case "integer":
+		return FieldTypeInteger, true
+	case "decimal":
+		return FieldTypeDecimal, true
+	case "uuid":
+		return FieldTypeUUID, true
+	case "json":
+		return FieldTypeJSON, true
+	case "stringArray":
+		return FieldTypeStringArray, true
+	case "numberArray":
+		return FieldTypeNumberArray, true
 	default:
 		return 0, false
 	}
@@ -82,6 +105,18 @@ func (fieldType FieldType) String() (string, bool) {
 		return "generic", true
 	case FieldTypeEnum:
 		return "enum", true
+	case FieldTypeInteger:
+		return "integer", true
+	case FieldTypeDecimal:
+		return "decimal", true
+	case FieldTypeUUID:
+		return "uuid", true
+	case FieldTypeJSON:
+		return "json", true
+	case FieldTypeStringArray:
+		return "stringArray", true
+	case FieldTypeNumberArray:
+		return "numberArray", true
 	default:
 		return "", false
 	}
@@ -103,6 +138,18 @@ func (fieldType FieldType) DdlType() (string, error) {
 		return "time with time zone", nil
 	case FieldTypeEnum:
 		return "enum_type", nil
+	case FieldTypeInteger:
+		return "bigint", nil
+	case FieldTypeDecimal:
+		return "numeric", nil
+	case FieldTypeUUID:
+		return "uuid", nil
+	case FieldTypeJSON:
+		return "jsonb", nil
+	case FieldTypeStringArray:
+		return "text[]", nil
+	case FieldTypeNumberArray:
+		return "numeric[]", nil
 	default:
 		return "", errors.New("Unsupported column type: " + string(fieldType))
 	}
@@ -113,16 +160,41 @@ func (fieldType FieldType) AssertType(i interface{}) bool {
 	case FieldTypeString, FieldTypeDateTime, FieldTypeDate, FieldTypeTime, FieldTypeEnum:
 		_, ok := i.(string)
 		return ok
-	case FieldTypeNumber:
+	case FieldTypeNumber, FieldTypeDecimal:
 		_, floatOk := i.(float64)
 		_, intOk := i.(int)
 		return floatOk || intOk
+	case FieldTypeInteger:
+		f, floatOk := i.(float64)
+		_, intOk := i.(int)
+		return floatOk && f == math.Trunc(f) || intOk
+	case FieldTypeUUID:
+		s, ok := i.(string)
+		return ok && uuidRe.MatchString(s)
+	case FieldTypeJSON:
+		//any JSON document is accepted
+		return i != nil
 	case FieldTypeBool:
 		_, ok := i.(bool)
 		return ok
 	case FieldTypeArray:
 		_, ok := i.([]interface{})
 		return ok
+	case FieldTypeStringArray, FieldTypeNumberArray:
+		items, ok := i.([]interface{})
+		if !ok {
+			return false
+		}
+		itemType := FieldTypeString
+		if fieldType == FieldTypeNumberArray {
+			itemType = FieldTypeNumber
+		}
+		for _, item := range items {
+			if !itemType.AssertType(item) {
+				return false
+			}
+		}
+		return true
 	case FieldTypeObject:
 		_, ok := i.(map[string]interface{})
 		return ok
@@ -134,14 +206,19 @@ func (fieldType FieldType) AssertType(i interface{}) bool {
 	}
 }
 
+//Returns true for the types whose values are numbers
+func (fieldType FieldType) IsNumeric() bool {
+	return fieldType == FieldTypeNumber || fieldType == FieldTypeInteger || fieldType == FieldTypeDecimal
+}
+
 func (fieldType FieldType) TypeAsserter() func(interface{}) bool {
 	switch fieldType {
-	case FieldTypeString, FieldTypeDateTime, FieldTypeDate, FieldTypeTime:
+	case FieldTypeString, FieldTypeDateTime, FieldTypeDate, FieldTypeTime, FieldTypeUUID:
 		return func(i interface{}) bool {
 			_, ok := i.(string)
 			return ok
 		}
-	case FieldTypeNumber:
+	case FieldTypeNumber, FieldTypeInteger, FieldTypeDecimal:
 		return func(i interface{}) bool {
 			_, ok := i.(float64)
 			return ok
@@ -290,6 +367,16 @@ type Field struct {
 	RetrieveMode   bool         `json:"retrieveMode,omitempty"` //only for outer links, true if field should be used for data retrieving
 	LinkThrough    string       `json:"linkThrough,omitempty"`  //only for "objects" field
 	Enum           EnumChoices  `json:"choices,omitempty"`
+	Searchable     bool         `json:"searchable,omitempty"` //only for string fields, true if field should be used for full-text search
+	MinLength      *int         `json:"minLength,omitempty"`  //only for string fields
+	MaxLength      *int         `json:"maxLength,omitempty"`  //only for string fields
+	Pattern        string       `json:"pattern,omitempty"`    //only for string fields, regular expression the value must match
+	Min            *float64     `json:"min,omitempty"`        //only for number fields
+	Max            *float64     `json:"max,omitempty"`        //only for number fields
+	Formula        string       `json:"formula,omitempty"`    //only for simple fields, expression the value is computed from, eg: "qty * price"
+	Stored         bool         `json:"stored,omitempty"`     //only for computed fields, true if the value should be stored as a generated column
+	Precision      int          `json:"precision,omitempty"`  //only for decimal fields, total number of digits
+	Scale          int          `json:"scale,omitempty"`      //only for decimal fields, number of digits after the decimal point
 }
 
 func (f *Field) IsSimple() bool {
@@ -337,9 +424,48 @@ func (f *Field) Clone() *Field {
 		RetrieveMode:   f.RetrieveMode,
 		LinkThrough:    f.LinkThrough,
 		Enum:           f.Enum,
+		Searchable:     f.Searchable,
+		MinLength:      f.MinLength,
+		MaxLength:      f.MaxLength,
+		Pattern:        f.Pattern,
+		Min:            f.Min,
+		Max:            f.Max,
+		Formula:        f.Formula,
+		Stored:         f.Stored,
+		Precision:      f.Precision,
+		Scale:          f.Scale,
 	}
 }
 
+//Returns true if the value of the field is computed by its formula
+func (f *Field) IsComputed() bool {
+	return f.Formula != ""
+}
+
+//Returns true if the field is computed at read time, so it has no column in the table
+func (f *Field) IsVirtual() bool {
+	return f.IsComputed() && !f.Stored
+}
+
+//Returns the column type, decimal columns are declared with their precision if it is set
+func ColumnDdlType(fieldType FieldType, precision int, scale int) (string, error) {
+	if fieldType == FieldTypeDecimal && precision > 0 {
+		return fmt.Sprintf("numeric(%d,%d)", precision, scale), nil
+	}
+	return fieldType.DdlType()
+}
+
+func (f *Field) HasValidationRules() bool {
+	return f.MinLength != nil || f.MaxLength != nil || f.Pattern != "" || f.Min != nil || f.Max != nil
+}
+
+func (f *Field) ValidationRulesEqual(another *Field) bool {
+	intEqual := func(a, b *int) bool { return a == nil && b == nil || a != nil && b != nil && *a == *b }
+	floatEqual := func(a, b *float64) bool { return a == nil && b == nil || a != nil && b != nil && *a == *b }
+	return intEqual(f.MinLength, another.MinLength) && intEqual(f.MaxLength, another.MaxLength) &&
+		f.Pattern == another.Pattern && floatEqual(f.Min, another.Min) && floatEqual(f.Max, another.Max)
+}
+
 func (f *Field) OnDeleteStrategy() *OnDeleteStrategy {
 	if f.Type == FieldTypeObject || (f.Type == FieldTypeGeneric && f.LinkType == LinkTypeInner) {
 		onDeleteStrategy, err := GetOnDeleteStrategyByVerboseName(f.OnDelete)
@@ -0,0 +1,225 @@
+package description
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//Kinds of formula nodes
+const (
+	FormulaNodeField  = "field"
+	FormulaNodeNumber = "number"
+	FormulaNodeString = "string"
+	FormulaNodeNeg    = "neg"
+)
+
+//Parsed formula of the computed field.
+//Binary nodes have an operator ("+", "-", "*", "/" or "||") as the Kind and two Args,
+//field nodes refer to the field of the same record ("qty") or of the linked record ("customer.name")
+type FormulaNode struct {
+	Kind  string
+	Path  []string
+	Value interface{}
+	Args  []*FormulaNode
+}
+
+//Returns paths of all fields the formula refers to
+func (node *FormulaNode) FieldPaths() [][]string {
+	if node.Kind == FormulaNodeField {
+		return [][]string{node.Path}
+	}
+	paths := make([][]string, 0)
+	for _, arg := range node.Args {
+		paths = append(paths, arg.FieldPaths()...)
+	}
+	return paths
+}
+
+//Returns true if the formula refers to the fields of the same record only
+func (node *FormulaNode) IsLocal() bool {
+	for _, path := range node.FieldPaths() {
+		if len(path) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+type formulaToken struct {
+	kind  string
+	value string
+}
+
+//Parses the formula, eg: "qty * price", "(price - discount) * 1.2", "first_name || ' ' || customer.name"
+func ParseFormula(formula string) (*FormulaNode, error) {
+	tokens, err := tokenizeFormula(formula)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("formula is empty")
+	}
+	parser := &formulaParser{tokens: tokens}
+	node, err := parser.parseConcatenation()
+	if err != nil {
+		return nil, err
+	}
+	if parser.position < len(parser.tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", parser.tokens[parser.position].value)
+	}
+	return node, nil
+}
+
+func tokenizeFormula(formula string) ([]formulaToken, error) {
+	tokens := make([]formulaToken, 0)
+	runes := []rune(formula)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '|':
+			if i+1 >= len(runes) || runes[i+1] != '|' {
+				return nil, fmt.Errorf("unexpected '|' at %d", i)
+			}
+			tokens = append(tokens, formulaToken{"operator", "||"})
+			i += 2
+		case strings.ContainsRune("+-*/()", r):
+			tokens = append(tokens, formulaToken{"operator", string(r)})
+			i++
+		case r == '\'':
+			var value strings.Builder
+			i++
+			for ; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("string literal is not closed")
+				}
+				//quote is escaped by doubling it
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+					} else {
+						break
+					}
+				}
+				value.WriteRune(runes[i])
+			}
+			tokens = append(tokens, formulaToken{FormulaNodeString, value.String()})
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, formulaToken{FormulaNodeNumber, string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, formulaToken{FormulaNodeField, string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected '%c' at %d", r, i)
+		}
+	}
+	return tokens, nil
+}
+
+type formulaParser struct {
+	tokens   []formulaToken
+	position int
+}
+
+func (p *formulaParser) nextOperator(operators ...string) (string, bool) {
+	if p.position < len(p.tokens) && p.tokens[p.position].kind == "operator" {
+		for _, operator := range operators {
+			if p.tokens[p.position].value == operator {
+				p.position++
+				return operator, true
+			}
+		}
+	}
+	return "", false
+}
+
+//Concatenation has the lowest precedence, then addition and then multiplication
+func (p *formulaParser) parseConcatenation() (*FormulaNode, error) {
+	return p.parseBinary(p.parseAddition, "||")
+}
+
+func (p *formulaParser) parseAddition() (*FormulaNode, error) {
+	return p.parseBinary(p.parseMultiplication, "+", "-")
+}
+
+func (p *formulaParser) parseMultiplication() (*FormulaNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/")
+}
+
+func (p *formulaParser) parseBinary(parseOperand func() (*FormulaNode, error), operators ...string) (*FormulaNode, error) {
+	left, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator, ok := p.nextOperator(operators...)
+		if !ok {
+			return left, nil
+		}
+		right, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		left = &FormulaNode{Kind: operator, Args: []*FormulaNode{left, right}}
+	}
+}
+
+func (p *formulaParser) parseUnary() (*FormulaNode, error) {
+	if _, ok := p.nextOperator("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &FormulaNode{Kind: FormulaNodeNeg, Args: []*FormulaNode{operand}}, nil
+	}
+	return p.parseOperand()
+}
+
+func (p *formulaParser) parseOperand() (*FormulaNode, error) {
+	if p.position >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of formula")
+	}
+	if _, ok := p.nextOperator("("); ok {
+		node, err := p.parseConcatenation()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.nextOperator(")"); !ok {
+			return nil, fmt.Errorf("')' is expected")
+		}
+		return node, nil
+	}
+	token := p.tokens[p.position]
+	p.position++
+	switch token.kind {
+	case FormulaNodeNumber:
+		number, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("wrong number '%s'", token.value)
+		}
+		return &FormulaNode{Kind: FormulaNodeNumber, Value: number}, nil
+	case FormulaNodeString:
+		return &FormulaNode{Kind: FormulaNodeString, Value: token.value}, nil
+	case FormulaNodeField:
+		path := strings.Split(token.value, ".")
+		for _, part := range path {
+			if part == "" {
+				return nil, fmt.Errorf("wrong field path '%s'", token.value)
+			}
+		}
+		return &FormulaNode{Kind: FormulaNodeField, Path: path}, nil
+	default:
+		return nil, fmt.Errorf("unexpected '%s'", token.value)
+	}
+}
@@ -0,0 +1,47 @@
+package description
+
+//Index access methods
+const (
+	IndexMethodBtree = "btree"
+	IndexMethodGin   = "gin"
+	IndexMethodHash  = "hash"
+)
+
+//Index declared on the object's table.
+//Predicate is an RQL-style condition of the partial index, eg: "and(eq(active,true),is_null(deleted,true))"
+type Index struct {
+	Name      string   `json:"name"`
+	Fields    []string `json:"fields"`
+	Unique    bool     `json:"unique,omitempty"`
+	Method    string   `json:"method,omitempty"`
+	Predicate string   `json:"predicate,omitempty"`
+}
+
+func (i *Index) Clone() *Index {
+	fields := make([]string, len(i.Fields))
+	copy(fields, i.Fields)
+	return &Index{
+		Name:      i.Name,
+		Fields:    fields,
+		Unique:    i.Unique,
+		Method:    i.Method,
+		Predicate: i.Predicate,
+	}
+}
+
+//Returns the access method of the index, btree is used by default
+func (i *Index) GetMethod() string {
+	if i.Method == "" {
+		return IndexMethodBtree
+	}
+	return i.Method
+}
+
+func (i *Index) IsMethodSupported() bool {
+	switch i.GetMethod() {
+	case IndexMethodBtree, IndexMethodGin, IndexMethodHash:
+		return true
+	default:
+		return false
+	}
+}
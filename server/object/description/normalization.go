@@ -7,6 +7,8 @@ type NormalizationService struct {
 func (normalizationService *NormalizationService) Normalize(metaDescription *MetaDescription) *MetaDescription {
 	normalizationService.NormalizeInnerFields(&metaDescription.Fields)
 	normalizationService.NormalizeOuterFields(&metaDescription.Fields)
+	normalizationService.NormalizeCasField(metaDescription)
+	normalizationService.NormalizeSoftDeleteField(metaDescription)
 	return metaDescription
 }
 
@@ -31,3 +33,28 @@ func (normalizationService *NormalizationService) NormalizeOuterFields(fields *[
 		}
 	}
 }
+
+//add version field to CAS-enabled object if it is not declared explicitly
+func (normalizationService *NormalizationService) NormalizeCasField(metaDescription *MetaDescription) {
+	if !metaDescription.Cas || metaDescription.FindField(CasFieldName) != nil {
+		return
+	}
+	metaDescription.Fields = append(metaDescription.Fields, Field{
+		Name:     CasFieldName,
+		Type:     FieldTypeNumber,
+		Optional: true,
+		Def:      float64(1),
+	})
+}
+
+//add deletion mark field to soft-deletable object if it is not declared explicitly
+func (normalizationService *NormalizationService) NormalizeSoftDeleteField(metaDescription *MetaDescription) {
+	if !metaDescription.SoftDelete || metaDescription.FindField(SoftDeleteFieldName) != nil {
+		return
+	}
+	metaDescription.Fields = append(metaDescription.Fields, Field{
+		Name:     SoftDeleteFieldName,
+		Type:     FieldTypeDateTime,
+		Optional: true,
+	})
+}
@@ -0,0 +1,28 @@
+package description
+
+//Functions rollups are computed with
+const (
+	RollupFuncCount = "count"
+	RollupFuncSum   = "sum"
+	RollupFuncAvg   = "avg"
+	RollupFuncMin   = "min"
+	RollupFuncMax   = "max"
+)
+
+//Read-only value aggregated over the records linked with the outer link,
+//eg: {"name": "lines_total", "link": "lines", "func": "sum", "field": "total"}
+type Rollup struct {
+	Name  string `json:"name"`
+	Link  string `json:"link"`
+	Func  string `json:"func"`
+	Field string `json:"field,omitempty"` //field of the linked object, not required for count
+}
+
+func (r *Rollup) IsFuncSupported() bool {
+	switch r.Func {
+	case RollupFuncCount, RollupFuncSum, RollupFuncAvg, RollupFuncMin, RollupFuncMax:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,8 +1,12 @@
 package description
 
 import (
+	"custodian/server/noti"
 	"custodian/utils"
 	"fmt"
+	"regexp"
+
+	"github.com/Q-CIS-DEV/go-rql-parser"
 )
 
 type ValidationError struct {
@@ -20,6 +24,24 @@ func (validationService *MetaValidationService) Validate(metaDescription *MetaDe
 	if ok, err := validationService.checkFieldsDoesNotContainDuplicates(metaDescription.Fields); !ok {
 		return false, err
 	}
+	if ok, err := validationService.checkKey(metaDescription); !ok {
+		return false, err
+	}
+	if ok, err := validationService.checkFieldsValidationRules(metaDescription.Fields); !ok {
+		return false, err
+	}
+	if ok, err := validationService.checkChecks(metaDescription); !ok {
+		return false, err
+	}
+	if ok, err := validationService.checkComputedFields(metaDescription); !ok {
+		return false, err
+	}
+	if ok, err := validationService.checkRollups(metaDescription); !ok {
+		return false, err
+	}
+	if ok, err := validationService.checkActions(metaDescription); !ok {
+		return false, err
+	}
 	return true, nil
 }
 
@@ -35,3 +57,183 @@ func (validationService *MetaValidationService) checkFieldsDoesNotContainDuplica
 	}
 	return true, nil
 }
+
+//check if the key field has a type which can identify records: sequential numbers, UUIDs or natural string keys
+func (validationService *MetaValidationService) checkKey(metaDescription *MetaDescription) (bool, error) {
+	key := metaDescription.FindField(metaDescription.Key)
+	if key == nil {
+		return true, nil
+	}
+	switch key.Type {
+	case FieldTypeNumber, FieldTypeInteger, FieldTypeString, FieldTypeUUID:
+	default:
+		return false, &ValidationError{fmt.Sprintf("Key field '%s' must be number, integer, string or uuid one", key.Name)}
+	}
+	return true, nil
+}
+
+//check if validation rules of fields are consistent with their types
+func (validationService *MetaValidationService) checkFieldsValidationRules(fields []Field) (bool, error) {
+	for _, field := range fields {
+		if (field.MinLength != nil || field.MaxLength != nil || field.Pattern != "") && field.Type != FieldTypeString {
+			return false, &ValidationError{fmt.Sprintf("Length and pattern rules are supported by string fields only, field '%s'", field.Name)}
+		}
+		if (field.Min != nil || field.Max != nil) && !field.Type.IsNumeric() {
+			return false, &ValidationError{fmt.Sprintf("Min and max rules are supported by number fields only, field '%s'", field.Name)}
+		}
+		if field.MinLength != nil && field.MaxLength != nil && *field.MinLength > *field.MaxLength {
+			return false, &ValidationError{fmt.Sprintf("Field '%s' has minLength greater than maxLength", field.Name)}
+		}
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			return false, &ValidationError{fmt.Sprintf("Field '%s' has min greater than max", field.Name)}
+		}
+		if (field.Precision != 0 || field.Scale != 0) && field.Type != FieldTypeDecimal {
+			return false, &ValidationError{fmt.Sprintf("Precision and scale are supported by decimal fields only, field '%s'", field.Name)}
+		}
+		if field.Precision < 0 || field.Precision > 1000 || field.Scale < 0 || field.Scale > field.Precision {
+			return false, &ValidationError{fmt.Sprintf("Field '%s' has wrong precision or scale", field.Name)}
+		}
+		if field.Pattern != "" {
+			if _, err := regexp.Compile(field.Pattern); err != nil {
+				return false, &ValidationError{fmt.Sprintf("Field '%s' has wrong pattern: %s", field.Name, err.Error())}
+			}
+		}
+	}
+	return true, nil
+}
+
+//check if webhook settings are given to REST actions only and payload templates are parsable
+func (validationService *MetaValidationService) checkActions(metaDescription *MetaDescription) (bool, error) {
+	for _, action := range metaDescription.Actions {
+		if action.Condition != "" {
+			if rqlRoot, err := rqlParser.NewParser().Parse(action.Condition); err != nil || rqlRoot.Node == nil {
+				return false, &ValidationError{fmt.Sprintf("Action '%s' has wrong condition '%s'", action.Name, action.Condition)}
+			}
+		}
+		for _, fieldName := range action.ChangedFields {
+			if metaDescription.FindField(fieldName) == nil {
+				return false, &ValidationError{fmt.Sprintf("Action '%s' refers to nonexistent field '%s' in changed fields", action.Name, fieldName)}
+			}
+		}
+		if action.Hook {
+			if ok, err := validationService.checkHookProtocol(action); !ok {
+				return false, err
+			}
+			if action.Method != MethodCreate && action.Method != MethodUpdate && action.Method != MethodRemove {
+				return false, &ValidationError{fmt.Sprintf("Hook '%s' must be called on create, update or remove", action.Name)}
+			}
+		}
+		if action.Timeout < 0 || action.Timeout > 0 && !action.Hook {
+			return false, &ValidationError{fmt.Sprintf("Timeout of action '%s' must be positive and is supported by hooks only", action.Name)}
+		}
+		if !action.HasWebhookOptions() {
+			continue
+		}
+		if action.Protocol != noti.REST {
+			return false, &ValidationError{fmt.Sprintf("Headers, payload template and signing are supported by REST actions only, action '%s'", action.Name)}
+		}
+		if _, err := noti.ParsePayloadTemplate(action.Template); err != nil {
+			return false, &ValidationError{fmt.Sprintf("Action '%s' has wrong payload template: %s", action.Name, err.Error())}
+		}
+		if action.SecretEnv != "" && action.Secret() == "" {
+			return false, &ValidationError{fmt.Sprintf("Secret of action '%s' is not set, environment variable '%s' is empty", action.Name, action.SecretEnv)}
+		}
+	}
+	return true, nil
+}
+
+//check if notifiers of the hook action protocol can be called synchronously
+func (validationService *MetaValidationService) checkHookProtocol(action Action) (bool, error) {
+	factory, ok := noti.NotifierFactories[action.Protocol]
+	if !ok {
+		return false, &ValidationError{fmt.Sprintf("Action '%s' has unknown protocol", action.Name)}
+	}
+	notifier, err := factory(action.Args, action.ActiveIfNotRoot)
+	if err != nil {
+		return false, &ValidationError{fmt.Sprintf("Action '%s' has wrong arguments: %s", action.Name, err.Error())}
+	}
+	if _, ok := notifier.(noti.Hook); !ok {
+		protocol, _ := action.Protocol.String()
+		return false, &ValidationError{fmt.Sprintf("Hooks are not supported by %s actions, action '%s'", protocol, action.Name)}
+	}
+	return true, nil
+}
+
+//check if object-level checks have names and parsable expressions
+func (validationService *MetaValidationService) checkChecks(metaDescription *MetaDescription) (bool, error) {
+	checkNames := make([]string, 0)
+	for _, check := range metaDescription.Checks {
+		if check.Name == "" || utils.Contains(checkNames, check.Name) {
+			return false, &ValidationError{fmt.Sprintf("Object contains check with empty or duplicated name '%s'", check.Name)}
+		}
+		checkNames = append(checkNames, check.Name)
+		if rqlRoot, err := rqlParser.NewParser().Parse(check.Expression); err != nil || rqlRoot.Node == nil {
+			return false, &ValidationError{fmt.Sprintf("Check '%s' has wrong expression '%s'", check.Name, check.Expression)}
+		}
+	}
+	return true, nil
+}
+
+//check if formulas of computed fields are parsable and refer to the existing fields.
+//Paths to the linked objects are verified when the object is built, since linked objects are required for it
+func (validationService *MetaValidationService) checkComputedFields(metaDescription *MetaDescription) (bool, error) {
+	for _, field := range metaDescription.Fields {
+		if !field.IsComputed() {
+			if field.Stored {
+				return false, &ValidationError{fmt.Sprintf("Field '%s' can't be stored, since it has no formula", field.Name)}
+			}
+			continue
+		}
+		if !field.IsSimple() || field.LinkType != 0 {
+			return false, &ValidationError{fmt.Sprintf("Only simple fields can be computed, field '%s'", field.Name)}
+		}
+		if field.Name == metaDescription.Key || field.Def != nil || field.NowOnCreate || field.NowOnUpdate {
+			return false, &ValidationError{fmt.Sprintf("Computed field '%s' can't be a key or have a default value", field.Name)}
+		}
+		if field.Searchable {
+			return false, &ValidationError{fmt.Sprintf("Computed field '%s' can't be searchable", field.Name)}
+		}
+		formula, err := ParseFormula(field.Formula)
+		if err != nil {
+			return false, &ValidationError{fmt.Sprintf("Field '%s' has wrong formula '%s': %s", field.Name, field.Formula, err.Error())}
+		}
+		if field.Stored && !formula.IsLocal() {
+			return false, &ValidationError{fmt.Sprintf("Stored field '%s' can refer to the fields of the same object only", field.Name)}
+		}
+		for _, path := range formula.FieldPaths() {
+			referencedField := metaDescription.FindField(path[0])
+			if referencedField == nil {
+				return false, &ValidationError{fmt.Sprintf("Formula of field '%s' refers to unknown field '%s'", field.Name, path[0])}
+			}
+			if referencedField.IsComputed() {
+				return false, &ValidationError{fmt.Sprintf("Formula of field '%s' can't refer to computed field '%s'", field.Name, path[0])}
+			}
+			if len(path) == 1 && !referencedField.IsSimple() || len(path) > 1 && referencedField.Type != FieldTypeObject {
+				return false, &ValidationError{fmt.Sprintf("Formula of field '%s' refers to field '%s' which can't be computed with", field.Name, path[0])}
+			}
+		}
+	}
+	return true, nil
+}
+
+//check if rollups have unique names and are declared on outer links.
+//Fields of the linked objects are verified when the object is built
+func (validationService *MetaValidationService) checkRollups(metaDescription *MetaDescription) (bool, error) {
+	rollupNames := make([]string, 0)
+	for _, rollup := range metaDescription.Rollups {
+		if rollup.Name == "" || utils.Contains(rollupNames, rollup.Name) || metaDescription.FindField(rollup.Name) != nil {
+			return false, &ValidationError{fmt.Sprintf("Object contains rollup with empty or duplicated name '%s'", rollup.Name)}
+		}
+		rollupNames = append(rollupNames, rollup.Name)
+		if link := metaDescription.FindField(rollup.Link); link == nil || link.Type != FieldTypeArray || link.LinkType != LinkTypeOuter {
+			return false, &ValidationError{fmt.Sprintf("Rollup '%s' must refer to the outer link, got '%s'", rollup.Name, rollup.Link)}
+		}
+		if !rollup.IsFuncSupported() {
+			return false, &ValidationError{fmt.Sprintf("Rollup '%s' has unsupported function '%s'", rollup.Name, rollup.Func)}
+		}
+		if rollup.Field == "" && rollup.Func != RollupFuncCount {
+			return false, &ValidationError{fmt.Sprintf("Rollup '%s' requires the field to compute '%s' of", rollup.Name, rollup.Func)}
+		}
+	}
+	return true, nil
+}
@@ -2,13 +2,21 @@ package dml_info
 
 import (
 	"bytes"
+	"strings"
 )
 
+const UpsertInsertedColumn = "__inserted"
+
 type insertInfo struct {
 	Table      string
 	Cols       []string
 	RCols      []string
 	ObjectsLen int
+	//rows conflicting by these columns are updated instead of inserting
+	ConflictCols []string
+	UpdateCols   []string
+	//the conflicting row is updated only if its version matches the one being inserted, the version is increased
+	VersionCol string
 }
 
 func (insertInfo *insertInfo) GetValues() string {
@@ -22,6 +30,34 @@ func (insertInfo *insertInfo) GetValues() string {
 	return b.String()
 }
 
+//Updates the columns of the conflicting row with the values being inserted
+func (insertInfo *insertInfo) GetConflictUpdates() string {
+	updates := make([]string, len(insertInfo.UpdateCols))
+	for i, column := range insertInfo.UpdateCols {
+		if column == insertInfo.VersionCol {
+			updates[i] = column + " = " + insertInfo.Table + "." + column + " + 1"
+		} else {
+			updates[i] = column + " = EXCLUDED." + column
+		}
+	}
+	return strings.Join(updates, ", ")
+}
+
 func NewInsertInfo(table string, columns []string, returnColumns []string, objectsLength int) *insertInfo {
-	return &insertInfo{table, EscapeColumns(columns), EscapeColumns(returnColumns), objectsLength}
+	return &insertInfo{table, EscapeColumns(columns), EscapeColumns(returnColumns), objectsLength, nil, nil, ""}
+}
+
+//Upserted rows are returned along with the flag telling whether the row is inserted, the updated row has xmax set
+func NewUpsertInfo(table string, columns []string, returnColumns []string, objectsLength int, conflictColumns []string, updateColumns []string, versionColumn string) *insertInfo {
+	escapedReturnColumns := EscapeColumns(returnColumns)
+	if len(conflictColumns) > 0 {
+		escapedReturnColumns = append(escapedReturnColumns, "(xmax = 0) AS "+EscapeColumn(UpsertInsertedColumn))
+	}
+	escapedVersionColumn := ""
+	if versionColumn != "" {
+		escapedVersionColumn = EscapeColumn(versionColumn)
+	}
+	return &insertInfo{
+		table, EscapeColumns(columns), escapedReturnColumns, objectsLength, EscapeColumns(conflictColumns), EscapeColumns(updateColumns), escapedVersionColumn,
+	}
 }
@@ -7,12 +7,16 @@ import (
 
 // MetaDescription DDL errors
 const (
-	ErrUnsupportedLinkType   = "unsuported_link_type"
-	ErrNotFound              = "not_found"
-	ErrTooManyFound          = "too_many_found"
-	ErrInternal              = "internal"
-	ErrWrongDefultValue      = "wrong_default_value"
-	ErrExecutingDDL          = "error_exec_ddl"
+	ErrUnsupportedLinkType    = "unsuported_link_type"
+	ErrNotFound               = "not_found"
+	ErrTooManyFound           = "too_many_found"
+	ErrInternal               = "internal"
+	ErrWrongDefultValue       = "wrong_default_value"
+	ErrExecutingDDL           = "error_exec_ddl"
+	ErrUnsupportedSearchField = "unsupported_search_field"
+	ErrWrongIndex             = "wrong_index"
+	ErrWrongUniqueTogether    = "wrong_unique_together"
+	ErrWrongFormula           = "wrong_formula"
 )
 
 type DDLError struct {
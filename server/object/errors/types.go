@@ -7,4 +7,10 @@ const (
 	ErrRestrictConstraintViolation = "restrict_constraint_violation"
 	ErrWrongRQL                    = "wrong_rql"
 	ErrKeyValueNotFound            = "key_value_not_found"
+	ErrValidationRuleViolation     = "validation_rule_violation"
+	ErrComputedFieldReadOnly       = "computed_field_read_only"
+	ErrWrongCountMode              = "wrong_count_mode"
+	ErrWrongImportMode             = "wrong_import_mode"
+	ErrWrongImportRow              = "wrong_import_row"
+	ErrWrongBatchOperation         = "wrong_batch_operation"
 )
@@ -0,0 +1,99 @@
+package object
+
+import (
+	"bytes"
+	errors2 "custodian/server/errors"
+	"custodian/server/object/description"
+	"custodian/server/object/errors"
+	"fmt"
+
+	rqlParser "github.com/Q-CIS-DEV/go-rql-parser"
+)
+
+//Number of records fetched from the server-side cursor at once
+const exportBatchSize = 1000
+
+const exportCursorName = "custodian_export"
+
+//Exports records matching the filter. Records are fetched with the server-side cursor batch by batch,
+//each batch is passed to the consumer as soon as it is fetched, so that the whole set is never kept in memory
+func (processor *Processor) Export(objectName string, filter string, includePaths []string, excludePaths []string, depth int, omitOuters bool, consume func([]*Record) error) error {
+	businessObject, err := processor.GetMeta(objectName)
+	if err != nil {
+		return err
+	}
+
+	rqlNode, err := rqlParser.NewParser().Parse(filter)
+	if err != nil {
+		return errors2.NewValidationError(errors.ErrWrongRQL, err.Error(), nil)
+	}
+
+	transaction, err := processor.transactionManager.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	//nothing is changed by the export
+	defer transaction.Rollback()
+
+	searchContext := SearchContext{DepthLimit: depth, processor: processor, LazyPath: "/custodian/data/bulk", DbTransaction: transaction, OmitOuters: omitOuters}
+	root := &Node{
+		KeyField:       businessObject.Key,
+		Meta:           businessObject,
+		ChildNodes:     *NewChildNodes(),
+		Depth:          1,
+		OnlyLink:       false,
+		Plural:         false,
+		Parent:         nil,
+		Type:           NodeTypeRegular,
+		SelectFields:   *NewSelectFields(businessObject.Key, businessObject.TableFields()),
+		RetrievePolicy: new(AggregatedRetrievePolicyFactory).Factory(includePaths, excludePaths),
+	}
+	root.RecursivelyFillChildNodes(searchContext.DepthLimit, description.FieldModeRetrieve)
+
+	tableAlias := string(businessObject.Name[0])
+	sqlQuery, err := NewSqlTranslator(rqlNode).query(tableAlias, root)
+	if err != nil {
+		return err
+	}
+	fields := root.SelectFields.FieldList
+	selectInfo := &SelectInfo{
+		From:   GetTableName(businessObject.Name) + " " + tableAlias,
+		Cols:   fieldsToCols(fields, tableAlias),
+		Where:  sqlQuery.Where,
+		Order:  sqlQuery.Sort,
+		Limit:  sqlQuery.Limit,
+		Offset: sqlQuery.Offset,
+	}
+	var queryString bytes.Buffer
+	queryString.WriteString(fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR ", exportCursorName))
+	if err := selectInfo.sql(&queryString); err != nil {
+		return errors2.NewFatalError(ErrTemplateFailed, err.Error(), nil)
+	}
+
+	tx := transaction.Transaction()
+	if _, err := tx.Exec(queryString.String(), sqlQuery.Binds...); err != nil {
+		return errors2.NewFatalError(ErrDMLFailed, err.Error(), nil)
+	}
+	statement, err := NewStmt(tx, fmt.Sprintf("FETCH FORWARD %d FROM %s", exportBatchSize, exportCursorName))
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	for {
+		recordsData, err := statement.ParsedQuery(nil, fields)
+		if err != nil {
+			return err
+		}
+		if len(recordsData) == 0 {
+			return nil
+		}
+		records := make([]*Record, 0, len(recordsData))
+		for _, obj := range recordsData {
+			records = append(records, root.FillRecordValues(NewRecord(businessObject, obj, processor), searchContext))
+		}
+		if err := consume(records); err != nil {
+			return err
+		}
+	}
+}
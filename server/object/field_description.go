@@ -15,19 +15,27 @@ type FieldDescription struct {
 	OuterLinkField *FieldDescription
 	LinkMetaList   *MetaList
 	LinkThrough    *Meta
+	Rollup         *Rollup
+}
+
+//Returns false for the fields which are selected with SQL expressions, ie rollups and virtual computed fields
+func (f *FieldDescription) hasColumn() bool {
+	return f.Rollup == nil && !f.IsVirtual()
 }
 
 func (f *FieldDescription) IsValueTypeValid(v interface{}) bool {
 	switch f.Type {
-	case FieldTypeString, FieldTypeDateTime, FieldTypeDate, FieldTypeTime:
+	case FieldTypeString, FieldTypeDateTime, FieldTypeDate, FieldTypeTime, FieldTypeUUID:
 		_, ok := v.(string)
 		return ok
-	case FieldTypeNumber:
+	case FieldTypeNumber, FieldTypeInteger, FieldTypeDecimal:
 		_, ok := v.(float64)
 		return ok
 	case FieldTypeBool:
 		_, ok := v.(bool)
 		return ok
+	case FieldTypeJSON:
+		return true
 	default:
 		return false
 	}
@@ -36,24 +44,23 @@ func (f *FieldDescription) IsValueTypeValid(v interface{}) bool {
 
 func (field *FieldDescription) ValueFromString(v string) (interface{}, error) {
 	switch field.Type {
-	case FieldTypeString, FieldTypeDateTime, FieldTypeDate, FieldTypeTime, FieldTypeEnum:
+	case FieldTypeString, FieldTypeDateTime, FieldTypeDate, FieldTypeTime, FieldTypeEnum, FieldTypeUUID, FieldTypeStringArray:
 		return v, nil
-	case FieldTypeNumber:
+	case FieldTypeNumber, FieldTypeInteger, FieldTypeDecimal, FieldTypeNumberArray:
 		return strconv.ParseFloat(v, 64)
 	case FieldTypeBool:
 		return strconv.ParseBool(v)
-	case FieldTypeObject:
-		if field.LinkMeta.Key.Field.Type == FieldTypeString {
-			return v, nil
-		} else {
-			if f, err := strconv.ParseFloat(v, 64); err != nil {
-				return strconv.Atoi(v)
-			} else {
-				return f, nil
-			}
+	case FieldTypeJSON:
+		//value is compared with the JSON document by its type
+		if number, err := strconv.ParseFloat(v, 64); err == nil {
+			return JSONValue{Document: number}, nil
+		} else if boolean, err := strconv.ParseBool(v); err == nil {
+			return JSONValue{Document: boolean}, nil
 		}
-	case FieldTypeArray:
-		return strconv.Atoi(v)
+		return JSONValue{Document: v}, nil
+	case FieldTypeObject, FieldTypeArray:
+		//value is the key of the linked record
+		return field.LinkMeta.Key.ValueFromString(v)
 	case FieldTypeGeneric:
 		//	case of querying by object
 		return v, nil
@@ -68,7 +75,7 @@ func (field *FieldDescription) ValueFromString(v string) (interface{}, error) {
 
 func (f *FieldDescription) ValueAsString(v interface{}) (string, error) {
 	switch f.Type {
-	case FieldTypeString, FieldTypeDateTime, FieldTypeDate, FieldTypeTime:
+	case FieldTypeString, FieldTypeDateTime, FieldTypeDate, FieldTypeTime, FieldTypeUUID:
 		if str, ok := v.(string); !ok {
 			return "", errors.NewValidationError(
 				"conversion",
@@ -78,7 +85,7 @@ func (f *FieldDescription) ValueAsString(v interface{}) (string, error) {
 		} else {
 			return str, nil
 		}
-	case FieldTypeNumber:
+	case FieldTypeNumber, FieldTypeInteger, FieldTypeDecimal:
 		switch value := v.(type) {
 		case float64:
 			return strconv.FormatFloat(value, 'f', -1, 64), nil
@@ -0,0 +1,123 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/errors"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scalar field types", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	metaName := utils.RandomString(8)
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	havingObjectWithTypedFields := func() *object.Meta {
+		metaDescription := description.MetaDescription{
+			Name: metaName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeInteger,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{Name: "quantity", Type: description.FieldTypeInteger, Optional: true},
+				{Name: "price", Type: description.FieldTypeDecimal, Precision: 10, Scale: 2, Optional: true},
+				{Name: "token", Type: description.FieldTypeUUID, Optional: true},
+				{Name: "payload", Type: description.FieldTypeJSON, Optional: true},
+				{Name: "tags", Type: description.FieldTypeStringArray, Optional: true},
+				{Name: "sizes", Type: description.FieldTypeNumberArray, Optional: true},
+			},
+		}
+		meta, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(meta)
+		Expect(err).To(BeNil())
+		return meta
+	}
+
+	It("Stores and retrieves values of the new types", func() {
+		havingObjectWithTypedFields()
+
+		record, err := dataProcessor.CreateRecord(metaName, map[string]interface{}{
+			"quantity": 3.0,
+			"price":    10.25,
+			"token":    "0b3b8c6e-9a6d-4b57-8c6e-9a6d4b578c6e",
+			"payload":  map[string]interface{}{"status": "paid", "customer": map[string]interface{}{"age": 30.0}},
+			"tags":     []interface{}{"red", "big"},
+			"sizes":    []interface{}{1.0, 2.5},
+		}, auth.User{})
+		Expect(err).To(BeNil())
+
+		record, err = dataProcessor.Get(metaName, record.PkAsString(), nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(record.Data["quantity"]).To(Equal(3.0))
+		Expect(record.Data["price"]).To(Equal(10.25))
+		Expect(record.Data["token"]).To(Equal("0b3b8c6e-9a6d-4b57-8c6e-9a6d4b578c6e"))
+		Expect(record.Data["payload"]).To(Equal(map[string]interface{}{"status": "paid", "customer": map[string]interface{}{"age": 30.0}}))
+		Expect(record.Data["tags"]).To(Equal([]interface{}{"red", "big"}))
+		Expect(record.Data["sizes"]).To(Equal([]interface{}{1.0, 2.5}))
+	})
+
+	It("Rejects values of wrong types", func() {
+		havingObjectWithTypedFields()
+
+		_, err := dataProcessor.CreateRecord(metaName, map[string]interface{}{"quantity": 1.5}, auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Data.(map[string]string)["field"]).To(Equal("quantity"))
+
+		_, err = dataProcessor.CreateRecord(metaName, map[string]interface{}{"token": "not-a-uuid"}, auth.User{})
+		Expect(err).NotTo(BeNil())
+
+		_, err = dataProcessor.CreateRecord(metaName, map[string]interface{}{"tags": []interface{}{"red", 1.0}}, auth.User{})
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("Queries arrays and JSON documents with RQL", func() {
+		havingObjectWithTypedFields()
+
+		matching, err := dataProcessor.CreateRecord(metaName, map[string]interface{}{
+			"payload": map[string]interface{}{"status": "paid", "customer": map[string]interface{}{"age": 30.0}},
+			"tags":    []interface{}{"red", "big"},
+		}, auth.User{})
+		Expect(err).To(BeNil())
+		_, err = dataProcessor.CreateRecord(metaName, map[string]interface{}{
+			"payload": map[string]interface{}{"status": "new", "customer": map[string]interface{}{"age": 20.0}},
+			"tags":    []interface{}{"red"},
+		}, auth.User{})
+		Expect(err).To(BeNil())
+
+		for _, query := range []string{
+			"contains(tags,big)",
+			"contains(tags,(red,big))",
+			"eq(payload.status,paid)",
+			"gt(payload.customer.age,25)",
+		} {
+			_, records, err := dataProcessor.GetBulk(metaName, query, nil, nil, 1, false)
+			Expect(err).To(BeNil())
+			Expect(records).To(HaveLen(1))
+			Expect(records[0].Pk()).To(Equal(matching.Pk()))
+		}
+	})
+})
@@ -0,0 +1,46 @@
+package object
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+//JSONValue is a value of the JSON field, it is bound to the query as a JSON document
+type JSONValue struct {
+	Document interface{}
+}
+
+func (v JSONValue) String() string {
+	document, _ := json.Marshal(v.Document)
+	return string(document)
+}
+
+func (v JSONValue) Value() (driver.Value, error) {
+	return v.String(), nil
+}
+
+func (v JSONValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Document)
+}
+
+//ArrayValue is a value of the string or number array field, it is bound to the query as an array literal
+type ArrayValue []interface{}
+
+func (v ArrayValue) String() string {
+	items := make([]string, len(v))
+	for i, item := range v {
+		switch item := item.(type) {
+		case float64:
+			items[i] = strconv.FormatFloat(item, 'f', -1, 64)
+		case string:
+			items[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(item) + `"`
+		}
+	}
+	return "{" + strings.Join(items, ",") + "}"
+}
+
+func (v ArrayValue) Value() (driver.Value, error) {
+	return v.String(), nil
+}
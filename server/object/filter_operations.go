@@ -0,0 +1,150 @@
+package object
+
+import (
+	"bytes"
+	"custodian/server/auth"
+	errors2 "custodian/server/errors"
+	"custodian/server/object/description"
+	"custodian/server/object/errors"
+
+	rqlParser "github.com/Q-CIS-DEV/go-rql-parser"
+)
+
+//Applies the patch to all records matching the filter, each record is updated the same way as a single one.
+//Returns the number of updated records, nothing is updated if any of them fails
+func (processor *Processor) UpdateRecordsByFilter(objectName string, filter string, recordData map[string]interface{}, user auth.User) (int, error) {
+	objectMeta, err := processor.GetMeta(objectName)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := recordData[objectMeta.Key.Name]; ok {
+		return 0, errors2.NewValidationError(ErrInvalidArgument, "Key can't be updated by the filter", nil)
+	}
+
+	count := 0
+	err = processor.atomically(func() error {
+		keys, err := processor.getKeysByFilter(objectMeta, filter)
+		if err != nil {
+			return err
+		}
+		i := 0
+		next := func() (map[string]interface{}, error) {
+			if i == len(keys) {
+				return nil, nil
+			}
+			data := map[string]interface{}{objectMeta.Key.Name: keys[i]}
+			for name, value := range recordData {
+				data[name] = value
+			}
+			i++
+			return data, nil
+		}
+		return processor.BulkUpdateRecords(objectName, next, func(map[string]interface{}) error { count++; return nil }, user)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+//Removes all records matching the filter applying on-delete strategies of their links the same way as for a single record.
+//Returns the number of removed records not counting the ones removed by cascade, nothing is removed if any of them fails
+func (processor *Processor) DeleteRecordsByFilter(objectName string, filter string, user auth.User) (int, error) {
+	objectMeta, err := processor.GetMeta(objectName)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = processor.atomically(func() error {
+		keys, err := processor.getKeysByFilter(objectMeta, filter)
+		if err != nil {
+			return err
+		}
+		for _, keyValue := range keys {
+			key, err := objectMeta.Key.ValueAsString(keyValue)
+			if err != nil {
+				return err
+			}
+			//the record could be removed by cascade with one of the previous records
+			if record, err := processor.Get(objectName, key, nil, nil, 1, true); err != nil {
+				return err
+			} else if record == nil {
+				continue
+			}
+			if _, err := processor.RemoveRecord(objectName, key, user); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+//Returns keys of the records matching the filter
+func (processor *Processor) getKeysByFilter(objectMeta *Meta, filter string) ([]interface{}, error) {
+	rqlNode, err := rqlParser.NewParser().Parse(filter)
+	if err != nil {
+		return nil, errors2.NewValidationError(errors.ErrWrongRQL, err.Error(), nil)
+	}
+
+	root := &Node{
+		KeyField:       objectMeta.Key,
+		Meta:           objectMeta,
+		ChildNodes:     *NewChildNodes(),
+		Depth:          1,
+		OnlyLink:       false,
+		Plural:         false,
+		Parent:         nil,
+		Type:           NodeTypeRegular,
+		SelectFields:   *NewSelectFields(objectMeta.Key, []*FieldDescription{objectMeta.Key}),
+		RetrievePolicy: new(AggregatedRetrievePolicyFactory).Factory(nil, nil),
+	}
+	root.RecursivelyFillChildNodes(1, description.FieldModeRetrieve)
+
+	tableAlias := string(objectMeta.Name[0])
+	sqlQuery, err := NewSqlTranslator(rqlNode).query(tableAlias, root)
+	if err != nil {
+		return nil, err
+	}
+	fields := root.SelectFields.FieldList
+	selectInfo := &SelectInfo{
+		From:   GetTableName(objectMeta.Name) + " " + tableAlias,
+		Cols:   fieldsToCols(fields, tableAlias),
+		Where:  sqlQuery.Where,
+		Order:  sqlQuery.Sort,
+		Limit:  sqlQuery.Limit,
+		Offset: sqlQuery.Offset,
+	}
+	var queryString bytes.Buffer
+	if err := selectInfo.sql(&queryString); err != nil {
+		return nil, errors2.NewFatalError(ErrTemplateFailed, err.Error(), nil)
+	}
+
+	transaction, err := processor.transactionManager.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	statement, err := NewStmt(transaction.Transaction(), queryString.String())
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+	defer statement.Close()
+	recordsData, err := statement.ParsedQuery(sqlQuery.Binds, fields)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+	transaction.Commit()
+
+	keys := make([]interface{}, len(recordsData))
+	for i, recordData := range recordsData {
+		keys[i] = recordData[objectMeta.Key.Name]
+	}
+	return keys, nil
+}
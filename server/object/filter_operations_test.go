@@ -0,0 +1,90 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Operations by filter", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	customerName := utils.RandomString(8)
+	orderName := utils.RandomString(8)
+
+	BeforeEach(func() {
+		customerDescription := description.MetaDescription{
+			Name: customerName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+				{Name: "status", Type: description.FieldTypeString},
+			},
+		}
+		customerMeta, err := metaStore.NewMeta(&customerDescription)
+		Expect(err).To(BeNil())
+		Expect(metaStore.Create(customerMeta)).To(BeNil())
+
+		orderDescription := description.MetaDescription{
+			Name: orderName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+				{Name: "customer", Type: description.FieldTypeObject, LinkMeta: customerName, LinkType: description.LinkTypeInner, OnDelete: "cascade"},
+			},
+		}
+		orderMeta, err := metaStore.NewMeta(&orderDescription)
+		Expect(err).To(BeNil())
+		Expect(metaStore.Create(orderMeta)).To(BeNil())
+
+		for _, status := range []string{"new", "new", "active"} {
+			customer, err := dataProcessor.CreateRecord(customerName, map[string]interface{}{"status": status}, auth.User{})
+			Expect(err).To(BeNil())
+			_, err = dataProcessor.CreateRecord(orderName, map[string]interface{}{"customer": customer.Pk()}, auth.User{})
+			Expect(err).To(BeNil())
+		}
+	})
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	It("updates records matching the filter", func() {
+		count, err := dataProcessor.UpdateRecordsByFilter(customerName, "eq(status,new)", map[string]interface{}{"status": "active"}, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(2))
+
+		count, _, err = dataProcessor.GetBulk(customerName, "eq(status,active)", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(3))
+	})
+
+	It("removes records matching the filter along with records removed by cascade", func() {
+		count, err := dataProcessor.DeleteRecordsByFilter(customerName, "eq(status,new)", auth.User{})
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(2))
+
+		count, _, err = dataProcessor.GetBulk(orderName, "", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(1))
+	})
+
+	It("does not update the key by the filter", func() {
+		_, err := dataProcessor.UpdateRecordsByFilter(customerName, "eq(status,new)", map[string]interface{}{"id": 100}, auth.User{})
+		Expect(err).NotTo(BeNil())
+	})
+})
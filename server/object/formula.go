@@ -0,0 +1,266 @@
+package object
+
+import (
+	"custodian/server/errors"
+	"custodian/server/object/description"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Resolves fields along the path the formula refers to, the path may go through inner links only
+func resolveFormulaPath(meta *Meta, path []string) ([]*FieldDescription, error) {
+	fields := make([]*FieldDescription, 0, len(path))
+	currentMeta := meta
+	for i, part := range path {
+		field := currentMeta.FindField(part)
+		if field == nil {
+			return nil, fmt.Errorf("object '%s' doesn't have '%s' field", currentMeta.Name, part)
+		}
+		if i < len(path)-1 {
+			if field.Type != description.FieldTypeObject || field.LinkType != description.LinkTypeInner || field.LinkMeta == nil {
+				return nil, fmt.Errorf("field '%s' of object '%s' is not an inner link", part, currentMeta.Name)
+			}
+			currentMeta = field.LinkMeta
+		} else if !field.IsSimple() || field.IsComputed() {
+			return nil, fmt.Errorf("field '%s' of object '%s' can't be used in formulas", part, currentMeta.Name)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+//Checks if the paths of formulas refer to the existing fields of the linked objects
+func checkFormulas(objectMeta *Meta) error {
+	for i := range objectMeta.Fields {
+		field := &objectMeta.Fields[i]
+		if !field.IsComputed() {
+			continue
+		}
+		formula, err := description.ParseFormula(field.Formula)
+		if err == nil {
+			for _, path := range formula.FieldPaths() {
+				if _, err = resolveFormulaPath(objectMeta, path); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return errors.NewValidationError(
+				"new_meta",
+				fmt.Sprintf("Field '%s' has incorrect formula '%s': %s", field.Name, field.Formula, err.Error()),
+				nil,
+			)
+		}
+	}
+	return nil
+}
+
+//Translates the formula into SQL expression, paths of the fields are translated with the given function
+func formulaToSql(node *description.FormulaNode, pathToSql func(path []string) (string, error)) (string, error) {
+	switch node.Kind {
+	case description.FormulaNodeNumber:
+		return strconv.FormatFloat(node.Value.(float64), 'f', -1, 64), nil
+	case description.FormulaNodeString:
+		return "'" + strings.Replace(node.Value.(string), "'", "''", -1) + "'", nil
+	case description.FormulaNodeField:
+		return pathToSql(node.Path)
+	case description.FormulaNodeNeg:
+		operand, err := formulaToSql(node.Args[0], pathToSql)
+		if err != nil {
+			return "", err
+		}
+		return "(-" + operand + ")", nil
+	}
+	left, err := formulaToSql(node.Args[0], pathToSql)
+	if err != nil {
+		return "", err
+	}
+	right, err := formulaToSql(node.Args[1], pathToSql)
+	if err != nil {
+		return "", err
+	}
+	switch node.Kind {
+	case "/":
+		//division by zero results in NULL instead of the error
+		return fmt.Sprintf("(%s / NULLIF(%s, 0))", left, right), nil
+	case "||":
+		return fmt.Sprintf("(%s::text || %s::text)", left, right), nil
+	default:
+		return fmt.Sprintf("(%s %s %s)", left, node.Kind, right), nil
+	}
+}
+
+//Translates the path into the column prefixed with the alias, fields of the linked objects are selected with subqueries
+func formulaPathToSql(path []string, meta *Meta, alias string) (string, error) {
+	field := meta.FindField(path[0])
+	if field == nil {
+		return "", fmt.Errorf("object '%s' doesn't have '%s' field", meta.Name, path[0])
+	}
+	column := fmt.Sprintf("%s.\"%s\"", alias, field.Name)
+	if len(path) == 1 {
+		return column, nil
+	}
+	if field.LinkMeta == nil {
+		return "", fmt.Errorf("field '%s' of object '%s' is not an inner link", field.Name, meta.Name)
+	}
+	linkedAlias := alias + "_" + field.Name
+	value, err := formulaPathToSql(path[1:], field.LinkMeta, linkedAlias)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(SELECT %s FROM %s %s WHERE %s.\"%s\" = %s)", value, GetTableName(field.LinkMeta.Name), linkedAlias, linkedAlias, field.LinkMeta.Key.Name, column), nil
+}
+
+//Returns SQL expression of the field's value, which is either its column, the formula or the rollup
+func fieldValueSql(field *FieldDescription, alias string) (string, error) {
+	if field.Rollup != nil {
+		return rollupSql(field, alias)
+	}
+	if !field.IsVirtual() {
+		return fmt.Sprintf("%s.\"%s\"", alias, field.Name), nil
+	}
+	formula, err := description.ParseFormula(field.Formula)
+	if err != nil {
+		return "", err
+	}
+	return formulaToSql(formula, func(path []string) (string, error) {
+		return formulaPathToSql(path, field.Meta, alias)
+	})
+}
+
+//Returns SQL expression of the generated column, it can refer to the columns of the same table only
+func generatedColumnSql(field *description.Field) (string, error) {
+	formula, err := description.ParseFormula(field.Formula)
+	if err != nil {
+		return "", err
+	}
+	return formulaToSql(formula, func(path []string) (string, error) {
+		if len(path) > 1 {
+			return "", fmt.Errorf("generated column can't refer to the linked object's field '%s'", strings.Join(path, "."))
+		}
+		return fmt.Sprintf("\"%s\"", path[0]), nil
+	})
+}
+
+//Fetches data of the linked record by its primary key
+type linkedDataFetcher func(meta *Meta, pk interface{}) (map[string]interface{}, error)
+
+//Evaluates the formula against the record data. Any operand being NULL makes the result NULL as it is in SQL
+func evaluateFormula(node *description.FormulaNode, meta *Meta, data map[string]interface{}, fetch linkedDataFetcher) (interface{}, error) {
+	switch node.Kind {
+	case description.FormulaNodeNumber, description.FormulaNodeString:
+		return node.Value, nil
+	case description.FormulaNodeField:
+		return evaluateFormulaPath(node.Path, meta, data, fetch)
+	case description.FormulaNodeNeg:
+		operand, err := evaluateFormula(node.Args[0], meta, data, fetch)
+		if err != nil || operand == nil {
+			return nil, err
+		}
+		if number, ok := checkNumberValue(operand); ok {
+			return -number, nil
+		}
+		return nil, fmt.Errorf("'%v' is not a number", operand)
+	}
+	left, err := evaluateFormula(node.Args[0], meta, data, fetch)
+	if err != nil {
+		return nil, err
+	}
+	right, err := evaluateFormula(node.Args[1], meta, data, fetch)
+	if err != nil || left == nil || right == nil {
+		return nil, err
+	}
+	if node.Kind == "||" {
+		return formulaValueToString(left) + formulaValueToString(right), nil
+	}
+	leftNumber, leftOk := checkNumberValue(left)
+	rightNumber, rightOk := checkNumberValue(right)
+	if !leftOk || !rightOk {
+		return nil, fmt.Errorf("'%v' %s '%v' can't be computed", left, node.Kind, right)
+	}
+	switch node.Kind {
+	case "+":
+		return leftNumber + rightNumber, nil
+	case "-":
+		return leftNumber - rightNumber, nil
+	case "*":
+		return leftNumber * rightNumber, nil
+	default:
+		if rightNumber == 0 {
+			return nil, nil
+		}
+		return leftNumber / rightNumber, nil
+	}
+}
+
+func evaluateFormulaPath(path []string, meta *Meta, data map[string]interface{}, fetch linkedDataFetcher) (interface{}, error) {
+	value := data[path[0]]
+	if len(path) == 1 || value == nil {
+		return value, nil
+	}
+	field := meta.FindField(path[0])
+	if field == nil || field.LinkMeta == nil {
+		return nil, fmt.Errorf("field '%s' of object '%s' is not an inner link", path[0], meta.Name)
+	}
+	var linkedData map[string]interface{}
+	switch value := value.(type) {
+	case *Record:
+		linkedData = value.Data
+	case map[string]interface{}:
+		linkedData = value
+	default:
+		var err error
+		if linkedData, err = fetch(field.LinkMeta, value); err != nil || linkedData == nil {
+			return nil, err
+		}
+	}
+	return evaluateFormulaPath(path[1:], field.LinkMeta, linkedData, fetch)
+}
+
+func formulaValueToString(value interface{}) string {
+	if number, ok := checkNumberValue(value); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+//Computes values of the virtual fields selected by the node, absent values the formulas refer to are taken from the DB
+func (node *Node) fillComputedValues(record *Record, sc SearchContext) {
+	var storedData map[string]interface{}
+	fetch := func(meta *Meta, pk interface{}) (map[string]interface{}, error) {
+		return sc.processor.GetSystem(meta, meta.TableFields(), meta.Key.Name, pk, sc.DbTransaction)
+	}
+	for _, selectedField := range node.SelectFields.FieldList {
+		field := record.Meta.FindField(selectedField.Name)
+		if field == nil || !field.IsVirtual() {
+			continue
+		}
+		if _, ok := record.Data[field.Name]; ok {
+			continue
+		}
+		formula, err := description.ParseFormula(field.Formula)
+		if err != nil {
+			continue
+		}
+		data := record.Data
+		for _, path := range formula.FieldPaths() {
+			if _, ok := record.Data[path[0]]; !ok {
+				if storedData == nil {
+					if storedData, err = fetch(record.Meta, record.Pk()); err != nil || storedData == nil {
+						storedData = map[string]interface{}{}
+					}
+				}
+				data = storedData
+				break
+			}
+		}
+		value, err := evaluateFormula(formula, record.Meta, data, fetch)
+		if err != nil {
+			value = nil
+		} else if number, ok := checkNumberValue(value); ok && field.Type == description.FieldTypeString {
+			value = strconv.FormatFloat(number, 'f', -1, 64)
+		}
+		record.Data[field.Name] = value
+	}
+}
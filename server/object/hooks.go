@@ -0,0 +1,114 @@
+package object
+
+import (
+	"custodian/server/auth"
+	errors2 "custodian/server/errors"
+	"custodian/server/noti"
+	"custodian/server/object/description"
+	"custodian/utils"
+	"fmt"
+)
+
+//Call hooks of the object before the change of the record, they are called in turn with the proposed state of the record.
+//A hook responds with the values replacing the proposed ones in the record data, or rejects the change with
+//the client error status, in which case the "msg" of its response is used as the message of the validation error.
+//Any other failure, including the timeout, aborts the change as well. Removal can only be rejected
+func (processor *Processor) callHooks(objectMeta *Meta, method description.Method, previous map[string]interface{}, recordData map[string]interface{}, user auth.User) error {
+	if previous == nil {
+		previous = map[string]interface{}{}
+	}
+	proposed := utils.CloneMap(previous)
+	for key, value := range recordData {
+		proposed[key] = value
+	}
+
+	for _, hook := range objectMeta.Hooks {
+		if hook.Method != method || !actionTriggered(hook, objectMeta, method, previous, proposed) {
+			continue
+		}
+		caller, ok := hook.Notifier.(noti.Hook)
+		if !ok {
+			return errors2.NewFatalError(ErrHookFailed, fmt.Sprintf("Action '%s' of '%s' can't be called as a hook", hook.Name, objectMeta.Name), nil)
+		}
+
+		current := map[string]interface{}{}
+		if method != description.MethodRemove {
+			current = adaptRecordData(proposed)
+		}
+		event := noti.NewObjectEvent(map[string]interface{}{
+			"action":   method.AsString(),
+			"object":   objectMeta.Name,
+			"previous": adaptRecordData(previous),
+			"current":  current,
+			"user":     user,
+		}, true)
+		response, err := caller.Call(event, hook.HookTimeout())
+		if err != nil {
+			return errors2.NewFatalError(ErrHookFailed, fmt.Sprintf("Hook '%s' of '%s' failed: %s", hook.Name, objectMeta.Name, err.Error()), nil)
+		}
+		if response.Rejected() {
+			msg, ok := response.Data["msg"].(string)
+			if !ok {
+				msg = fmt.Sprintf("Hook '%s' of '%s' rejected the change", hook.Name, objectMeta.Name)
+			}
+			return errors2.NewValidationError(ErrHookRejected, msg, response.Data)
+		}
+		if !response.Succeeded() {
+			return errors2.NewFatalError(ErrHookFailed, fmt.Sprintf("Hook '%s' of '%s' responded with status %d", hook.Name, objectMeta.Name, response.Status), nil)
+		}
+
+		if method == description.MethodRemove {
+			continue
+		}
+		//the key identifies the record, so it is kept as is
+		for key, value := range response.Data {
+			if key == objectMeta.Key.Name || objectMeta.FindField(key) == nil {
+				continue
+			}
+			recordData[key] = value
+			proposed[key] = value
+		}
+	}
+	return nil
+}
+
+//Call hooks of the record being created. The upserted record is updated if the record having the same values
+//of the upsert fields exists, so update hooks are called with the existing record as the previous state in this case
+func (processor *Processor) callCreateHooks(objectMeta *Meta, upsert *upsertSettings, recordData map[string]interface{}, user auth.User) error {
+	if len(objectMeta.Hooks) == 0 {
+		return nil
+	}
+	if upsert == nil {
+		return processor.callHooks(objectMeta, description.MethodCreate, nil, recordData, user)
+	}
+	previous, err := processor.findUpsertTarget(objectMeta, upsert.fields, recordData)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return processor.callHooks(objectMeta, description.MethodCreate, nil, recordData, user)
+	}
+	return processor.callHooks(objectMeta, description.MethodUpdate, previous.GetData(), recordData, user)
+}
+
+//Call hooks of the record being removed and of records depending on it. Removal is cascaded to dependent records
+//or their links to the record are set to null, such update can only be rejected by hooks as the record is removed anyway
+func (processor *Processor) callRemovalHooks(removalNode *RecordRemovalNode, user auth.User) error {
+	record := removalNode.Record
+	if removalNode.OnDeleteStrategy != nil && *removalNode.OnDeleteStrategy == description.OnDeleteSetNull {
+		nulledData := map[string]interface{}{record.Meta.Key.Name: record.Pk(), removalNode.LinkField.Name: nil}
+		if err := processor.callHooks(record.Meta, description.MethodUpdate, record.GetData(), nulledData, user); err != nil {
+			return err
+		}
+	} else if err := processor.callHooks(record.Meta, description.MethodRemove, record.GetData(), nil, user); err != nil {
+		return err
+	}
+	for _, childNodes := range removalNode.Children {
+		for _, childNode := range childNodes {
+			if err := processor.callRemovalHooks(childNode, user); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
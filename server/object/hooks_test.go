@@ -0,0 +1,264 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/errors"
+	"custodian/server/noti"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hooks", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	var received map[string]interface{}
+	var status int
+	var response string
+	hookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+
+	BeforeEach(func() {
+		received = nil
+		status, response = http.StatusOK, ""
+	})
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	havingObjectWithHook := func(method description.Method) *object.Meta {
+		metaDescription := description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name:     "name",
+					Type:     description.FieldTypeString,
+					Optional: true,
+				},
+			},
+			Actions: []description.Action{
+				{
+					Method:   method,
+					Protocol: noti.REST,
+					Args:     []string{hookServer.URL},
+					Name:     "check",
+					Hook:     true,
+				},
+			},
+		}
+		metaObj, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(metaObj)
+		Expect(err).To(BeNil())
+		return metaObj
+	}
+
+	It("rejects hooks of protocols which can't be called synchronously", func() {
+		metaDescription := description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+			},
+			Actions: []description.Action{
+				{
+					Method:   description.MethodCreate,
+					Protocol: noti.AMQP,
+					Args:     []string{"amqp://localhost:5672", "events"},
+					Name:     "check",
+					Hook:     true,
+				},
+			},
+		}
+		_, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("creates the record with the data modified by the hook", func() {
+		metaObj := havingObjectWithHook(description.MethodCreate)
+		response = `{"name": "modified"}`
+
+		record, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"name": "proposed"}, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(received["current"].(map[string]interface{})["name"]).To(Equal("proposed"))
+
+		record, err = dataProcessor.Get(metaObj.Name, record.PkAsString(), nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(record.Data["name"]).To(Equal("modified"))
+	})
+
+	It("does not update the record rejected by the hook", func() {
+		metaObj := havingObjectWithHook(description.MethodUpdate)
+		record, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		status, response = http.StatusBadRequest, `{"msg": "Name can't be changed"}`
+		_, err = dataProcessor.UpdateRecord(metaObj.Name, record.PkAsString(), map[string]interface{}{"name": "second"}, auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(object.ErrHookRejected))
+		Expect(err.(*errors.ServerError).Msg).To(Equal("Name can't be changed"))
+		Expect(received["previous"].(map[string]interface{})["name"]).To(Equal("first"))
+		Expect(received["current"].(map[string]interface{})["name"]).To(Equal("second"))
+
+		record, err = dataProcessor.Get(metaObj.Name, record.PkAsString(), nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(record.Data["name"]).To(Equal("first"))
+	})
+
+	It("does not remove the record if the hook fails", func() {
+		metaObj := havingObjectWithHook(description.MethodRemove)
+		record, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		status = http.StatusInternalServerError
+		_, err = dataProcessor.RemoveRecord(metaObj.Name, record.PkAsString(), auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(object.ErrHookFailed))
+
+		record, err = dataProcessor.Get(metaObj.Name, record.PkAsString(), nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(record).NotTo(BeNil())
+	})
+
+	It("does not remove the record if the hook of the record removed by cascade fails", func() {
+		parentMeta, err := metaStore.NewMeta(&description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+			},
+		})
+		Expect(err).To(BeNil())
+		err = metaStore.Create(parentMeta)
+		Expect(err).To(BeNil())
+
+		childMeta, err := metaStore.NewMeta(&description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+				{Name: "name", Type: description.FieldTypeString, Optional: true},
+				{Name: "parent", Type: description.FieldTypeObject, LinkMeta: parentMeta.Name, LinkType: description.LinkTypeInner, OnDelete: "cascade", Optional: true},
+			},
+			Actions: []description.Action{
+				{Method: description.MethodRemove, Protocol: noti.REST, Args: []string{hookServer.URL}, Name: "check", Hook: true},
+			},
+		})
+		Expect(err).To(BeNil())
+		err = metaStore.Create(childMeta)
+		Expect(err).To(BeNil())
+
+		parent, err := dataProcessor.CreateRecord(parentMeta.Name, map[string]interface{}{}, auth.User{})
+		Expect(err).To(BeNil())
+		_, err = dataProcessor.CreateRecord(childMeta.Name, map[string]interface{}{"name": "child", "parent": parent.Pk()}, auth.User{})
+		Expect(err).To(BeNil())
+
+		status = http.StatusInternalServerError
+		_, err = dataProcessor.RemoveRecord(parentMeta.Name, parent.PkAsString(), auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(object.ErrHookFailed))
+		Expect(received["previous"].(map[string]interface{})["name"]).To(Equal("child"))
+
+		count, _, err := dataProcessor.GetBulk(childMeta.Name, "", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(1))
+	})
+
+	It("creates records of the bulk with the data modified by the hook", func() {
+		metaObj := havingObjectWithHook(description.MethodCreate)
+		response = `{"name": "modified"}`
+
+		records, err := dataProcessor.BulkCreateRecords(metaObj.Name, []map[string]interface{}{{"name": "first"}, {"name": "second"}}, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(received["current"].(map[string]interface{})["name"]).To(Equal("second"))
+
+		_, records, err = dataProcessor.GetBulk(metaObj.Name, "", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Data["name"]).To(Equal("modified"))
+		Expect(records[1].Data["name"]).To(Equal("modified"))
+	})
+
+	It("does not update records matching the filter if the hook rejects any of them", func() {
+		metaObj := havingObjectWithHook(description.MethodUpdate)
+		_, err := dataProcessor.BulkCreateRecords(metaObj.Name, []map[string]interface{}{{"name": "first"}, {"name": "second"}}, auth.User{})
+		Expect(err).To(BeNil())
+
+		status, response = http.StatusBadRequest, `{"msg": "Name can't be changed"}`
+		_, err = dataProcessor.UpdateRecordsByFilter(metaObj.Name, "", map[string]interface{}{"name": "third"}, auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(object.ErrHookRejected))
+		Expect(received["current"].(map[string]interface{})["name"]).To(Equal("third"))
+
+		count, _, err := dataProcessor.GetBulk(metaObj.Name, "eq(name,third)", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(0))
+	})
+
+	It("does not remove records of the bulk if the hook fails", func() {
+		metaObj := havingObjectWithHook(description.MethodRemove)
+		records, err := dataProcessor.BulkCreateRecords(metaObj.Name, []map[string]interface{}{{"name": "first"}, {"name": "second"}}, auth.User{})
+		Expect(err).To(BeNil())
+
+		status = http.StatusInternalServerError
+		i := 0
+		next := func() (map[string]interface{}, error) {
+			if i == len(records) {
+				return nil, nil
+			}
+			i++
+			return map[string]interface{}{"id": records[i-1].Data["id"]}, nil
+		}
+		err = dataProcessor.BulkDeleteRecords(metaObj.Name, next, auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(object.ErrHookFailed))
+
+		count, _, err := dataProcessor.GetBulk(metaObj.Name, "", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(2))
+	})
+
+	It("calls update hooks for the existing record matched by the upsert", func() {
+		metaObj := havingObjectWithHook(description.MethodUpdate)
+		record, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		response = `{"name": "modified"}`
+		record, err = dataProcessor.UpsertRecord(metaObj.Name, map[string]interface{}{"id": record.Data["id"], "name": "second"}, nil, nil, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(received["previous"].(map[string]interface{})["name"]).To(Equal("first"))
+		Expect(received["current"].(map[string]interface{})["name"]).To(Equal("second"))
+		Expect(record.Data["name"]).To(Equal("modified"))
+	})
+})
@@ -0,0 +1,205 @@
+package object
+
+import (
+	"bufio"
+	"bytes"
+	"custodian/server/auth"
+	errors2 "custodian/server/errors"
+	"custodian/server/object/description"
+	"custodian/server/object/errors"
+	"encoding/csv"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+)
+
+//Import modes: all rows are imported or none of them, or valid rows are imported regardless of invalid ones
+type ImportMode string
+
+const (
+	ImportModeAtomic     ImportMode = "atomic"
+	ImportModeBestEffort ImportMode = "best-effort"
+)
+
+func ParseImportMode(mode string) (ImportMode, error) {
+	switch ImportMode(mode) {
+	case "":
+		return ImportModeAtomic, nil
+	case ImportModeAtomic, ImportModeBestEffort:
+		return ImportMode(mode), nil
+	}
+	return "", errors2.NewValidationError(
+		errors.ErrWrongImportMode, fmt.Sprintf("Import mode '%s' is unknown, expected: atomic or best-effort", mode), nil,
+	)
+}
+
+//Row which is not imported, rows are numbered from 1 not counting the CSV header
+type ImportFailure struct {
+	Row   int         `json:"row"`
+	Error interface{} `json:"error"`
+}
+
+type ImportReport struct {
+	Mode     ImportMode       `json:"mode"`
+	Total    int              `json:"total"`
+	Imported int              `json:"imported"`
+	Errors   []*ImportFailure `json:"errors"`
+}
+
+//Error of the row which is reported without stopping the import
+type ImportRowError struct {
+	Err error
+}
+
+func (e *ImportRowError) Error() string {
+	return e.Err.Error()
+}
+
+const importSavepoint = "custodian_import_row"
+
+//Atomic import having failed rows is rolled back, the failures are reported instead of the error
+var errImportRolledBack = goerrors.New("import is rolled back")
+
+//Creates records of rows returned by next until it returns nil. Each row is created with the same validation as a single record,
+//errors of rows are collected into the report. Rows are imported within one transaction, each of them is isolated with a savepoint,
+//so that in the atomic mode nothing is imported if any row fails and in the best-effort mode only the failed rows are skipped
+func (processor *Processor) ImportRecords(objectName string, next func() (map[string]interface{}, error), mode ImportMode, user auth.User) (*ImportReport, error) {
+	if _, err := processor.GetMeta(objectName); err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Mode: mode, Errors: make([]*ImportFailure, 0)}
+	//notifications are pushed once the transaction is committed, so that nothing is pushed about rolled back records
+	err := processor.atomically(func() error {
+		transaction, err := processor.transactionManager.BeginTransaction()
+		if err != nil {
+			return err
+		}
+		defer transaction.Commit()
+		tx := transaction.Transaction()
+		for {
+			recordData, err := next()
+			if err == nil && recordData == nil {
+				break
+			}
+			report.Total++
+			if rowErr, ok := err.(*ImportRowError); ok {
+				report.Errors = append(report.Errors, &ImportFailure{Row: report.Total, Error: serializeImportError(rowErr.Err)})
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec("SAVEPOINT " + importSavepoint); err != nil {
+				return errors2.NewFatalError(ErrDMLFailed, err.Error(), nil)
+			}
+			if _, err := processor.CreateRecord(objectName, recordData, user); err != nil {
+				report.Errors = append(report.Errors, &ImportFailure{Row: report.Total, Error: serializeImportError(err)})
+				if _, err := tx.Exec("ROLLBACK TO SAVEPOINT " + importSavepoint); err != nil {
+					return errors2.NewFatalError(ErrDMLFailed, err.Error(), nil)
+				}
+				continue
+			}
+			report.Imported++
+		}
+
+		if mode == ImportModeAtomic && len(report.Errors) > 0 {
+			return errImportRolledBack
+		}
+		return nil
+	})
+	if err == errImportRolledBack {
+		report.Imported = 0
+		return report, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func serializeImportError(err error) interface{} {
+	if serverError, ok := err.(*errors2.ServerError); ok {
+		return serverError.Serialize()
+	}
+	return map[string]interface{}{"Msg": err.Error()}
+}
+
+//Returns rows of the NDJSON stream, one JSON object per line. Empty lines are skipped
+func NewNDJSONImportReader(r io.Reader) func() (map[string]interface{}, error) {
+	scanner := bufio.NewScanner(r)
+	//lines are limited to 16MB
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return func() (map[string]interface{}, error) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var recordData map[string]interface{}
+			if err := json.Unmarshal(line, &recordData); err != nil || recordData == nil {
+				return nil, &ImportRowError{errors2.NewValidationError(errors.ErrWrongImportRow, "Row is not a JSON object", nil)}
+			}
+			return recordData, nil
+		}
+		return nil, scanner.Err()
+	}
+}
+
+//Returns rows of the CSV stream, the header maps columns to the fields of the object. Values of the inner links are the keys
+//of the linked records, values of JSON and array fields are JSON documents. Empty values are skipped, so that defaults are applied
+func NewCSVImportReader(r io.Reader, objectMeta *Meta) (func() (map[string]interface{}, error), error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return func() (map[string]interface{}, error) { return nil, nil }, nil
+	} else if err != nil {
+		return nil, errors2.NewValidationError(errors.ErrWrongImportRow, fmt.Sprintf("CSV header is malformed: %s", err.Error()), nil)
+	}
+	fields := make([]*FieldDescription, len(header))
+	for i, column := range header {
+		fields[i] = objectMeta.FindField(column)
+		if fields[i] == nil {
+			return nil, errors2.NewValidationError(
+				errors.ErrWrongImportRow, fmt.Sprintf("Object '%s' doesn't have '%s' field", objectMeta.Name, column), nil,
+			)
+		}
+		if !fields[i].IsSimple() && !(fields[i].Type == description.FieldTypeObject && fields[i].LinkType == description.LinkTypeInner) {
+			return nil, errors2.NewValidationError(
+				errors.ErrWrongImportRow, fmt.Sprintf("Field '%s' can't be imported from CSV", column), nil,
+			)
+		}
+	}
+	reader.FieldsPerRecord = len(header)
+
+	return func() (map[string]interface{}, error) {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil, nil
+		} else if _, ok := err.(*csv.ParseError); ok {
+			return nil, &ImportRowError{errors2.NewValidationError(errors.ErrWrongImportRow, err.Error(), nil)}
+		} else if err != nil {
+			return nil, err
+		}
+		recordData := make(map[string]interface{})
+		for i, value := range row {
+			if value == "" {
+				continue
+			}
+			var fieldValue interface{}
+			switch fields[i].Type {
+			case description.FieldTypeJSON, description.FieldTypeStringArray, description.FieldTypeNumberArray:
+				err = json.Unmarshal([]byte(value), &fieldValue)
+			default:
+				fieldValue, err = fields[i].ValueFromString(value)
+			}
+			if err != nil {
+				return nil, &ImportRowError{errors2.NewValidationError(
+					errors.ErrWrongFiledType, fmt.Sprintf("Value '%s' of field '%s' has a wrong type", value, fields[i].Name), nil,
+				)}
+			}
+			recordData[fields[i].Name] = fieldValue
+		}
+		return recordData, nil
+	}, nil
+}
@@ -0,0 +1,143 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/noti"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Import", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	companyName := utils.RandomString(8)
+	employeeName := utils.RandomString(8)
+	var employeeMeta *object.Meta
+
+	BeforeEach(func() {
+		companyDescription := description.MetaDescription{
+			Name: companyName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeString},
+			},
+		}
+		companyMeta, err := metaStore.NewMeta(&companyDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(companyMeta)
+		Expect(err).To(BeNil())
+
+		employeeDescription := description.MetaDescription{
+			Name: employeeName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+				{Name: "name", Type: description.FieldTypeString},
+				{Name: "age", Type: description.FieldTypeInteger, Optional: true},
+				{Name: "company", Type: description.FieldTypeObject, LinkMeta: companyName, LinkType: description.LinkTypeInner, Optional: true},
+			},
+			Actions: []description.Action{
+				{Method: description.MethodCreate, Protocol: noti.TEST, Args: []string{"http://example.com"}, ActiveIfNotRoot: true, Name: "on_create"},
+			},
+		}
+		employeeMeta, err = metaStore.NewMeta(&employeeDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(employeeMeta)
+		Expect(err).To(BeNil())
+
+		_, err = dataProcessor.CreateRecord(companyName, map[string]interface{}{"id": "acme"}, auth.User{})
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	csvData := "name,age,company\nJohn,30,acme\nJane,unknown,acme\n,40,\nJack,,\n"
+
+	It("imports nothing in the atomic mode if any row fails", func() {
+		next, err := object.NewCSVImportReader(strings.NewReader(csvData), employeeMeta)
+		Expect(err).To(BeNil())
+
+		report, err := dataProcessor.ImportRecords(employeeName, next, object.ImportModeAtomic, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(report.Total).To(Equal(4))
+		Expect(report.Imported).To(Equal(0))
+		Expect(report.Errors).To(HaveLen(2))
+		Expect(report.Errors[0].Row).To(Equal(2))
+		Expect(report.Errors[1].Row).To(Equal(3))
+
+		count, _, err := dataProcessor.GetBulk(employeeName, "", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(0))
+	})
+
+	It("notifies about imported records only after the import is committed", func() {
+		meta, _, err := metaStore.Get(employeeName, true)
+		Expect(err).To(BeNil())
+		events := meta.Actions[0].Notifier.(*noti.TestNotifier).Events
+
+		next, err := object.NewCSVImportReader(strings.NewReader(csvData), employeeMeta)
+		Expect(err).To(BeNil())
+		_, err = dataProcessor.ImportRecords(employeeName, next, object.ImportModeAtomic, auth.User{})
+		Expect(err).To(BeNil())
+		Consistently(events).Should(BeEmpty())
+
+		next, err = object.NewCSVImportReader(strings.NewReader(csvData), employeeMeta)
+		Expect(err).To(BeNil())
+		_, err = dataProcessor.ImportRecords(employeeName, next, object.ImportModeBestEffort, auth.User{})
+		Expect(err).To(BeNil())
+		var event *noti.Event
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Obj()["current"].(map[string]interface{})["name"]).To(Equal("John"))
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Obj()["current"].(map[string]interface{})["name"]).To(Equal("Jack"))
+	})
+
+	It("imports valid rows in the best-effort mode", func() {
+		next, err := object.NewCSVImportReader(strings.NewReader(csvData), employeeMeta)
+		Expect(err).To(BeNil())
+
+		report, err := dataProcessor.ImportRecords(employeeName, next, object.ImportModeBestEffort, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(report.Imported).To(Equal(2))
+		Expect(report.Errors).To(HaveLen(2))
+
+		_, records, err := dataProcessor.GetBulk(employeeName, "sort(+id)", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Data["name"]).To(Equal("John"))
+		Expect(records[0].Data["company"]).To(Equal("acme"))
+		Expect(records[1].Data["name"]).To(Equal("Jack"))
+	})
+
+	It("imports rows of NDJSON", func() {
+		next := object.NewNDJSONImportReader(strings.NewReader("{\"name\": \"John\", \"company\": \"acme\"}\n\nnot a row\n"))
+
+		report, err := dataProcessor.ImportRecords(employeeName, next, object.ImportModeBestEffort, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(report.Total).To(Equal(2))
+		Expect(report.Imported).To(Equal(1))
+		Expect(report.Errors[0].Row).To(Equal(2))
+	})
+
+	It("does not accept CSV columns of unknown fields", func() {
+		_, err := object.NewCSVImportReader(strings.NewReader("name,salary\nJohn,100\n"), employeeMeta)
+		Expect(err).NotTo(BeNil())
+	})
+})
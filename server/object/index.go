@@ -0,0 +1,264 @@
+package object
+
+import (
+	"bytes"
+	"custodian/server/object/description"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Q-CIS-DEV/go-rql-parser"
+)
+
+const indexNamePrefix = "idx_"
+
+//DDL index of the table declared in the object's description, predicate is already translated into SQL
+type TableIndex struct {
+	Name      string
+	Columns   []string
+	Unique    bool
+	Method    string
+	Predicate string
+}
+
+//Indexes are unique within a schema, so the name of the table is a part of the index name
+func GetIndexName(tableName string, indexName string) string {
+	return indexNamePrefix + tableName + "_" + indexName
+}
+
+func (idx *TableIndex) Equal(another *TableIndex) bool {
+	return idx.Name == another.Name && idx.Unique == another.Unique && idx.Method == another.Method &&
+		idx.Predicate == another.Predicate && strings.Join(idx.Columns, ",") == strings.Join(another.Columns, ",")
+}
+
+const templCreateIndex = `CREATE {{if .dot.Unique}}UNIQUE {{end}}INDEX "{{.Name}}" ON "{{.Table}}" USING {{.dot.Method}} ({{range $i, $column := .dot.Columns}}{{if $i}}, {{end}}"{{$column}}"{{end}}){{if .dot.Predicate}} WHERE {{.dot.Predicate}}{{end}};`
+const templDropIndex = `DROP INDEX IF EXISTS "{{.Name}}";`
+const templRenameIndex = `ALTER INDEX IF EXISTS "{{.CurrentName}}" RENAME TO "{{.NewName}}";`
+
+var parsedTemplCreateIndex = template.Must(template.New("create_index").Parse(templCreateIndex))
+var parsedTemplDropIndex = template.Must(template.New("drop_index").Parse(templDropIndex))
+var parsedTemplRenameIndex = template.Must(template.New("rename_index").Parse(templRenameIndex))
+
+//Creates a DDL script to add the index
+func (idx *TableIndex) CreateScript(tableName string) (*DDLStmt, error) {
+	var buffer bytes.Buffer
+	if e := parsedTemplCreateIndex.Execute(&buffer, map[string]interface{}{
+		"Table": tableName,
+		"Name":  GetIndexName(tableName, idx.Name),
+		"dot":   idx}); e != nil {
+		return nil, NewDdlError(ErrInternal, e.Error(), tableName)
+	}
+	return NewDdlStatement(fmt.Sprintf("create_index#%s_%s", tableName, idx.Name), buffer.String()), nil
+}
+
+//Creates a DDL script to remove the index
+func (idx *TableIndex) DropScript(tableName string) (*DDLStmt, error) {
+	var buffer bytes.Buffer
+	if e := parsedTemplDropIndex.Execute(&buffer, map[string]interface{}{"Name": GetIndexName(tableName, idx.Name)}); e != nil {
+		return nil, NewDdlError(ErrInternal, e.Error(), tableName)
+	}
+	return NewDdlStatement(fmt.Sprintf("drop_index#%s_%s", tableName, idx.Name), buffer.String()), nil
+}
+
+//Creates a DDL script to rename the index along with the table it belongs to
+func (idx *TableIndex) RenameScript(currentTableName string, newTableName string) (*DDLStmt, error) {
+	var buffer bytes.Buffer
+	if e := parsedTemplRenameIndex.Execute(&buffer, map[string]interface{}{
+		"CurrentName": GetIndexName(currentTableName, idx.Name),
+		"NewName":     GetIndexName(newTableName, idx.Name)}); e != nil {
+		return nil, NewDdlError(ErrInternal, e.Error(), currentTableName)
+	}
+	return NewDdlStatement(fmt.Sprintf("rename_index#%s_%s", currentTableName, idx.Name), buffer.String()), nil
+}
+
+type IndexSliceCP struct {
+	from []TableIndex
+	to   *[]TableIndex
+}
+
+func (cp *IndexSliceCP) Id(i int) string { return cp.from[i].Name }
+func (cp *IndexSliceCP) Len() int        { return len(cp.from) }
+func (cp *IndexSliceCP) Copy(i int)      { *cp.to = append(*cp.to, cp.from[i]) }
+
+//Builds the DDL index for the index of the object's description, indexed fields must be the columns of the table
+func (mdf *MetaDdlFactory) FactoryIndex(index *description.Index, metaDdl *MetaDDL) (*TableIndex, error) {
+	if index.Name == "" {
+		return nil, NewDdlError(ErrWrongIndex, "Index name is required", metaDdl.Table)
+	}
+	if len(index.Fields) == 0 {
+		return nil, NewDdlError(ErrWrongIndex, fmt.Sprintf("Index '%s' has no fields", index.Name), metaDdl.Table)
+	}
+	if !index.IsMethodSupported() {
+		return nil, NewDdlError(ErrWrongIndex, fmt.Sprintf("Index '%s' has unsupported method '%s'", index.Name, index.Method), metaDdl.Table)
+	}
+	if index.GetMethod() == description.IndexMethodHash && (len(index.Fields) > 1 || index.Unique) {
+		return nil, NewDdlError(ErrWrongIndex, fmt.Sprintf("Hash index '%s' can't be unique or have multiple fields", index.Name), metaDdl.Table)
+	}
+	if index.Unique && index.GetMethod() != description.IndexMethodBtree {
+		return nil, NewDdlError(ErrWrongIndex, fmt.Sprintf("Only btree index '%s' can be unique", index.Name), metaDdl.Table)
+	}
+	for _, fieldName := range index.Fields {
+		if metaDdl.findColumn(fieldName) == nil {
+			return nil, NewDdlError(ErrWrongIndex, fmt.Sprintf("Index '%s' refers to unknown field '%s'", index.Name, fieldName), metaDdl.Table)
+		}
+	}
+	tableIndex := &TableIndex{Name: index.Name, Columns: index.Fields, Unique: index.Unique, Method: index.GetMethod()}
+	if index.Predicate != "" {
+		predicate, err := indexPredicateToSql(index.Predicate, metaDdl)
+		if err != nil {
+			return nil, NewDdlError(ErrWrongIndex, fmt.Sprintf("Index '%s' has wrong predicate: %s", index.Name, err.Error()), metaDdl.Table)
+		}
+		tableIndex.Predicate = predicate
+	}
+	return tableIndex, nil
+}
+
+func (md *MetaDDL) findColumn(name string) *Column {
+	for i := range md.Columns {
+		if md.Columns[i].Name == name {
+			return &md.Columns[i]
+		}
+	}
+	return nil
+}
+
+//Translates RQL-style predicate of the partial index into SQL.
+//Index DDL can't have bound parameters, so the values are inlined as literals
+func indexPredicateToSql(predicate string, metaDdl *MetaDDL) (string, error) {
+	rqlRoot, err := rqlParser.NewParser().Parse(predicate)
+	if err != nil {
+		return "", err
+	}
+	if rqlRoot.Node == nil {
+		return "", fmt.Errorf("predicate is empty")
+	}
+	return indexPredicateNodeToSql(rqlRoot.Node, metaDdl)
+}
+
+func indexPredicateNodeToSql(node *rqlParser.RqlNode, metaDdl *MetaDDL) (string, error) {
+	op := strings.ToUpper(node.Op)
+	switch op {
+	case "AND", "OR":
+		conditions := make([]string, 0, len(node.Args))
+		for _, arg := range node.Args {
+			argNode, ok := arg.(*rqlParser.RqlNode)
+			if !ok {
+				return "", fmt.Errorf("unexpected argument '%v' of '%s'", arg, node.Op)
+			}
+			condition, err := indexPredicateNodeToSql(argNode, metaDdl)
+			if err != nil {
+				return "", err
+			}
+			conditions = append(conditions, condition)
+		}
+		return "(" + strings.Join(conditions, " "+op+" ") + ")", nil
+	case "NOT":
+		if len(node.Args) != 1 {
+			return "", fmt.Errorf("expected only one argument for 'not'")
+		}
+		argNode, ok := node.Args[0].(*rqlParser.RqlNode)
+		if !ok {
+			return "", fmt.Errorf("unexpected argument '%v' of 'not'", node.Args[0])
+		}
+		condition, err := indexPredicateNodeToSql(argNode, metaDdl)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + condition + ")", nil
+	}
+
+	if len(node.Args) != 2 {
+		return "", fmt.Errorf("expected two arguments for '%s'", node.Op)
+	}
+	columnName, ok := node.Args[0].(string)
+	if !ok {
+		return "", fmt.Errorf("the field name of '%s' is not a string", node.Op)
+	}
+	column := metaDdl.findColumn(columnName)
+	if column == nil {
+		return "", fmt.Errorf("unknown field '%s'", columnName)
+	}
+	columnSql := fmt.Sprintf("\"%s\"", column.Name)
+
+	switch op {
+	case "EQ", "NE", "LT", "LE", "GT", "GE":
+		value, err := indexPredicateValueToSql(node.Args[1], column)
+		if err != nil {
+			return "", err
+		}
+		if value == "NULL" {
+			if op == "EQ" {
+				return columnSql + " IS NULL", nil
+			} else if op == "NE" {
+				return columnSql + " IS NOT NULL", nil
+			}
+			return "", fmt.Errorf("operator '%s' doesn't support NULL value", node.Op)
+		}
+		sqlOperators := map[string]string{"EQ": "=", "NE": "!=", "LT": "<", "LE": "<=", "GT": ">", "GE": ">="}
+		return fmt.Sprintf("%s %s %s", columnSql, sqlOperators[op], value), nil
+	case "IN":
+		values := []interface{}{node.Args[1]}
+		if valuesNode, ok := node.Args[1].(*rqlParser.RqlNode); ok {
+			values = valuesNode.Args
+		}
+		valuesSql := make([]string, 0, len(values))
+		for _, value := range values {
+			valueSql, err := indexPredicateValueToSql(value, column)
+			if err != nil {
+				return "", err
+			}
+			valuesSql = append(valuesSql, valueSql)
+		}
+		return fmt.Sprintf("%s IN (%s)", columnSql, strings.Join(valuesSql, ",")), nil
+	case "IS_NULL":
+		isNull, err := strconv.ParseBool(fmt.Sprintf("%v", node.Args[1]))
+		if err != nil {
+			return "", fmt.Errorf("second argument for is_null() must be 'true' or 'false'")
+		}
+		if isNull {
+			return columnSql + " IS NULL", nil
+		}
+		return columnSql + " IS NOT NULL", nil
+	default:
+		return "", fmt.Errorf("operator '%s' is not supported in index predicates", node.Op)
+	}
+}
+
+//Converts RQL value into SQL literal according to the type of the column
+func indexPredicateValueToSql(value interface{}, column *Column) (string, error) {
+	switch value := value.(type) {
+	case *rqlParser.RqlNode:
+		switch strings.ToUpper(value.Op) {
+		case "NULL":
+			return "NULL", nil
+		case "TRUE":
+			return "true", nil
+		case "FALSE":
+			return "false", nil
+		case "EMPTY":
+			return "''", nil
+		default:
+			return "", fmt.Errorf("value function '%s' is unknown", value.Op)
+		}
+	case string:
+		switch column.Typ {
+		case description.FieldTypeNumber, description.FieldTypeInteger, description.FieldTypeDecimal:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return "", fmt.Errorf("value '%s' of field '%s' is not a number", value, column.Name)
+			}
+			return strconv.FormatFloat(number, 'f', -1, 64), nil
+		case description.FieldTypeBool:
+			boolean, err := strconv.ParseBool(value)
+			if err != nil {
+				return "", fmt.Errorf("value '%s' of field '%s' is not a boolean", value, column.Name)
+			}
+			return strconv.FormatBool(boolean), nil
+		default:
+			return "'" + strings.Replace(value, "'", "''", -1) + "'", nil
+		}
+	default:
+		return "", fmt.Errorf("unknown value type: '%v'", value)
+	}
+}
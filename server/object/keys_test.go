@@ -0,0 +1,197 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Non-serial keys", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	companyName := utils.RandomString(8)
+	employeeName := utils.RandomString(8)
+	noteName := utils.RandomString(8)
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	havingObjects := func(keyField description.Field) {
+		companyDescription := description.MetaDescription{
+			Name:   companyName,
+			Key:    "id",
+			Cas:    false,
+			Fields: []description.Field{keyField, {Name: "name", Type: description.FieldTypeString, Optional: true}},
+		}
+		companyMeta, err := metaStore.NewMeta(&companyDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(companyMeta)
+		Expect(err).To(BeNil())
+
+		employeeDescription := description.MetaDescription{
+			Name: employeeName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeUUID, Optional: true, Def: map[string]interface{}{"func": "uuid"}},
+				{Name: "company", Type: description.FieldTypeObject, LinkMeta: companyName, LinkType: description.LinkTypeInner, OnDelete: "cascade"},
+			},
+		}
+		employeeMeta, err := metaStore.NewMeta(&employeeDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(employeeMeta)
+		Expect(err).To(BeNil())
+
+		noteDescription := description.MetaDescription{
+			Name: noteName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeUUID, Optional: true, Def: map[string]interface{}{"func": "uuid"}},
+				{Name: "target", Type: description.FieldTypeGeneric, LinkType: description.LinkTypeInner, LinkMetaList: []string{companyName}, Optional: true},
+			},
+		}
+		noteMeta, err := metaStore.NewMeta(&noteDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(noteMeta)
+		Expect(err).To(BeNil())
+
+		companyDescription.Fields = append(companyDescription.Fields, description.Field{
+			Name:           "employees",
+			Type:           description.FieldTypeArray,
+			LinkType:       description.LinkTypeOuter,
+			LinkMeta:       employeeName,
+			OuterLinkField: "company",
+			Optional:       true,
+		})
+		companyMeta, err = metaStore.NewMeta(&companyDescription)
+		Expect(err).To(BeNil())
+		_, err = metaStore.Update(companyMeta.Name, companyMeta, true, true)
+		Expect(err).To(BeNil())
+	}
+
+	It("Generates UUID keys on the server side", func() {
+		havingObjects(description.Field{Name: "id", Type: description.FieldTypeUUID, Optional: true, Def: map[string]interface{}{"func": "uuid"}})
+
+		company, err := dataProcessor.CreateRecord(companyName, map[string]interface{}{"name": "Acme"}, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(description.FieldTypeUUID.AssertType(company.Pk())).To(BeTrue())
+
+		employee, err := dataProcessor.CreateRecord(employeeName, map[string]interface{}{"company": company.Pk()}, auth.User{})
+		Expect(err).To(BeNil())
+
+		company, err = dataProcessor.Get(companyName, company.PkAsString(), nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(company.Data["employees"]).To(HaveLen(1))
+		Expect(employee.Data["company"]).To(Equal(company.Pk()))
+
+		_, records, err := dataProcessor.GetBulk(employeeName, "eq(company.name,Acme)", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(1))
+	})
+
+	It("Accepts natural keys supplied by the client", func() {
+		havingObjects(description.Field{Name: "id", Type: description.FieldTypeString})
+
+		company, err := dataProcessor.CreateRecord(companyName, map[string]interface{}{"id": "acme", "name": "Acme"}, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(company.Pk()).To(Equal("acme"))
+
+		note, err := dataProcessor.CreateRecord(noteName, map[string]interface{}{"target": map[string]interface{}{"_object": companyName, "id": "acme"}}, auth.User{})
+		Expect(err).To(BeNil())
+
+		note, err = dataProcessor.Get(noteName, note.PkAsString(), nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(note.Data["target"].(*object.GenericInnerLink).Pk).To(Equal("acme"))
+
+		_, records, err := dataProcessor.GetBulk(noteName, "eq(target."+companyName+".name,Acme)", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(1))
+	})
+
+	It("Links records by natural keys containing RQL special characters", func() {
+		tagDescription := description.MetaDescription{
+			Name:   utils.RandomString(8),
+			Key:    "code",
+			Cas:    false,
+			Fields: []description.Field{{Name: "code", Type: description.FieldTypeString}},
+		}
+		tagMeta, err := metaStore.NewMeta(&tagDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(tagMeta)
+		Expect(err).To(BeNil())
+
+		articleDescription := description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "code",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "code", Type: description.FieldTypeString},
+				{Name: "tags", Type: description.FieldTypeObjects, LinkMeta: tagMeta.Name, LinkType: description.LinkTypeInner, Optional: true},
+			},
+		}
+		articleMeta, err := metaStore.NewMeta(&articleDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(articleMeta)
+		Expect(err).To(BeNil())
+
+		_, err = dataProcessor.BulkCreateRecords(tagMeta.Name, []map[string]interface{}{{"code": "red,green"}, {"code": "(blue)"}}, auth.User{})
+		Expect(err).To(BeNil())
+		_, err = dataProcessor.CreateRecord(articleMeta.Name, map[string]interface{}{"code": "a,b", "tags": []interface{}{"red,green", "(blue)"}}, auth.User{})
+		Expect(err).To(BeNil())
+
+		article, err := dataProcessor.Get(articleMeta.Name, "a,b", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(article.Data["tags"]).To(ConsistOf("red,green", "(blue)"))
+
+		_, err = dataProcessor.UpdateRecord(articleMeta.Name, "a,b", map[string]interface{}{"tags": []interface{}{"(blue)"}}, auth.User{})
+		Expect(err).To(BeNil())
+		article, err = dataProcessor.Get(articleMeta.Name, "a,b", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(article.Data["tags"]).To(ConsistOf("(blue)"))
+
+		count, _, err := dataProcessor.GetBulkByKeys(tagMeta.Name, []interface{}{"red,green", "(blue)"}, nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(2))
+	})
+
+	It("Removes records linked to the natural key containing RQL special characters", func() {
+		havingObjects(description.Field{Name: "id", Type: description.FieldTypeString})
+
+		_, err := dataProcessor.CreateRecord(companyName, map[string]interface{}{"id": "acme,inc", "employees": []interface{}{map[string]interface{}{}}}, auth.User{})
+		Expect(err).To(BeNil())
+		_, err = dataProcessor.CreateRecord(companyName, map[string]interface{}{"id": "acme", "employees": []interface{}{map[string]interface{}{}}}, auth.User{})
+		Expect(err).To(BeNil())
+
+		_, err = dataProcessor.RemoveRecord(companyName, "acme,inc", auth.User{})
+		Expect(err).To(BeNil())
+
+		count, _, err := dataProcessor.GetBulk(employeeName, "", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(1))
+	})
+
+	It("Rejects keys of unsupported types", func() {
+		metaDescription := description.MetaDescription{
+			Name:   companyName,
+			Key:    "id",
+			Cas:    false,
+			Fields: []description.Field{{Name: "id", Type: description.FieldTypeBool}},
+		}
+		_, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).NotTo(BeNil())
+	})
+})
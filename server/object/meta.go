@@ -12,6 +12,7 @@ type Meta struct {
 	Key       *FieldDescription
 	Fields    []FieldDescription
 	Actions   []*Action
+	Hooks     []*Action
 }
 
 func (m *Meta) FindField(name string) *FieldDescription {
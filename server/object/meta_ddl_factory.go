@@ -31,11 +31,26 @@ func (mdf *MetaDdlFactory) Factory(metaDescription *description.MetaDescription)
 			}
 		}
 	}
+	if uniqueConstraints, err := mdf.factoryUniqueConstraints(metaDescription, metaDdl); err != nil {
+		return nil, err
+	} else {
+		metaDdl.UniqueTogether = uniqueConstraints
+	}
+	metaDdl.Indexes = make([]TableIndex, 0)
+	for i := range metaDescription.Indexes {
+		if index, err := mdf.FactoryIndex(&metaDescription.Indexes[i], metaDdl); err != nil {
+			return nil, err
+		} else {
+			metaDdl.Indexes = append(metaDdl.Indexes, *index)
+		}
+	}
 	return metaDdl, nil
 }
 
 func (mdf *MetaDdlFactory) FactoryFieldProperties(field *description.Field, metaDescription *description.MetaDescription) ([]Column, *IFK, *OFK, *Seq, error) {
-	if field.IsSimple() {
+	if field.IsVirtual() {
+		return nil, nil, nil, nil, nil
+	} else if field.IsSimple() {
 		return mdf.factorySimpleFieldProperties(field, metaDescription.Name)
 	} else if field.Type == description.FieldTypeObject && field.LinkType == description.LinkTypeInner {
 		return mdf.processInnerLinkField(field, metaDescription)
@@ -115,6 +130,8 @@ func (mdf *MetaDdlFactory) factoryBlankColumn(metaName string, field *descriptio
 	column := Column{}
 	column.Name = field.Name
 	column.Typ = field.Type
+	column.Precision = field.Precision
+	column.Scale = field.Scale
 	column.Optional = field.Optional
 	column.Unique = field.Unique
 
@@ -126,6 +143,17 @@ func (mdf *MetaDdlFactory) factoryBlankColumn(metaName string, field *descriptio
 	if len(field.Enum) > 0 {
 		column.Enum = field.Enum
 	}
+	if field.Searchable {
+		if field.Type != description.FieldTypeString {
+			return nil, &DDLError{table: metaName, code: ErrUnsupportedSearchField, msg: fmt.Sprintf("Field '%s' can't be searchable, only string fields are supported", field.Name)}
+		}
+		column.Searchable = true
+	}
+	if field.Stored {
+		if column.Formula, err = generatedColumnSql(field); err != nil {
+			return nil, &DDLError{table: metaName, code: ErrWrongFormula, msg: fmt.Sprintf("Field '%s' has wrong formula: %s", field.Name, err.Error())}
+		}
+	}
 
 	return &column, nil
 }
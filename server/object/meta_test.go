@@ -1,10 +1,13 @@
 package object
 
 import (
+	"custodian/server/noti"
 	"custodian/server/object/description"
 
 	"custodian/utils"
+	"encoding/json"
 	"fmt"
+	"os"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -189,6 +192,27 @@ var _ = Describe("The PG MetaStore", func() {
 		})
 	})
 
+	It("keeps the secret of the action in the environment variable", func() {
+		metaDescription := GetBaseMetaData(utils.RandomString(8))
+		metaDescription.Actions = []description.Action{
+			{Method: description.MethodCreate, Protocol: noti.REST, Args: []string{"http://example.com"}, Name: "signed", Sign: true, SecretEnv: "CUSTODIAN_TEST_ACTION_SECRET"},
+		}
+
+		os.Unsetenv("CUSTODIAN_TEST_ACTION_SECRET")
+		_, err := metaStore.NewMeta(metaDescription)
+		Expect(err).NotTo(BeNil())
+
+		os.Setenv("CUSTODIAN_TEST_ACTION_SECRET", "top-secret")
+		defer os.Unsetenv("CUSTODIAN_TEST_ACTION_SECRET")
+		meta, err := metaStore.NewMeta(metaDescription)
+		Expect(err).To(BeNil())
+		Expect(meta.Actions[0].WebhookOptions().Secret).To(Equal("top-secret"))
+
+		encoded, err := json.Marshal(meta.MetaDescription)
+		Expect(err).To(BeNil())
+		Expect(string(encoded)).NotTo(ContainSubstring("top-secret"))
+	})
+
 	It("can create table with camelCase fields", func() {
 		Context("having an object with camelCase fields", func() {
 			metaDescription := GetBaseMetaData(utils.RandomString(8))
@@ -33,10 +33,26 @@ func (ds *DdlStatementSet) Add(s *DDLStmt) {
 	*ds = append(*ds, s)
 }
 
+var decimalTypeRe = regexp.MustCompile(`^numeric\((\d+),(\d+)\)$`)
+
+//Returns precision and scale of the decimal column type, eg: "numeric(10,2)"
+func dbTypePrecision(dt string) (int, int) {
+	match := decimalTypeRe.FindStringSubmatch(dt)
+	if match == nil {
+		return 0, 0
+	}
+	precision, _ := strconv.Atoi(match[1])
+	scale, _ := strconv.Atoi(match[2])
+	return precision, scale
+}
+
 func dbTypeToFieldType(dt string) (description.FieldType, bool) {
 	if strings.HasPrefix(dt, `"o_`) || strings.HasPrefix(dt, `o_`) {
 		return description.FieldTypeEnum, true
 	}
+	if decimalTypeRe.MatchString(dt) {
+		return description.FieldTypeDecimal, true
+	}
 	switch dt {
 	case "text":
 		return description.FieldTypeString, true
@@ -44,6 +60,16 @@ func dbTypeToFieldType(dt string) (description.FieldType, bool) {
 		return description.FieldTypeNumber, true
 	case "integer":
 		return description.FieldTypeNumber, true
+	case "bigint":
+		return description.FieldTypeInteger, true
+	case "uuid":
+		return description.FieldTypeUUID, true
+	case "jsonb":
+		return description.FieldTypeJSON, true
+	case "text[]":
+		return description.FieldTypeStringArray, true
+	case "numeric[]":
+		return description.FieldTypeNumberArray, true
 	case "boolean":
 		return description.FieldTypeBool, true
 	case "timestamp with time zone":
@@ -60,22 +86,33 @@ func dbTypeToFieldType(dt string) (description.FieldType, bool) {
 
 //DDL table metadata
 type MetaDDL struct {
-	Table   string
-	Columns []Column
-	Pk      string
-	IFKs    []IFK
-	OFKs    []OFK
-	Seqs    []Seq
+	Table          string
+	Columns        []Column
+	Pk             string
+	IFKs           []IFK
+	OFKs           []OFK
+	Seqs           []Seq
+	Indexes        []TableIndex
+	UniqueTogether []UniqueConstraint
 }
 
 // DDL column meta
 type Column struct {
-	Name     string
-	Typ      description.FieldType
-	Optional bool
-	Unique   bool
-	Defval   string
-	Enum     description.EnumChoices
+	Name       string
+	Typ        description.FieldType
+	Optional   bool
+	Unique     bool
+	Defval     string
+	Enum       description.EnumChoices
+	Searchable bool
+	Formula    string //SQL expression of the generated column
+	Precision  int
+	Scale      int
+}
+
+//Returns the SQL type of the column
+func (cl Column) DdlType() (string, error) {
+	return description.ColumnDdlType(cl.Typ, cl.Precision, cl.Scale)
 }
 
 type IFK struct {
@@ -215,6 +252,11 @@ func defaultNextval(metaName string, f *description.Field, args []interface{}) (
 	}
 }
 
+//UUID is generated with gen_random_uuid() which is provided by pgcrypto extension prior to Postgres 13
+func defaultUUID(metaName string, f *description.Field, args []interface{}) (ColDefVal, error) {
+	return &ColDefValFunc{&description.DefExpr{Func: "gen_random_uuid"}}, nil
+}
+
 func defaultCurrentDate(metaName string, f *description.Field, args []interface{}) (ColDefVal, error) {
 	return &ColDefDate{}, nil
 }
@@ -233,6 +275,7 @@ func defaultOwner(metaName string, f *description.Field, args []interface{}) (Co
 
 var defaultFuncs = map[string]func(metaName string, f *description.Field, args []interface{}) (ColDefVal, error){
 	"nextval":           defaultNextval,
+	"uuid":              defaultUUID,
 	"current_date":      defaultCurrentDate,
 	"current_timestamp": defaultCurrentTimestamp,
 	"now":               defaultNow,
@@ -291,6 +334,10 @@ const (
 	{{range .IFKs}}
 		{{template "ifk" dict "Mtable" $mtable "dot" .}},{{"\n"}}
 	{{end}}
+
+	{{range .UniqueTogether}}
+		CONSTRAINT "{{.Name}}" UNIQUE ({{range $i, $column := .Columns}}{{if $i}}, {{end}}"{{$column}}"{{end}}),{{"\n"}}
+	{{end}}
 	
 	PRIMARY KEY ("{{.Pk}}")
     );`
@@ -298,8 +345,9 @@ const (
 		{{ $enum := len .dot.Enum}}
 		
 		"{{.dot.Name}}" 
-		{{ if gt $enum 0 }} "{{ .Mtable }}_{{ .dot.Name }}" {{ else }} {{ .dot.Typ.DdlType }}{{ end }}
+		{{ if gt $enum 0 }} "{{ .Mtable }}_{{ .dot.Name }}" {{ else }} {{ .dot.DdlType }}{{ end }}
 		{{if not .dot.Optional}} NOT NULL{{end}}{{if .dot.Unique}} UNIQUE{{end}}
+		{{if .dot.Formula}} GENERATED ALWAYS AS ({{.dot.Formula}}) STORED{{end}}
 		{{if .dot.Defval}} DEFAULT {{.dot.Defval}}{{if eq .dot.Typ 11}}::"{{.Mtable}}_{{.dot.Name}}"{{end}}{{end}}{{end}}`
 	templCreateTableInnerFK = `{{define "ifk"}}
 		CONSTRAINT fk_{{.dot.FromColumn}}_{{.dot.ToTable}}_{{.dot.ToColumn}} 
@@ -358,7 +406,7 @@ func (cl *Column) dropScript(tname string) (*DDLStmt, error) {
 }
 
 //DDL add table column template
-const templAddTableColumn = `ALTER TABLE "{{.Table}}" ADD COLUMN "{{.dot.Name}}" {{if eq .dot.Typ .FieldTypeEnum}} "{{.Table}}_{{.dot.Name}}" {{else}} {{.dot.Typ.DdlType}} {{end}} {{if not .dot.Optional}} NOT NULL{{end}}{{if .dot.Unique}} UNIQUE{{end}}{{if .dot.Defval}} DEFAULT {{.dot.Defval}}{{end}};`
+const templAddTableColumn = `ALTER TABLE "{{.Table}}" ADD COLUMN "{{.dot.Name}}" {{if eq .dot.Typ .FieldTypeEnum}} "{{.Table}}_{{.dot.Name}}" {{else}} {{.dot.DdlType}} {{end}} {{if not .dot.Optional}} NOT NULL{{end}}{{if .dot.Unique}} UNIQUE{{end}}{{if .dot.Formula}} GENERATED ALWAYS AS ({{.dot.Formula}}) STORED{{end}}{{if .dot.Defval}} DEFAULT {{.dot.Defval}}{{end}};`
 
 var parsedTemplAddTableColumn = template.Must(template.New("add_table_column").Funcs(ddlFuncs).Parse(templAddTableColumn))
 
@@ -375,7 +423,7 @@ func (cl *Column) addScript(tname string) (*DDLStmt, error) {
 	return &DDLStmt{Name: fmt.Sprintf("add_table_column#%s.%s", tname, cl.Name), Code: buffer.String()}, nil
 }
 
-const templAlterTableColumnAlterType = `ALTER TABLE "{{.Table}}" ALTER COLUMN "{{.dot.Name}}" SET DATA TYPE {{if eq .dot.Typ .FieldTypeEnum}} "{{.Table}}_{{.dot.Name}}" USING "{{.dot.Name}}"::text::"{{.Table}}_{{.dot.Name}}"{{else}} {{.dot.Typ.DdlType}} {{end}};`
+const templAlterTableColumnAlterType = `ALTER TABLE "{{.Table}}" ALTER COLUMN "{{.dot.Name}}" SET DATA TYPE {{if eq .dot.Typ .FieldTypeEnum}} "{{.Table}}_{{.dot.Name}}" USING "{{.dot.Name}}"::text::"{{.Table}}_{{.dot.Name}}"{{else}} {{.dot.DdlType}} USING "{{.dot.Name}}"::{{.dot.DdlType}} {{end}};`
 const templAlterTableColumnAlterNull = `ALTER TABLE "{{.Table}}" ALTER COLUMN "{{.dot.Name}}" {{if not .dot.Optional}} SET {{else}} DROP {{end}} NOT NULL;`
 const templAlterTableColumnAlterDefault = `ALTER TABLE "{{.Table}}" ALTER COLUMN "{{.dot.Name}}" {{if .dot.Defval}} SET DEFAULT {{.dot.Defval}} {{else}} DROP DEFAULT {{end}};`
 
@@ -398,10 +446,13 @@ func (cl *Column) alterScript(tname string) (*DDLStmt, error) {
 		"dot":   cl}); e != nil {
 		return nil, &DDLError{table: tname, code: ErrInternal, msg: e.Error()}
 	}
-	if e := parsedTemplAlterTableColumnAlterDefault.Execute(&buffer, map[string]interface{}{
-		"Table": tname,
-		"dot":   cl}); e != nil {
-		return nil, &DDLError{table: tname, code: ErrInternal, msg: e.Error()}
+	//generated column has no default value
+	if cl.Formula == "" {
+		if e := parsedTemplAlterTableColumnAlterDefault.Execute(&buffer, map[string]interface{}{
+			"Table": tname,
+			"dot":   cl}); e != nil {
+			return nil, &DDLError{table: tname, code: ErrInternal, msg: e.Error()}
+		}
 	}
 	return &DDLStmt{Name: fmt.Sprintf("alter_table_column#%s.%s", tname, cl.Name), Code: buffer.String()}, nil
 }
@@ -516,6 +567,22 @@ func (md *MetaDDL) CreateScript() (DdlStatementSet, error) {
 	} else {
 		stmts.Add(s)
 	}
+	for _, col := range md.Columns {
+		if col.Searchable {
+			if s, err := CreateSearchStatements(md.Table, col.Name); err != nil {
+				return nil, err
+			} else {
+				stmts = append(stmts, s...)
+			}
+		}
+	}
+	for i := range md.Indexes {
+		if s, err := md.Indexes[i].CreateScript(md.Table); err != nil {
+			return nil, err
+		} else {
+			stmts.Add(s)
+		}
+	}
 	return stmts, nil
 }
 
@@ -615,16 +682,20 @@ func (cp *OFKSliceCP) Copy(i int) { *cp.to = append(*cp.to, cp.from[i]) }
 
 // Difference of two meta DDL
 type MetaDDLDiff struct {
-	Table     string
-	ColsRem   []Column
-	ColsAdd   []Column
-	ColsAlter []Column
-	IFKsRem   []IFK
-	IFKsAdd   []IFK
-	OFKsRem   []OFK
-	OFKsAdd   []OFK
-	SeqsAdd   []Seq
-	SeqsRem   []Seq
+	Table      string
+	ColsRem    []Column
+	ColsAdd    []Column
+	ColsAlter  []Column
+	IFKsRem    []IFK
+	IFKsAdd    []IFK
+	OFKsRem    []OFK
+	OFKsAdd    []OFK
+	SeqsAdd    []Seq
+	SeqsRem    []Seq
+	IndexesRem []TableIndex
+	IndexesAdd []TableIndex
+	UniqueRem  []UniqueConstraint
+	UniqueAdd  []UniqueConstraint
 }
 
 // Calculate difference between two meta DDL
@@ -637,13 +708,32 @@ func (m1 *MetaDDL) Diff(m2 *MetaDDL) (*MetaDDLDiff, error) {
 	InverseIntersect(&IFKSliceCP{m1.IFKs, &mdd.IFKsRem}, &IFKSliceCP{m2.IFKs, &mdd.IFKsAdd})
 	InverseIntersect(&OFKSliceCP{m1.OFKs, &mdd.OFKsRem}, &OFKSliceCP{m2.OFKs, &mdd.OFKsAdd})
 	InverseIntersect(&SeqSliceCP{m1.Seqs, &mdd.SeqsRem}, &SeqSliceCP{m2.Seqs, &mdd.SeqsAdd})
+	InverseIntersect(&IndexSliceCP{m1.Indexes, &mdd.IndexesRem}, &IndexSliceCP{m2.Indexes, &mdd.IndexesAdd})
+	InverseIntersect(&UniqueConstraintSliceCP{m1.UniqueTogether, &mdd.UniqueRem}, &UniqueConstraintSliceCP{m2.UniqueTogether, &mdd.UniqueAdd})
+	//changed indexes are recreated
+	for i := range m1.Indexes {
+		for j := range m2.Indexes {
+			if m1.Indexes[i].Name == m2.Indexes[j].Name && !m1.Indexes[i].Equal(&m2.Indexes[j]) {
+				mdd.IndexesRem = append(mdd.IndexesRem, m1.Indexes[i])
+				mdd.IndexesAdd = append(mdd.IndexesAdd, m2.Indexes[j])
+			}
+		}
+	}
 	//process fields update check
 	for _, currentObjectColumn := range m1.Columns {
 		for _, objectToUpdateColumn := range m2.Columns {
 			//omit this check for PK`s until TB-116 is implemented
 			if currentObjectColumn.Name == objectToUpdateColumn.Name && m1.Pk != currentObjectColumn.Name {
+				//expression of the generated column can't be altered, so the column is recreated
+				if currentObjectColumn.Formula != objectToUpdateColumn.Formula {
+					mdd.ColsRem = append(mdd.ColsRem, currentObjectColumn)
+					mdd.ColsAdd = append(mdd.ColsAdd, objectToUpdateColumn)
+					continue
+				}
 				if currentObjectColumn.Optional != objectToUpdateColumn.Optional ||
-					currentObjectColumn.Typ != objectToUpdateColumn.Typ || len(objectToUpdateColumn.Enum) > 0 {
+					currentObjectColumn.Typ != objectToUpdateColumn.Typ || len(objectToUpdateColumn.Enum) > 0 ||
+					currentObjectColumn.Precision != objectToUpdateColumn.Precision || currentObjectColumn.Scale != objectToUpdateColumn.Scale ||
+					currentObjectColumn.Searchable != objectToUpdateColumn.Searchable {
 					mdd.ColsAlter = append(mdd.ColsAlter, objectToUpdateColumn)
 
 					if currentObjectColumn.Typ == description.FieldTypeEnum &&
@@ -660,6 +750,20 @@ func (m1 *MetaDDL) Diff(m2 *MetaDDL) (*MetaDDLDiff, error) {
 
 func (m *MetaDDLDiff) Script() (DdlStatementSet, error) {
 	var stmts = DdlStatementSet{}
+	for i := range m.IndexesRem {
+		if s, e := m.IndexesRem[i].DropScript(m.Table); e != nil {
+			return nil, e
+		} else {
+			stmts.Add(s)
+		}
+	}
+	for i := range m.UniqueRem {
+		if s, e := m.UniqueRem[i].DropScript(m.Table); e != nil {
+			return nil, e
+		} else {
+			stmts.Add(s)
+		}
+	}
 	for i, _ := range m.IFKsRem {
 		if s, e := m.IFKsRem[i].dropScript(m.Table); e != nil {
 			return nil, e
@@ -668,6 +772,14 @@ func (m *MetaDDLDiff) Script() (DdlStatementSet, error) {
 		}
 	}
 	for i, _ := range m.ColsRem {
+		//generated search column depends on the column, so it is dropped first
+		if m.ColsRem[i].Searchable {
+			if s, e := DropSearchColumnStatement(m.Table, m.ColsRem[i].Name); e != nil {
+				return nil, e
+			} else {
+				stmts.Add(s)
+			}
+		}
 		if s, e := m.ColsRem[i].dropScript(m.Table); e != nil {
 			return nil, e
 		} else {
@@ -706,6 +818,13 @@ func (m *MetaDDLDiff) Script() (DdlStatementSet, error) {
 		} else {
 			stmts.Add(s)
 		}
+		if m.ColsAdd[i].Searchable {
+			if s, e := CreateSearchStatements(m.Table, m.ColsAdd[i].Name); e != nil {
+				return nil, e
+			} else {
+				stmts = append(stmts, s...)
+			}
+		}
 	}
 	for i, _ := range m.ColsAlter {
 		if len(m.ColsAlter[i].Enum) > 0 {
@@ -722,11 +841,24 @@ func (m *MetaDDLDiff) Script() (DdlStatementSet, error) {
 				}
 			}
 		}
+		//generated search column prevents the column type from being altered, so it is recreated
+		if s, e := DropSearchColumnStatement(m.Table, m.ColsAlter[i].Name); e != nil {
+			return nil, e
+		} else {
+			stmts.Add(s)
+		}
 		if s, e := m.ColsAlter[i].alterScript(m.Table); e != nil {
 			return nil, e
 		} else {
 			stmts.Add(s)
 		}
+		if m.ColsAlter[i].Searchable {
+			if s, e := CreateSearchStatements(m.Table, m.ColsAlter[i].Name); e != nil {
+				return nil, e
+			} else {
+				stmts = append(stmts, s...)
+			}
+		}
 	}
 	for i, _ := range m.IFKsAdd {
 		if s, e := m.IFKsAdd[i].addScript(m.Table); e != nil {
@@ -735,6 +867,20 @@ func (m *MetaDDLDiff) Script() (DdlStatementSet, error) {
 			stmts.Add(s)
 		}
 	}
+	for i := range m.UniqueAdd {
+		if s, e := m.UniqueAdd[i].AddScript(m.Table); e != nil {
+			return nil, e
+		} else {
+			stmts.Add(s)
+		}
+	}
+	for i := range m.IndexesAdd {
+		if s, e := m.IndexesAdd[i].CreateScript(m.Table); e != nil {
+			return nil, e
+		} else {
+			stmts.Add(s)
+		}
+	}
 	/*for i, _ := range m.OFKsAdd {
 		if s, e := m.OFKsAdd[i].CreateTableDdlStatement(); e != nil {
 			return nil, e
@@ -767,6 +913,12 @@ func MetaDDLFromDB(tx *sql.Tx, name string) (*MetaDDL, error) {
 	if err = reverser.Constraints(&md.IFKs, &md.OFKs); err != nil {
 		return nil, err
 	}
+	if err = reverser.Indexes(&md.Indexes); err != nil {
+		return nil, err
+	}
+	if err = reverser.UniqueTogether(&md.UniqueTogether); err != nil {
+		return nil, err
+	}
 
 	for i, _ := range md.Columns {
 		if strs := seqNameParseRe.FindAllStringSubmatch(md.Columns[i].Defval, -1); len(strs) > 0 {
@@ -6,9 +6,14 @@ import (
 	"custodian/server/migrations/description"
 	"custodian/server/migrations/operations"
 	"custodian/server/migrations/operations/action"
+	"custodian/server/migrations/operations/check"
+	object_operations "custodian/server/migrations/operations/object"
+	"custodian/server/migrations/operations/rollup"
 	meta_description "custodian/server/object/description"
 	"custodian/server/object/migrations/operations/field"
+	"custodian/server/object/migrations/operations/index"
 	"custodian/server/object/migrations/operations/object"
+	"custodian/server/object/migrations/operations/unique"
 	"fmt"
 )
 
@@ -56,6 +61,45 @@ func (of *OperationFactory) Factory(migrationDescription *description.MigrationD
 		return action.NewUpdateActionOperation(currentAction, &operationDescription.Action.Action), nil
 	case description.RemoveActionOperation:
 		return action.NewRemoveActionOperation(&operationDescription.Action.Action), nil
+	case description.AddIndexOperation:
+		return index.NewAddIndexOperation(operationDescription.Index), nil
+	case description.RemoveIndexOperation:
+		targetIndex := metaDescription.FindIndex(operationDescription.Index.Name)
+		if targetIndex == nil {
+			return nil, errors.NewValidationError(migrations.MigrationErrorInvalidDescription, fmt.Sprintf("meta %s has no index %s", metaDescription.Name, operationDescription.Index.Name), nil)
+		}
+		return index.NewRemoveIndexOperation(targetIndex), nil
+	case description.AddUniqueTogetherOperation:
+		return unique.NewAddUniqueTogetherOperation(operationDescription.UniqueTogether), nil
+	case description.RemoveUniqueTogetherOperation:
+		if metaDescription.FindUniqueTogether(operationDescription.UniqueTogether) < 0 {
+			return nil, errors.NewValidationError(migrations.MigrationErrorInvalidDescription, fmt.Sprintf("meta %s has no fields %v unique together", metaDescription.Name, operationDescription.UniqueTogether), nil)
+		}
+		return unique.NewRemoveUniqueTogetherOperation(operationDescription.UniqueTogether), nil
+	case description.AddCheckOperation:
+		return check.NewAddCheckOperation(operationDescription.Check), nil
+	case description.RemoveCheckOperation:
+		targetCheck := metaDescription.FindCheck(operationDescription.Check.Name)
+		if targetCheck == nil {
+			return nil, errors.NewValidationError(migrations.MigrationErrorInvalidDescription, fmt.Sprintf("meta %s has no check %s", metaDescription.Name, operationDescription.Check.Name), nil)
+		}
+		return check.NewRemoveCheckOperation(targetCheck), nil
+	case description.AddRollupOperation:
+		return rollup.NewAddRollupOperation(operationDescription.Rollup), nil
+	case description.RemoveRollupOperation:
+		targetRollup := metaDescription.FindRollup(operationDescription.Rollup.Name)
+		if targetRollup == nil {
+			return nil, errors.NewValidationError(migrations.MigrationErrorInvalidDescription, fmt.Sprintf("meta %s has no rollup %s", metaDescription.Name, operationDescription.Rollup.Name), nil)
+		}
+		return rollup.NewRemoveRollupOperation(targetRollup), nil
+	case description.EnableSoftDeleteOperation:
+		return object.NewUpdateSoftDeleteOperation(true), nil
+	case description.DisableSoftDeleteOperation:
+		return object.NewUpdateSoftDeleteOperation(false), nil
+	case description.EnableHistoryOperation:
+		return object_operations.NewUpdateHistoryOperation(true), nil
+	case description.DisableHistoryOperation:
+		return object_operations.NewUpdateHistoryOperation(false), nil
 	}
 	return nil, errors.NewValidationError(migrations.MigrationErrorInvalidDescription, fmt.Sprintf(fmt.Sprintf("unknown type of operation(%s)", operationDescription.Type), metaDescription.Name, operationDescription), nil)
 }
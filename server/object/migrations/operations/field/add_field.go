@@ -80,6 +80,13 @@ func (o *AddFieldOperation) addColumnStatements(statementSet *object.DdlStatemen
 			}
 			statementSet.Add(statement)
 		}
+		if column.Searchable {
+			statements, err := object.CreateSearchStatements(tableName, column.Name)
+			if err != nil {
+				return err
+			}
+			*statementSet = append(*statementSet, statements...)
+		}
 	}
 	return nil
 }
@@ -63,6 +63,13 @@ func (o *RemoveFieldOperation) addColumnStatements(statementSet *object.DdlState
 	statementFactory := new(statement_factories.ColumnStatementFactory)
 	tableName := object.GetTableName(metaDescription.Name)
 	for _, column := range columns {
+		if column.Searchable {
+			statement, err := object.DropSearchColumnStatement(tableName, column.Name)
+			if err != nil {
+				return err
+			}
+			statementSet.Add(statement)
+		}
 		statement, err := statementFactory.FactoryDropStatement(tableName, column)
 		if err != nil {
 			return err
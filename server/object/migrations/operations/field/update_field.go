@@ -93,6 +93,24 @@ func (o *UpdateFieldOperation) factoryColumnsStatements(statementSet *object.Ddl
 	statementFactory := new(statement_factories.ColumnStatementFactory)
 	constraintFactory := new(statement_factories.ConstraintStatementFactory)
 	tableName := object.GetTableName(metaDescription.Name)
+	//column of the computed field depends on its formula, so it is recreated if the formula is changed
+	if o.CurrentField.Formula != o.NewField.Formula || o.CurrentField.Stored != o.NewField.Stored {
+		for _, column := range currentColumns {
+			statement, err := statementFactory.FactoryDropStatement(tableName, column)
+			if err != nil {
+				return err
+			}
+			statementSet.Add(statement)
+		}
+		for _, column := range newColumns {
+			statement, err := statementFactory.FactoryAddStatement(tableName, column)
+			if err != nil {
+				return err
+			}
+			statementSet.Add(statement)
+		}
+		return nil
+	}
 	if len(currentColumns) != len(newColumns) {
 		return errors.NewFatalError(migrations.MigrationErrorInvalidDescription, "Update column migration cannot be done with difference numbers of columns", nil)
 	} else {
@@ -101,6 +119,14 @@ func (o *UpdateFieldOperation) factoryColumnsStatements(statementSet *object.Ddl
 		for i := range currentColumns {
 			currentColumn = currentColumns[i]
 			newColumn = newColumns[i]
+			if currentColumn.Searchable {
+				//generated search column depends on the column, it is recreated after the column is updated
+				statement, err := object.DropSearchColumnStatement(tableName, currentColumn.Name)
+				if err != nil {
+					return err
+				}
+				statementSet.Add(statement)
+			}
 			if currentColumn.Name != newColumn.Name {
 				//process renaming
 				statement, err := statementFactory.FactoryRenameStatement(tableName, currentColumn, newColumn)
@@ -124,7 +150,7 @@ func (o *UpdateFieldOperation) factoryColumnsStatements(statementSet *object.Ddl
 				}
 				statementSet.Add(statement)
 			}
-			if currentColumn.Typ != newColumn.Typ {
+			if currentColumn.Typ != newColumn.Typ || currentColumn.Precision != newColumn.Precision || currentColumn.Scale != newColumn.Scale {
 				if len(newColumn.Enum) > 0 {
 					statement, err := object.CreateEnumStatement(tableName, newColumn.Name, newColumn.Enum)
 					if err != nil {
@@ -181,6 +207,13 @@ func (o *UpdateFieldOperation) factoryColumnsStatements(statementSet *object.Ddl
 					statementSet.Add(statement)
 				}
 			}
+			if newColumn.Searchable {
+				statements, err := object.CreateSearchStatements(tableName, newColumn.Name)
+				if err != nil {
+					return err
+				}
+				*statementSet = append(*statementSet, statements...)
+			}
 		}
 	}
 	return nil
@@ -0,0 +1,43 @@
+package index
+
+import (
+	"custodian/logger"
+	"custodian/server/migrations/operations/index"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+
+	"fmt"
+)
+
+type AddIndexOperation struct {
+	index.AddIndexOperation
+}
+
+func (o *AddIndexOperation) SyncDbDescription(metaDescriptionToApply *description.MetaDescription, transaction transactions.DbTransaction, syncer object.MetaDescriptionSyncer) (err error) {
+	tx := transaction.Transaction()
+
+	metaDdlFactory := object.NewMetaDdlFactory(syncer)
+	metaDdl, err := metaDdlFactory.Factory(metaDescriptionToApply)
+	if err != nil {
+		return err
+	}
+	tableIndex, err := metaDdlFactory.FactoryIndex(o.Index, metaDdl)
+	if err != nil {
+		return err
+	}
+	statement, err := tableIndex.CreateScript(metaDdl.Table)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Creating index in DB: %s\n", statement.Code)
+	if _, err = tx.Exec(statement.Code); err != nil {
+		return object.NewDdlError(metaDescriptionToApply.Name, object.ErrExecutingDDL, fmt.Sprintf("Error while executing statement '%s': %s", statement.Name, err.Error()))
+	}
+	return nil
+}
+
+func NewAddIndexOperation(targetIndex *description.Index) *AddIndexOperation {
+	return &AddIndexOperation{index.AddIndexOperation{Index: targetIndex}}
+}
@@ -0,0 +1,20 @@
+package index_test
+
+import (
+	"github.com/onsi/ginkgo/reporters"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestIndex(t *testing.T) {
+	RegisterFailHandler(Fail)
+	if ci := os.Getenv("CI"); ci != "" {
+		teamcityReporter := reporters.NewTeamCityReporter(os.Stdout)
+		RunSpecsWithCustomReporters(t, "Index Suite", []Reporter{teamcityReporter})
+	} else {
+		RunSpecs(t, "Index Suite")
+	}
+}
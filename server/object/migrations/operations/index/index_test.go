@@ -0,0 +1,114 @@
+package index
+
+import (
+	object2 "custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/object/migrations/operations/object"
+
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("'AddIndex' and 'RemoveIndex' Migration Operations", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object2.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object2.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object2.NewPgMetaDescriptionSyncer(dbTransactionManager, object2.NewCache(), db)
+	metaStore := object2.NewStore(metaDescriptionSyncer, dbTransactionManager)
+
+	var metaDescription *description.MetaDescription
+
+	//setup transaction
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	//setup MetaDescription
+	BeforeEach(func() {
+		metaDescription = &description.MetaDescription{
+			Name: "a",
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name: "id",
+					Type: description.FieldTypeNumber,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name:     "name",
+					Type:     description.FieldTypeString,
+					Optional: true,
+				},
+				{
+					Name:     "active",
+					Type:     description.FieldTypeBool,
+					Optional: true,
+				},
+			},
+		}
+		globalTransaction, err := dbTransactionManager.BeginTransaction()
+		Expect(err).To(BeNil())
+
+		operation := object.NewCreateObjectOperation(metaDescription)
+		metaDescription, err = operation.SyncMetaDescription(nil, metaDescriptionSyncer)
+		Expect(err).To(BeNil())
+		err = operation.SyncDbDescription(metaDescription, globalTransaction, metaDescriptionSyncer)
+		Expect(err).To(BeNil())
+
+		globalTransaction.Commit()
+	})
+
+	It("creates partial unique index and removes it", func() {
+		globalTransaction, err := dbTransactionManager.BeginTransaction()
+		Expect(err).To(BeNil())
+
+		index := description.Index{Name: "active_name", Fields: []string{"name"}, Unique: true, Predicate: "eq(active,true)"}
+		addIndexOperation := NewAddIndexOperation(&index)
+		err = addIndexOperation.SyncDbDescription(metaDescription, globalTransaction, metaDescriptionSyncer)
+		Expect(err).To(BeNil())
+		metaDescription, err = addIndexOperation.SyncMetaDescription(metaDescription, metaDescriptionSyncer)
+		Expect(err).To(BeNil())
+		Expect(metaDescription.Indexes).To(HaveLen(1))
+
+		metaDdlFromDB, err := object2.MetaDDLFromDB(globalTransaction.Transaction(), metaDescription.Name)
+		Expect(err).To(BeNil())
+		Expect(metaDdlFromDB.Indexes).To(HaveLen(1))
+		Expect(metaDdlFromDB.Indexes[0].Name).To(Equal("active_name"))
+		Expect(metaDdlFromDB.Indexes[0].Columns).To(Equal([]string{"name"}))
+		Expect(metaDdlFromDB.Indexes[0].Unique).To(BeTrue())
+		Expect(metaDdlFromDB.Indexes[0].Method).To(Equal(description.IndexMethodBtree))
+		Expect(metaDdlFromDB.Indexes[0].Predicate).To(ContainSubstring("active"))
+
+		removeIndexOperation := NewRemoveIndexOperation(metaDescription.FindIndex("active_name"))
+		err = removeIndexOperation.SyncDbDescription(metaDescription, globalTransaction, metaDescriptionSyncer)
+		Expect(err).To(BeNil())
+		metaDescription, err = removeIndexOperation.SyncMetaDescription(metaDescription, metaDescriptionSyncer)
+		Expect(err).To(BeNil())
+		Expect(metaDescription.Indexes).To(HaveLen(0))
+
+		metaDdlFromDB, err = object2.MetaDDLFromDB(globalTransaction.Transaction(), metaDescription.Name)
+		Expect(err).To(BeNil())
+		Expect(metaDdlFromDB.Indexes).To(HaveLen(0))
+
+		globalTransaction.Commit()
+	})
+
+	It("does not add index on unknown field", func() {
+		globalTransaction, err := dbTransactionManager.BeginTransaction()
+		Expect(err).To(BeNil())
+
+		index := description.Index{Name: "unknown", Fields: []string{"unknown"}}
+		err = NewAddIndexOperation(&index).SyncDbDescription(metaDescription, globalTransaction, metaDescriptionSyncer)
+		Expect(err).NotTo(BeNil())
+
+		globalTransaction.Rollback()
+	})
+})
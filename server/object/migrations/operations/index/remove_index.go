@@ -0,0 +1,35 @@
+package index
+
+import (
+	"custodian/logger"
+	"custodian/server/migrations/operations/index"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+
+	"fmt"
+)
+
+type RemoveIndexOperation struct {
+	index.RemoveIndexOperation
+}
+
+func (o *RemoveIndexOperation) SyncDbDescription(metaDescription *description.MetaDescription, transaction transactions.DbTransaction, syncer object.MetaDescriptionSyncer) (err error) {
+	tx := transaction.Transaction()
+
+	tableIndex := object.TableIndex{Name: o.Index.Name}
+	statement, err := tableIndex.DropScript(object.GetTableName(metaDescription.Name))
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Removing index from DB: %s\n", statement.Code)
+	if _, err = tx.Exec(statement.Code); err != nil {
+		return object.NewDdlError(metaDescription.Name, object.ErrExecutingDDL, fmt.Sprintf("Error while executing statement '%s': %s", statement.Name, err.Error()))
+	}
+	return nil
+}
+
+func NewRemoveIndexOperation(targetIndex *description.Index) *RemoveIndexOperation {
+	return &RemoveIndexOperation{index.RemoveIndexOperation{Index: targetIndex}}
+}
@@ -46,6 +46,13 @@ func (o *CreateObjectOperation) SyncDbDescription(_ *description.MetaDescription
 	} else {
 		statementSet.Add(statement)
 	}
+	for i := range metaDdl.Indexes {
+		if statement, err := metaDdl.Indexes[i].CreateScript(metaDdl.Table); err != nil {
+			return err
+		} else {
+			statementSet.Add(statement)
+		}
+	}
 
 	for _, statement := range statementSet {
 		logger.Debug("Creating object in DB: %syncer\n", statement.Code)
@@ -43,6 +43,15 @@ func (o *RenameObjectOperation) SyncDbDescription(metaDescription *description.M
 		}
 	}
 
+	for i := range metaDescription.Indexes {
+		tableIndex := object2.TableIndex{Name: metaDescription.Indexes[i].Name}
+		statement, err := tableIndex.RenameScript(object2.GetTableName(metaDescription.Name), object2.GetTableName(o.MetaDescription.Name))
+		if err != nil {
+			return err
+		}
+		statementSet.Add(statement)
+	}
+
 	for _, statement := range statementSet {
 		logger.Debug("Renaming object: %s\n", statement.Code)
 		if _, err = tx.Exec(statement.Code); err != nil {
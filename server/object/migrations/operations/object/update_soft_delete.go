@@ -0,0 +1,46 @@
+package object
+
+import (
+	"custodian/logger"
+	"custodian/server/migrations/operations/object"
+	object2 "custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/object/migrations/operations/statement_factories"
+	"custodian/server/transactions"
+
+	"fmt"
+)
+
+type UpdateSoftDeleteOperation struct {
+	object.UpdateSoftDeleteOperation
+}
+
+//Adds the column of the deletion mark if soft delete is being enabled and the object has no such field yet
+func (o *UpdateSoftDeleteOperation) SyncDbDescription(metaDescription *description.MetaDescription, transaction transactions.DbTransaction, syncer object2.MetaDescriptionSyncer) (err error) {
+	if !o.SoftDelete || metaDescription.FindField(description.SoftDeleteFieldName) != nil {
+		return nil
+	}
+	tx := transaction.Transaction()
+
+	softDeleteField := description.Field{Name: description.SoftDeleteFieldName, Type: description.FieldTypeDateTime, Optional: true}
+	columns, _, _, _, err := object2.NewMetaDdlFactory(syncer).FactoryFieldProperties(&softDeleteField, metaDescription)
+	if err != nil {
+		return err
+	}
+	tableName := object2.GetTableName(metaDescription.Name)
+	for _, column := range columns {
+		statement, err := new(statement_factories.ColumnStatementFactory).FactoryAddStatement(tableName, column)
+		if err != nil {
+			return err
+		}
+		logger.Debug("Adding deletion mark to DB: %s\n", statement.Code)
+		if _, err = tx.Exec(statement.Code); err != nil {
+			return object2.NewDdlError(metaDescription.Name, object2.ErrExecutingDDL, fmt.Sprintf("Error while executing statement '%s': %s", statement.Name, err.Error()))
+		}
+	}
+	return nil
+}
+
+func NewUpdateSoftDeleteOperation(softDelete bool) *UpdateSoftDeleteOperation {
+	return &UpdateSoftDeleteOperation{object.UpdateSoftDeleteOperation{SoftDelete: softDelete}}
+}
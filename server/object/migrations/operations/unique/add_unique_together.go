@@ -0,0 +1,43 @@
+package unique
+
+import (
+	"custodian/logger"
+	"custodian/server/migrations/operations/unique"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+
+	"fmt"
+)
+
+type AddUniqueTogetherOperation struct {
+	unique.AddUniqueTogetherOperation
+}
+
+func (o *AddUniqueTogetherOperation) SyncDbDescription(metaDescriptionToApply *description.MetaDescription, transaction transactions.DbTransaction, syncer object.MetaDescriptionSyncer) (err error) {
+	tx := transaction.Transaction()
+
+	metaDdlFactory := object.NewMetaDdlFactory(syncer)
+	metaDdl, err := metaDdlFactory.Factory(metaDescriptionToApply)
+	if err != nil {
+		return err
+	}
+	constraint, err := metaDdlFactory.FactoryUniqueConstraint(o.Fields, metaDdl)
+	if err != nil {
+		return err
+	}
+	statement, err := constraint.AddScript(metaDdl.Table)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Creating unique constraint in DB: %s\n", statement.Code)
+	if _, err = tx.Exec(statement.Code); err != nil {
+		return object.NewDdlError(metaDescriptionToApply.Name, object.ErrExecutingDDL, fmt.Sprintf("Error while executing statement '%s': %s", statement.Name, err.Error()))
+	}
+	return nil
+}
+
+func NewAddUniqueTogetherOperation(fields []string) *AddUniqueTogetherOperation {
+	return &AddUniqueTogetherOperation{unique.AddUniqueTogetherOperation{Fields: fields}}
+}
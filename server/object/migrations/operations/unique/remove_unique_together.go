@@ -0,0 +1,35 @@
+package unique
+
+import (
+	"custodian/logger"
+	"custodian/server/migrations/operations/unique"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+
+	"fmt"
+)
+
+type RemoveUniqueTogetherOperation struct {
+	unique.RemoveUniqueTogetherOperation
+}
+
+func (o *RemoveUniqueTogetherOperation) SyncDbDescription(metaDescription *description.MetaDescription, transaction transactions.DbTransaction, syncer object.MetaDescriptionSyncer) (err error) {
+	tx := transaction.Transaction()
+
+	tableName := object.GetTableName(metaDescription.Name)
+	statement, err := object.NewUniqueConstraint(tableName, o.Fields).DropScript(tableName)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Removing unique constraint from DB: %s\n", statement.Code)
+	if _, err = tx.Exec(statement.Code); err != nil {
+		return object.NewDdlError(metaDescription.Name, object.ErrExecutingDDL, fmt.Sprintf("Error while executing statement '%s': %s", statement.Name, err.Error()))
+	}
+	return nil
+}
+
+func NewRemoveUniqueTogetherOperation(fields []string) *RemoveUniqueTogetherOperation {
+	return &RemoveUniqueTogetherOperation{unique.RemoveUniqueTogetherOperation{Fields: fields}}
+}
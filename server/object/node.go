@@ -3,7 +3,6 @@ package object
 import (
 	"custodian/server/object/description"
 	"errors"
-	"fmt"
 	rqlParser "github.com/Q-CIS-DEV/go-rql-parser"
 )
 
@@ -145,7 +144,7 @@ func (node *Node) keyAsNativeType(recordValues map[string]interface{}, objectMet
 
 func (node *Node) ResolveByRql(sc SearchContext, rqlNode *rqlParser.RqlRootNode) ([]*Record, int, error) {
 	var results []*Record
-	raw, count, err := sc.processor.GetRql(node, rqlNode, node.SelectFields.FieldList, sc.DbTransaction)
+	raw, count, err := sc.processor.GetRqlPage(node, rqlNode, node.SelectFields.FieldList, sc.Pagination, sc.DbTransaction)
 
 	if err == nil {
 		for _, obj := range raw {
@@ -210,6 +209,14 @@ func (node *Node) Resolve(sc SearchContext, key interface{}) (*Record, error) {
 	//return obj, nil
 }
 
+//Add the condition excluding records marked as deleted to the filters of plural node
+func (node *Node) pluralFilters(filters map[string]interface{}) map[string]interface{} {
+	if node.Meta.SoftDelete {
+		filters[description.SoftDeleteFieldName] = nil
+	}
+	return filters
+}
+
 func (node *Node) ResolveRegularPlural(sc SearchContext, key interface{}) ([]interface{}, error) {
 	// logger.Debug("Resolving plural: node [meta=%s, depth=%s, plural=%s], sc=%s, key=%s", node.Meta.Name, node.Depth, node.plural, sc, key)
 	var fields []*FieldDescription = nil
@@ -218,7 +225,7 @@ func (node *Node) ResolveRegularPlural(sc SearchContext, key interface{}) ([]int
 	} else {
 		fields = node.SelectFields.FieldList
 	}
-	if records, err := sc.processor.GetAll(node.Meta, fields, map[string]interface{}{node.KeyField.Name: key}, sc.DbTransaction); err != nil {
+	if records, err := sc.processor.GetAll(node.Meta, fields, node.pluralFilters(map[string]interface{}{node.KeyField.Name: key}), sc.DbTransaction); err != nil {
 		return nil, err
 	} else {
 		result := make([]interface{}, len(records), len(records))
@@ -246,10 +253,10 @@ func (node *Node) ResolveGenericPlural(sc SearchContext, key interface{}, object
 	if node.OnlyLink {
 		fields = []*FieldDescription{node.Meta.Key}
 	}
-	if records, err := sc.processor.GetAll(node.Meta, fields, map[string]interface{}{
+	if records, err := sc.processor.GetAll(node.Meta, fields, node.pluralFilters(map[string]interface{}{
 		GetGenericFieldKeyColumnName(node.KeyField.Name):  key,
 		GetGenericFieldTypeColumnName(node.KeyField.Name): objectMeta.Name,
-	}, sc.DbTransaction); err != nil {
+	}), sc.DbTransaction); err != nil {
 		return nil, err
 	} else {
 		result := make([]interface{}, len(records), len(records))
@@ -276,7 +283,7 @@ func (node *Node) ResolvePluralObjects(sc SearchContext, key interface{}) ([]int
 		linkField := node.Meta.FindField(node.LinkField.Meta.Name)
 		//specify field, which value should be retrieved
 		fields := []*FieldDescription{node.KeyField}
-		if records, err := sc.processor.GetAll(node.Meta, fields, map[string]interface{}{linkField.Name: key}, sc.DbTransaction); err != nil {
+		if records, err := sc.processor.GetAll(node.Meta, fields, node.pluralFilters(map[string]interface{}{linkField.Name: key}), sc.DbTransaction); err != nil {
 			return nil, err
 		} else {
 			result := make([]interface{}, len(records), len(records))
@@ -291,7 +298,7 @@ func (node *Node) ResolvePluralObjects(sc SearchContext, key interface{}) ([]int
 		//Eg: for record of object A with ID 56 which has "Objects" relation to B called "bs" filter will look like this:
 		//eq(b__s_set.a,56)
 		//and querying is performed by B meta
-		filter := fmt.Sprintf("eq(%s.%s,%s)", node.LinkField.LinkThrough.FindField(node.LinkField.LinkMeta.Name).ReverseOuterField().Name, node.LinkField.Meta.Name, keyStr)
+		rqlNode := newRqlRootNode(newEqRqlNode(node.LinkField.LinkThrough.FindField(node.LinkField.LinkMeta.Name).ReverseOuterField().Name+"."+node.LinkField.Meta.Name, keyStr))
 		searchContext := SearchContext{DepthLimit: 1, processor: sc.processor, LazyPath: "/custodian/data/bulk", DbTransaction: sc.DbTransaction, OmitOuters: sc.OmitOuters}
 		root := &Node{
 			KeyField:       node.LinkField.LinkMeta.Key,
@@ -307,12 +314,10 @@ func (node *Node) ResolvePluralObjects(sc SearchContext, key interface{}) ([]int
 		}
 		root.RecursivelyFillChildNodes(searchContext.DepthLimit, description.FieldModeRetrieve)
 
-		parser := rqlParser.NewParser()
-		rqlNode, err := parser.Parse(filter)
+		records, _, err := root.ResolveByRql(searchContext, rqlNode)
 		if err != nil {
 			return nil, err
 		}
-		records, _, err := root.ResolveByRql(searchContext, rqlNode)
 
 		result := make([]interface{}, len(records), len(records))
 		for i, data := range records {
@@ -462,6 +467,7 @@ func (node *Node) FillRecordValues(record *Record, searchContext SearchContext)
 	nodeCopy := node
 	//node may mutate during resolving of generic fields, thus local copy of node is required
 	for nodeResults := []ResultNode{{nodeCopy, record}}; len(nodeResults) > 0; nodeResults = nodeResults[1:] {
+		nodeResults[0].node.fillComputedValues(nodeResults[0].values, searchContext)
 		childNodesResults, _ := nodeResults[0].getFilledChildNodes(searchContext)
 		nodeResults = append(nodeResults, childNodesResults...)
 	}
@@ -20,11 +20,7 @@ func (notificationSender *notificationSender) push(recordSetNotification *Record
 		if action.Method.AsString() == recordSetNotification.Method.AsString() {
 
 			notificationChannel := action.NewNotificationChannel()
-			for _, notificationObject := range recordSetNotification.BuildNotificationsData(
-				recordSetNotification.PreviousState[action.Id()],
-				recordSetNotification.CurrentState[action.Id()],
-				user,
-			) {
+			for _, notificationObject := range recordSetNotification.BuildNotificationsData(action, user) {
 				notificationChannel <- noti.NewObjectEvent(notificationObject, recordSetNotification.isRoot)
 			}
 		}
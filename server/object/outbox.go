@@ -0,0 +1,261 @@
+package object
+
+import (
+	"custodian/logger"
+	"custodian/server/auth"
+	errors2 "custodian/server/errors"
+	"custodian/server/noti"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusDead    = "dead"
+)
+
+const (
+	SQL_CREATE_OUTBOX_TABLE      = `CREATE TABLE IF NOT EXISTS "o___custodian_outbox__" ("id" SERIAL, "object" text NOT NULL, "method" text NOT NULL, "action" text NOT NULL, "is_root" bool NOT NULL, "payload" text NOT NULL, "status" text NOT NULL DEFAULT 'pending', "attempts" integer NOT NULL DEFAULT 0, "next_attempt" timestamp with time zone NOT NULL DEFAULT now(), "last_error" text NULL, "created" timestamp with time zone NOT NULL DEFAULT now(), PRIMARY KEY ("id"));`
+	SQL_CREATE_OUTBOX_INDEX      = `CREATE INDEX IF NOT EXISTS "o___custodian_outbox___due" ON "o___custodian_outbox__" ("status", "next_attempt");`
+	SQL_INSERT_OUTBOX_ENTRY      = `INSERT INTO "o___custodian_outbox__" ("object", "method", "action", "is_root", "payload") VALUES ($1, $2, $3, $4, $5);`
+	SQL_CLAIM_DUE_OUTBOX_ENTRIES = `UPDATE "o___custodian_outbox__" SET "next_attempt" = now() + make_interval(secs => $2) WHERE "id" IN (SELECT "id" FROM "o___custodian_outbox__" WHERE "status" = 'pending' AND "next_attempt" <= now() ORDER BY "id" LIMIT $1 FOR UPDATE SKIP LOCKED) RETURNING "id", "object", "method", "action", "is_root", "payload", "status", "attempts", "next_attempt", "last_error", "created";`
+	SQL_SELECT_OUTBOX_ENTRIES    = `SELECT "id", "object", "method", "action", "is_root", "payload", "status", "attempts", "next_attempt", "last_error", "created" FROM "o___custodian_outbox__" WHERE "status" = $1 ORDER BY "id" LIMIT $2 OFFSET $3;`
+	SQL_COUNT_OUTBOX_ENTRIES     = `SELECT count(*) FROM "o___custodian_outbox__" WHERE "status" = $1;`
+	SQL_DELETE_OUTBOX_ENTRY      = `DELETE FROM "o___custodian_outbox__" WHERE "id" = $1;`
+	SQL_FAIL_OUTBOX_ENTRY        = `UPDATE "o___custodian_outbox__" SET "status" = $2, "attempts" = "attempts" + 1, "next_attempt" = now() + make_interval(secs => $3), "last_error" = $4 WHERE "id" = $1;`
+	SQL_RETRY_OUTBOX_ENTRY       = `UPDATE "o___custodian_outbox__" SET "status" = 'pending', "attempts" = 0, "next_attempt" = now() WHERE "id" = $1;`
+)
+
+//Delivery settings of the outbox, the pause before the next attempt is doubled after each failure
+var (
+	OutboxPollInterval = time.Second
+	OutboxBatchSize    = 100
+	OutboxMaxAttempts  = 10
+	OutboxBackoffBase  = 10 * time.Second
+	OutboxBackoffMax   = time.Hour
+	//claimed entries are not dispatched again until the lease expires, it must be longer than delivery of the batch
+	OutboxLease = 10 * time.Minute
+)
+
+type OutboxEntry struct {
+	Id          int                    `json:"id"`
+	Object      string                 `json:"object"`
+	Method      string                 `json:"method"`
+	Action      *description.Action    `json:"action"`
+	IsRoot      bool                   `json:"is_root"`
+	Payload     map[string]interface{} `json:"payload"`
+	Status      string                 `json:"status"`
+	Attempts    int                    `json:"attempts"`
+	NextAttempt time.Time              `json:"next_attempt"`
+	LastError   *string                `json:"last_error"`
+	Created     time.Time              `json:"created"`
+}
+
+//Write notifications of the given method to the outbox within the current transaction, each notification is written only once
+func (processor *Processor) writeOutbox(notificationPool *RecordSetNotificationPool, method description.Method, user auth.User) error {
+	operation := func(dbTransaction transactions.DbTransaction) error {
+		var stmt *Stmt
+		for _, notification := range notificationPool.Notifications() {
+			if notification.outboxWritten || !notification.ShouldBeProcessed(method) {
+				continue
+			}
+			notification.outboxWritten = true
+			for _, action := range notification.Actions {
+				if action.Method.AsString() != method.AsString() {
+					continue
+				}
+				encodedAction, err := json.Marshal(action)
+				if err != nil {
+					return err
+				}
+				for _, data := range notification.BuildNotificationsData(action, user) {
+					payload, err := json.Marshal(data)
+					if err != nil {
+						return err
+					}
+					if stmt == nil {
+						if stmt, err = dbTransaction.(*PgTransaction).Prepare(SQL_INSERT_OUTBOX_ENTRY); err != nil {
+							return err
+						}
+						defer stmt.Close()
+					}
+					if _, err := stmt.Exec(notification.recordSet.Meta.Name, method.AsString(), string(encodedAction), notification.isRoot, string(payload)); err != nil {
+						return errors2.NewFatalError(ErrDMLFailed, err.Error(), nil)
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	dbTransaction, err := processor.transactionManager.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	if err := dbTransaction.Execute([]transactions.Operation{operation}); err != nil {
+		dbTransaction.Rollback()
+		return err
+	}
+	return dbTransaction.Commit()
+}
+
+//Outbox of notifications, pending entries are delivered in background until they are delivered or run out of attempts
+type NotificationOutbox struct {
+	db   *sql.DB
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func NewNotificationOutbox(db *sql.DB) *NotificationOutbox {
+	return &NotificationOutbox{db: db}
+}
+
+//Start delivering pending entries in background
+func (outbox *NotificationOutbox) Start() {
+	if outbox.stop != nil {
+		return
+	}
+	outbox.stop = make(chan struct{})
+	outbox.done.Add(1)
+	go func() {
+		defer outbox.done.Done()
+		ticker := time.NewTicker(OutboxPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-outbox.stop:
+				return
+			case <-ticker.C:
+				for {
+					count, err := outbox.Dispatch()
+					if err != nil {
+						logger.Error("Failed to dispatch outbox notifications: %s", err.Error())
+					}
+					if err != nil || count < OutboxBatchSize {
+						break
+					}
+				}
+			}
+		}
+	}()
+}
+
+//Stop delivering and wait for the current round to finish
+func (outbox *NotificationOutbox) Stop() {
+	if outbox.stop == nil {
+		return
+	}
+	close(outbox.stop)
+	outbox.done.Wait()
+	outbox.stop = nil
+}
+
+//Deliver a batch of due entries, delivered entries are removed. Returns the number of processed entries.
+//Entries are claimed by postponing their next attempt for the lease, so that no locks are held during delivery
+//and the entries are dispatched again once the lease expires if the dispatcher stops before updating them
+func (outbox *NotificationOutbox) Dispatch() (int, error) {
+	rows, err := outbox.db.Query(SQL_CLAIM_DUE_OUTBOX_ENTRIES, OutboxBatchSize, OutboxLease.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	entries, err := scanOutboxEntries(rows)
+	if err != nil {
+		return 0, err
+	}
+	//the order of returned rows is not defined
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Id < entries[j].Id })
+
+	for _, entry := range entries {
+		if err := entry.deliver(); err != nil {
+			status := OutboxStatusPending
+			if entry.Attempts+1 >= OutboxMaxAttempts {
+				status = OutboxStatusDead
+				logger.Error("Outbox notification %d of '%s' is dead after %d attempts: %s", entry.Id, entry.Object, entry.Attempts+1, err.Error())
+			}
+			if _, err := outbox.db.Exec(SQL_FAIL_OUTBOX_ENTRY, entry.Id, status, outboxBackoff(entry.Attempts+1).Seconds(), err.Error()); err != nil {
+				return 0, err
+			}
+		} else if _, err := outbox.db.Exec(SQL_DELETE_OUTBOX_ENTRY, entry.Id); err != nil {
+			return 0, err
+		}
+	}
+	return len(entries), nil
+}
+
+//List entries having the given status
+func (outbox *NotificationOutbox) List(status string, limit int, offset int) (int, []*OutboxEntry, error) {
+	if status != OutboxStatusPending && status != OutboxStatusDead {
+		return 0, nil, errors2.NewValidationError(ErrWrongOutboxStatus, fmt.Sprintf("Outbox status must be '%s' or '%s'", OutboxStatusPending, OutboxStatusDead), nil)
+	}
+	var total int
+	if err := outbox.db.QueryRow(SQL_COUNT_OUTBOX_ENTRIES, status).Scan(&total); err != nil {
+		return 0, nil, err
+	}
+	rows, err := outbox.db.Query(SQL_SELECT_OUTBOX_ENTRIES, status, limit, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	entries, err := scanOutboxEntries(rows)
+	if err != nil {
+		return 0, nil, err
+	}
+	return total, entries, nil
+}
+
+//Return the entry to the pending state with the attempts counter reset, so that it is delivered as soon as possible
+func (outbox *NotificationOutbox) Retry(id int) error {
+	result, err := outbox.db.Exec(SQL_RETRY_OUTBOX_ENTRY, id)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors2.NewNotFoundError(ErrOutboxEntryNotFound, fmt.Sprintf("Outbox entry %d not found", id), nil)
+	}
+	return nil
+}
+
+//Pause before the given attempt
+func outboxBackoff(attempts int) time.Duration {
+	backoff := OutboxBackoffBase
+	for i := 1; i < attempts && backoff < OutboxBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > OutboxBackoffMax {
+		backoff = OutboxBackoffMax
+	}
+	return backoff
+}
+
+func (entry *OutboxEntry) deliver() error {
+	action := *entry.Action
+	if err := description.InitAction(0, &action); err != nil {
+		return err
+	}
+	return noti.Deliver(action.Notifier, noti.NewObjectEvent(entry.Payload, entry.IsRoot))
+}
+
+func scanOutboxEntries(rows *sql.Rows) ([]*OutboxEntry, error) {
+	defer rows.Close()
+	entries := make([]*OutboxEntry, 0)
+	for rows.Next() {
+		entry := &OutboxEntry{Action: &description.Action{}}
+		var action, payload string
+		if err := rows.Scan(&entry.Id, &entry.Object, &entry.Method, &action, &entry.IsRoot, &payload, &entry.Status, &entry.Attempts, &entry.NextAttempt, &entry.LastError, &entry.Created); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(action), entry.Action); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(payload), &entry.Payload); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
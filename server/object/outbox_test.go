@@ -0,0 +1,187 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/noti"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notification outbox", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+	dataProcessor.UseOutbox()
+
+	outbox := object.NewNotificationOutbox(db)
+
+	var mutex sync.Mutex
+	var received []map[string]interface{}
+	var status int
+	var onDelivery func()
+	callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if onDelivery != nil {
+			onDelivery()
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		var data map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&data)
+		received = append(received, data)
+		w.WriteHeader(status)
+	}))
+
+	backoffBase := object.OutboxBackoffBase
+
+	BeforeEach(func() {
+		_, err := db.Exec(`DELETE FROM "o___custodian_outbox__";`)
+		Expect(err).To(BeNil())
+		received = nil
+		status = http.StatusOK
+		onDelivery = nil
+		//failed entries are due immediately
+		object.OutboxBackoffBase = 0
+	})
+
+	AfterEach(func() {
+		object.OutboxBackoffBase = backoffBase
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	havingObjectWithAction := func() *object.Meta {
+		metaDescription := description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name:     "name",
+					Type:     description.FieldTypeString,
+					Optional: true,
+				},
+			},
+			Actions: []description.Action{
+				{
+					Method:          description.MethodCreate,
+					Protocol:        noti.REST,
+					Args:            []string{callbackServer.URL},
+					ActiveIfNotRoot: true,
+					Name:            "on_create",
+				},
+			},
+		}
+		metaObj, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(metaObj)
+		Expect(err).To(BeNil())
+		return metaObj
+	}
+
+	It("Writes notifications to the outbox and delivers them on dispatch", func() {
+		metaObj := havingObjectWithAction()
+
+		_, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		total, entries, err := outbox.List(object.OutboxStatusPending, 10, 0)
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(1))
+		Expect(entries[0].Object).To(Equal(metaObj.Name))
+		Expect(entries[0].Action.Name).To(Equal("on_create"))
+		Expect(received).To(BeEmpty())
+
+		count, err := outbox.Dispatch()
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(1))
+		Expect(received).To(HaveLen(1))
+		Expect(received[0]["current"].(map[string]interface{})["name"]).To(Equal("first"))
+
+		total, _, err = outbox.List(object.OutboxStatusPending, 10, 0)
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(0))
+	})
+
+	It("Does not dispatch entries being delivered by another dispatcher", func() {
+		metaObj := havingObjectWithAction()
+
+		_, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		var concurrentCount int
+		var concurrentErr error
+		onDelivery = func() {
+			concurrentCount, concurrentErr = outbox.Dispatch()
+		}
+		count, err := outbox.Dispatch()
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(1))
+		Expect(concurrentErr).To(BeNil())
+		Expect(concurrentCount).To(Equal(0))
+		Expect(received).To(HaveLen(1))
+	})
+
+	It("Does not write notifications of the failed change", func() {
+		metaObj := havingObjectWithAction()
+
+		_, err := dataProcessor.BulkCreateRecords(metaObj.Name, []map[string]interface{}{{"name": "first"}, {"id": "wrong"}}, auth.User{})
+		Expect(err).NotTo(BeNil())
+
+		total, _, err := outbox.List(object.OutboxStatusPending, 10, 0)
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(0))
+	})
+
+	It("Marks the entry as dead when attempts are exhausted and retries it on demand", func() {
+		metaObj := havingObjectWithAction()
+		status = http.StatusInternalServerError
+
+		_, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		for i := 0; i < object.OutboxMaxAttempts; i++ {
+			_, err := outbox.Dispatch()
+			Expect(err).To(BeNil())
+		}
+		Expect(received).To(HaveLen(object.OutboxMaxAttempts))
+
+		total, entries, err := outbox.List(object.OutboxStatusDead, 10, 0)
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(1))
+		Expect(entries[0].Attempts).To(Equal(object.OutboxMaxAttempts))
+		Expect(entries[0].LastError).NotTo(BeNil())
+
+		status = http.StatusOK
+		err = outbox.Retry(entries[0].Id)
+		Expect(err).To(BeNil())
+
+		count, err := outbox.Dispatch()
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(1))
+
+		total, _, err = outbox.List(object.OutboxStatusDead, 10, 0)
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(0))
+	})
+})
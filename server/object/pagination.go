@@ -0,0 +1,52 @@
+package object
+
+import (
+	errors2 "custodian/server/errors"
+	"custodian/server/object/errors"
+	"fmt"
+)
+
+//Mode of counting records matching the filter, which is returned with the list
+type CountMode string
+
+const (
+	CountExact    CountMode = "exact"
+	CountEstimate CountMode = "estimate"
+	CountNone     CountMode = "none"
+	CountHasMore  CountMode = "has_more"
+)
+
+func ParseCountMode(mode string) (CountMode, error) {
+	switch CountMode(mode) {
+	case "":
+		return CountExact, nil
+	case CountExact, CountEstimate, CountNone, CountHasMore:
+		return CountMode(mode), nil
+	}
+	return "", errors2.NewValidationError(
+		errors.ErrWrongCountMode, fmt.Sprintf("Count mode '%s' is unknown, expected: exact, estimate, none or has_more", mode), nil,
+	)
+}
+
+//Pagination describes how the list of records is paginated and counted, it receives the state of the next page
+type Pagination struct {
+	//records are paginated by the cursor instead of the offset, empty cursor requests the first page
+	ByCursor bool
+	Cursor   string
+	Count    CountMode
+	//cursor of the next page, it is empty on the last page
+	Next string
+	//set if the count mode is has_more and there are records after the page
+	HasMore bool
+}
+
+func (p *Pagination) byCursor() bool {
+	return p != nil && p.ByCursor
+}
+
+func (p *Pagination) countMode() CountMode {
+	if p == nil || p.Count == "" {
+		return CountExact
+	}
+	return p.Count
+}
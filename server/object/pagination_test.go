@@ -0,0 +1,95 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pagination", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	objectName := utils.RandomString(8)
+
+	BeforeEach(func() {
+		metaDescription := description.MetaDescription{
+			Name: objectName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+				{Name: "name", Type: description.FieldTypeString, Optional: true},
+			},
+		}
+		meta, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(meta)
+		Expect(err).To(BeNil())
+
+		for _, name := range []string{"b", "a", "c", "a", "b"} {
+			_, err := dataProcessor.CreateRecord(objectName, map[string]interface{}{"name": name}, auth.User{})
+			Expect(err).To(BeNil())
+		}
+	})
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	It("can fetch all records page by page with the cursor", func() {
+		pagination := &object.Pagination{ByCursor: true}
+		names := make([]interface{}, 0)
+		for pages := 0; pages < 5; pages++ {
+			count, records, err := dataProcessor.GetBulkPage(objectName, "sort(-name),limit(0,2)", pagination, nil, nil, 1, false)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(5))
+			for _, record := range records {
+				names = append(names, record.Data["name"])
+			}
+			if pagination.Next == "" {
+				break
+			}
+			pagination = &object.Pagination{ByCursor: true, Cursor: pagination.Next}
+		}
+		Expect(names).To(Equal([]interface{}{"c", "b", "b", "a", "a"}))
+	})
+
+	It("can check if there are more records instead of counting them", func() {
+		pagination := &object.Pagination{Count: object.CountHasMore}
+		count, records, err := dataProcessor.GetBulkPage(objectName, "limit(3,2)", pagination, nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(0))
+		Expect(records).To(HaveLen(2))
+		Expect(pagination.HasMore).To(BeFalse())
+
+		pagination = &object.Pagination{Count: object.CountHasMore}
+		_, records, err = dataProcessor.GetBulkPage(objectName, "limit(2,2)", pagination, nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(2))
+		Expect(pagination.HasMore).To(BeTrue())
+	})
+
+	It("can estimate the count of records", func() {
+		pagination := &object.Pagination{Count: object.CountEstimate}
+		_, records, err := dataProcessor.GetBulkPage(objectName, "eq(name,a)", pagination, nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(2))
+	})
+
+	It("does not accept unknown count mode", func() {
+		_, err := object.ParseCountMode("approximate")
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -21,6 +21,7 @@ const (
 	CHECK_META_OBJ_EXISTS string = `SELECT 1 FROM o___meta__ WHERE meta_description ->> 'name'=$1;`
 	CREATE_META_OBJ       string = `INSERT INTO o___meta__ (meta_description) VALUES ($1);`
 	UPDATE_META_OBJ       string = `UPDATE o___meta__ SET meta_description=$1 WHERE meta_description ->> 'name'=$2;`
+	SQL_CREATE_UUID_EXT   string = `CREATE EXTENSION IF NOT EXISTS pgcrypto;`
 )
 
 func getMetaObjFromDb(name string, tx *sql.Tx) (description.MetaDescription, error) {
@@ -48,6 +49,11 @@ func NewPgMetaDescriptionSyncer(globalTransactionManager *PgDbTransactionManager
 	if len(mc.metaList) == 0 {
 		md := PgMetaDescriptionSyncer{globalTransactionManager, mc}
 		db.Exec(SQL_CREATE_META_TABLE)
+		db.Exec(SQL_CREATE_UUID_EXT)
+		db.Exec(SQL_CREATE_RECORD_HISTORY_TABLE)
+		db.Exec(SQL_CREATE_RECORD_HISTORY_INDEX)
+		db.Exec(SQL_CREATE_OUTBOX_TABLE)
+		db.Exec(SQL_CREATE_OUTBOX_INDEX)
 
 		metaDescriptionList, _, _ := md.List()
 		mc.Fill(metaDescriptionList)
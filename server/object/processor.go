@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/jackc/pgconn"
+	"github.com/lib/pq"
 	"regexp"
 
 	_ "github.com/jackc/pgx/v4/stdlib"
@@ -24,20 +25,29 @@ import (
 type DBManager struct {}
 
 const (
-	ErrTemplateFailed     = "template_failed"
-	ErrInvalidArgument    = "invalid_argument"
-	ErrDMLFailed          = "dml_failed"
-	ErrValidation         = "validation_error"
-	ErrValueDuplication   = "duplicated_value_error"
-	ErrConvertationFailed = "convertation_failed"
-	ErrCommitFailed       = "commit_failed"
+	ErrTemplateFailed      = "template_failed"
+	ErrInvalidArgument     = "invalid_argument"
+	ErrDMLFailed           = "dml_failed"
+	ErrValidation          = "validation_error"
+	ErrValueDuplication    = "duplicated_value_error"
+	ErrConvertationFailed  = "convertation_failed"
+	ErrCommitFailed        = "commit_failed"
+	ErrCasValueAbsent      = "cas_value_absent"
+	ErrCasConflict         = "cas_conflict"
+	ErrSoftDeleteDisabled  = "soft_delete_disabled"
+	ErrRecordNotDeleted    = "record_not_deleted"
+	ErrHistoryDisabled     = "history_disabled"
+	ErrOutboxEntryNotFound = "outbox_entry_not_found"
+	ErrWrongOutboxStatus   = "wrong_outbox_status"
+	ErrHookRejected        = "hook_rejected"
+	ErrHookFailed          = "hook_failed"
 )
 
 //{{ if isLast $key .Cols}}{{else}},{{end}}
 const (
-	templInsert      = `INSERT INTO {{.Table}} {{if not .Cols}} DEFAULT VALUES {{end}}  {{if .Cols}} ({{join .Cols ", "}}) VALUES {{.GetValues}} {{end}} {{if .RCols}} RETURNING {{join .RCols ", "}}{{end}};`
+	templInsert      = `INSERT INTO {{.Table}} {{if not .Cols}} DEFAULT VALUES {{end}}  {{if .Cols}} ({{join .Cols ", "}}) VALUES {{.GetValues}} {{end}}{{if .ConflictCols}} ON CONFLICT ({{join .ConflictCols ", "}}) DO UPDATE SET {{.GetConflictUpdates}}{{if .VersionCol}} WHERE {{.Table}}.{{.VersionCol}} = EXCLUDED.{{.VersionCol}}{{end}}{{end}} {{if .RCols}} RETURNING {{join .RCols ", "}}{{end}};`
 	templFixSequence = `SELECT setval('{{.Table}}_{{.Field}}_seq',(SELECT CAST(MAX("{{.Field}}") AS INT) FROM {{.Table}}), true);`
-	templSelect      = `SELECT {{join .Cols ", "}} FROM {{.From}}{{if .Where}} WHERE {{.Where}}{{end}}{{if .Order}} ORDER BY {{.Order}}{{end}}{{if .Limit}} LIMIT {{.Limit}}{{end}}{{if .Offset}} OFFSET {{.Offset}}{{end}}`
+	templSelect      = `SELECT {{join .Cols ", "}} FROM {{.From}}{{if .Where}} WHERE {{.Where}}{{end}}{{if .GroupBy}} GROUP BY {{join .GroupBy ", "}}{{end}}{{if .Order}} ORDER BY {{.Order}}{{end}}{{if .Limit}} LIMIT {{.Limit}}{{end}}{{if .Offset}} OFFSET {{.Offset}}{{end}}`
 	templDelete      = `DELETE FROM {{.Table}}{{if .Filters}} WHERE {{join .Filters " AND "}}{{end}}`
 	templUpdate      = `UPDATE {{.Table}} SET {{join .Values ","}}{{if .Filters}} WHERE {{join .Filters " AND "}}{{end}}{{if .Cols}} RETURNING {{join .Cols ", "}}{{end}}`
 )
@@ -51,12 +61,13 @@ var parsedTemplUpdate = template.Must(template.New("dml_update").Funcs(funcs).Pa
 
 //TODO: move SelectInfo to dml_info and implement constructor method NewSelectInfo with escaping
 type SelectInfo struct {
-	Cols   []string
-	From   string
-	Where  string
-	Order  string
-	Limit  string
-	Offset string
+	Cols    []string
+	From    string
+	Where   string
+	GroupBy []string
+	Order   string
+	Limit   string
+	Offset  string
 }
 
 func (selectInfo *SelectInfo) sql(sql *bytes.Buffer) error {
@@ -71,12 +82,16 @@ func NewSelectInfo(objectMeta *Meta, fields []*FieldDescription, filterKeys []st
 		}
 		whereExpression += dml_info.EscapeColumn(key) + "=$" + strconv.Itoa(i+1)
 	}
-	return &SelectInfo{From: GetTableName(objectMeta.Name), Cols: dml_info.EscapeColumns(fieldsToCols(fields, "")), Where: whereExpression}
+	return &SelectInfo{From: GetTableName(objectMeta.Name), Cols: fieldsToCols(fields, ""), Where: whereExpression}
 }
 
 func getFieldsColumnsNames(fields []*FieldDescription) []string {
 	names := make([]string, 0)
 	for _, field := range fields {
+		//rollups and virtual fields have no columns
+		if !field.hasColumn() {
+			continue
+		}
 		switch field.Type {
 		case description.FieldTypeGeneric:
 			names = append(names, GetGenericFieldTypeColumnName(field.Name))
@@ -90,13 +105,13 @@ func getFieldsColumnsNames(fields []*FieldDescription) []string {
 
 func newFieldValue(f *FieldDescription, isOptional bool) (interface{}, error) {
 	switch f.Type {
-	case description.FieldTypeString, description.FieldTypeDate, description.FieldTypeDateTime, description.FieldTypeTime, description.FieldTypeEnum:
+	case description.FieldTypeString, description.FieldTypeDate, description.FieldTypeDateTime, description.FieldTypeTime, description.FieldTypeEnum, description.FieldTypeUUID:
 		if isOptional {
 			return new(sql.NullString), nil
 		} else {
 			return new(string), nil
 		}
-	case description.FieldTypeNumber:
+	case description.FieldTypeNumber, description.FieldTypeInteger, description.FieldTypeDecimal:
 		if isOptional {
 			return new(sql.NullFloat64), nil
 		} else {
@@ -108,6 +123,13 @@ func newFieldValue(f *FieldDescription, isOptional bool) (interface{}, error) {
 		} else {
 			return new(bool), nil
 		}
+	case description.FieldTypeJSON:
+		//document is decoded after scanning
+		return new(sql.NullString), nil
+	case description.FieldTypeStringArray:
+		return new(pq.StringArray), nil
+	case description.FieldTypeNumberArray:
+		return new(pq.Float64Array), nil
 	case description.FieldTypeObject, description.FieldTypeArray:
 		if f.LinkType == description.LinkTypeInner {
 			return newFieldValue(f.LinkMeta.Key, f.Optional)
@@ -180,12 +202,7 @@ func (s *Stmt) Query(binds []interface{}) (*Rows, error) {
 				"23503":
 				return nil, errors.NewValidationError(ErrValidation, err.Error(), nil) // Return data here
 			case "23505":
-				re := regexp.MustCompile(`\(([^)]+)\)=\(([^)]+)\)`)
-				parts := re.FindStringSubmatch(err.Detail)[1:]
-				data := make(map[string]interface{})
-				data[parts[0]] = parts[1]
-
-				return nil, errors.NewValidationError(ErrValueDuplication, err.Error(), data) // Return data here
+				return nil, newValueDuplicationError(err)
 			default:
 				return nil, errors.NewFatalError(ErrDMLFailed, err.Error(), nil)
 			}
@@ -197,6 +214,29 @@ func (s *Stmt) Query(binds []interface{}) (*Rows, error) {
 	return &Rows{rows}, nil
 }
 
+var duplicatedValuesRe = regexp.MustCompile(`\(([^)]+)\)=\(([^)]+)\)`)
+
+//Validation error of the unique constraint violation, data contains duplicated values by field names.
+//Fields of the constraint across several columns are listed in the message
+func newValueDuplicationError(err *pgconn.PgError) *errors.ServerError {
+	parts := duplicatedValuesRe.FindStringSubmatch(err.Detail)
+	if len(parts) < 3 {
+		return errors.NewValidationError(ErrValueDuplication, err.Error(), nil)
+	}
+	data := make(map[string]interface{})
+	fields := strings.Split(parts[1], ", ")
+	values := strings.Split(parts[2], ", ")
+	if len(fields) == 1 || len(fields) != len(values) {
+		data[parts[1]] = parts[2]
+		return errors.NewValidationError(ErrValueDuplication, err.Error(), data)
+	}
+	for i := range fields {
+		fields[i] = strings.Trim(fields[i], `"`)
+		data[fields[i]] = values[i]
+	}
+	return errors.NewValidationError(ErrValueDuplication, fmt.Sprintf("Values of the fields %s must be unique together", strings.Join(fields, ", ")), data)
+}
+
 func (s *Stmt) Scalar(receiver interface{}, binds []interface{}) error {
 	if rows, err := s.Query(binds); err != nil {
 		return err
@@ -312,13 +352,27 @@ func increaseCasVal(v interface{}) interface{} {
 	return cas + 1
 }
 
+//Returns escaped columns of the fields prefixed with the alias if it is given, rollups are selected with subqueries
 func fieldsToCols(fields []*FieldDescription, alias string) []string {
-	columns := getFieldsColumnsNames(fields)
-	for i, column := range columns {
-		if alias != "" {
-			column = fmt.Sprintf("%s.\"%s\"", alias, column)
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Rollup != nil {
+			tableAlias := alias
+			if tableAlias == "" {
+				tableAlias = GetTableName(field.Meta.Name)
+			}
+			if value, err := rollupSql(field, tableAlias); err == nil {
+				columns = append(columns, fmt.Sprintf("%s AS \"%s\"", value, field.Name))
+			}
+			continue
+		}
+		for _, column := range getFieldsColumnsNames([]*FieldDescription{field}) {
+			if alias != "" {
+				columns = append(columns, fmt.Sprintf("%s.\"%s\"", alias, column))
+			} else {
+				columns = append(columns, dml_info.EscapeColumn(column))
+			}
 		}
-		columns[i] = column
 	}
 	return columns
 }
@@ -0,0 +1,203 @@
+package object
+
+import (
+	"custodian/server/auth"
+	errors2 "custodian/server/errors"
+	"custodian/server/object/description"
+	"custodian/server/object/errors"
+	"custodian/server/transactions"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	rqlParser "github.com/Q-CIS-DEV/go-rql-parser"
+)
+
+const (
+	RecordHistoryMetaName           = "__custodian_records_history__"
+	SQL_CREATE_RECORD_HISTORY_TABLE = `CREATE TABLE IF NOT EXISTS "o___custodian_records_history__" ("id" SERIAL, "object" text NOT NULL, "record" text NOT NULL, "action" text NOT NULL, "user" integer NULL, "created" timestamp with time zone NOT NULL DEFAULT now(), "fields" text NOT NULL, "previous" text NOT NULL, "current" text NOT NULL, PRIMARY KEY ("id"));`
+	SQL_CREATE_RECORD_HISTORY_INDEX = `CREATE INDEX IF NOT EXISTS "o___custodian_records_history___record" ON "o___custodian_records_history__" ("object", "record");`
+	SQL_INSERT_RECORD_HISTORY_ENTRY = `INSERT INTO "o___custodian_records_history__" ("object", "record", "action", "user", "fields", "previous", "current") VALUES ($1, $2, $3, $4, $5, $6, $7);`
+)
+
+type recordHistoryEntry struct {
+	object   string
+	record   string
+	action   string
+	user     interface{}
+	fields   []string
+	previous map[string]interface{}
+	current  map[string]interface{}
+}
+
+//Write history entries for notifications of objects with enabled history, each notification is recorded only once.
+//Entries are written in the transaction of the records, so that the change fails if its history can't be written
+func (processor *Processor) recordHistory(notificationPool *RecordSetNotificationPool, method description.Method, user auth.User) error {
+	entries := make([]*recordHistoryEntry, 0)
+	for _, notification := range notificationPool.Notifications() {
+		if notification.historyRecorded || !notification.recordSet.Meta.History || !notification.ShouldBeProcessed(method) {
+			continue
+		}
+		notification.historyRecorded = true
+		entries = append(entries, buildRecordHistoryEntries(notification, user)...)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	operation := func(dbTransaction transactions.DbTransaction) error {
+		stmt, err := dbTransaction.(*PgTransaction).Prepare(SQL_INSERT_RECORD_HISTORY_ENTRY)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, entry := range entries {
+			previous, _ := json.Marshal(entry.previous)
+			current, _ := json.Marshal(entry.current)
+			if _, err := stmt.Exec(entry.object, entry.record, entry.action, entry.user, strings.Join(entry.fields, ","), string(previous), string(current)); err != nil {
+				return errors2.NewFatalError(ErrDMLFailed, err.Error(), nil)
+			}
+		}
+		return nil
+	}
+
+	dbTransaction, err := processor.transactionManager.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	if err := dbTransaction.Execute([]transactions.Operation{operation}); err != nil {
+		dbTransaction.Rollback()
+		return err
+	}
+	return dbTransaction.Commit()
+}
+
+//Build history entry for each record of the notification
+func buildRecordHistoryEntries(notification *RecordSetNotification, user auth.User) []*recordHistoryEntry {
+	entries := make([]*recordHistoryEntry, 0)
+	meta := notification.recordSet.Meta
+	var userId interface{}
+	if user.Id != 0 {
+		userId = user.Id
+	}
+
+	count := len(notification.previousRecords)
+	if len(notification.currentRecords) > count {
+		count = len(notification.currentRecords)
+	}
+	for i := 0; i < count; i++ {
+		previous := map[string]interface{}{}
+		current := map[string]interface{}{}
+		var pk interface{}
+		if i < len(notification.previousRecords) && notification.previousRecords[i] != nil {
+			previous = adaptRecordData(notification.previousRecords[i].GetData())
+			pk = notification.previousRecords[i].Pk()
+		}
+		if i < len(notification.currentRecords) && notification.currentRecords[i] != nil {
+			current = adaptRecordData(notification.currentRecords[i].GetData())
+			pk = notification.currentRecords[i].Pk()
+		}
+		if pk == nil {
+			continue
+		}
+		pkAsString, _ := meta.Key.ValueAsString(pk)
+
+		fields := make([]string, 0)
+		switch notification.Method {
+		case description.MethodUpdate:
+			//keep only values which were actually changed
+			changedPrevious := map[string]interface{}{}
+			changedCurrent := map[string]interface{}{}
+			for key, value := range current {
+				if !historyValuesEqual(previous[key], value) {
+					fields = append(fields, key)
+					changedPrevious[key] = previous[key]
+					changedCurrent[key] = value
+				}
+			}
+			previous, current = changedPrevious, changedCurrent
+		case description.MethodCreate:
+			for key := range current {
+				fields = append(fields, key)
+			}
+		default:
+			for key := range previous {
+				fields = append(fields, key)
+			}
+		}
+		if notification.Method == description.MethodUpdate && len(fields) == 0 {
+			continue
+		}
+		sort.Strings(fields)
+
+		entries = append(entries, &recordHistoryEntry{
+			object:   meta.Name,
+			record:   pkAsString,
+			action:   notification.Method.AsString(),
+			user:     userId,
+			fields:   fields,
+			previous: previous,
+			current:  current,
+		})
+	}
+	return entries
+}
+
+func historyValuesEqual(a, b interface{}) bool {
+	aJson, _ := json.Marshal(a)
+	bJson, _ := json.Marshal(b)
+	return string(aJson) == string(bJson)
+}
+
+//Get history entries of the record, filter is applied along with the record condition
+func (processor *Processor) GetHistory(objectName, key string, filter string) (int, []map[string]interface{}, error) {
+	objectMeta, err := processor.GetMeta(objectName)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !objectMeta.History {
+		return 0, nil, errors2.NewValidationError(ErrHistoryDisabled, fmt.Sprintf("History is not enabled for object '%s'", objectName), nil)
+	}
+
+	historyStore := NewStore(NewDbMetaDescriptionSyncer(processor.transactionManager), processor.metaStore.transactionManager)
+	historyProcessor, _ := NewProcessor(historyStore, processor.transactionManager)
+
+	historyFilter := filter
+	if filter == "" {
+		historyFilter = "sort(-id)"
+	} else if !strings.Contains(filter, "sort(") {
+		historyFilter += ",sort(-id)"
+	}
+	rqlNode, err := rqlParser.NewParser().Parse(historyFilter)
+	if err != nil {
+		return 0, nil, errors2.NewValidationError(errors.ErrWrongRQL, err.Error(), nil)
+	}
+	//the record condition is added to the parsed filter, so that the key is never interpreted as RQL
+	conditions := []*rqlParser.RqlNode{newEqRqlNode("object", objectName), newEqRqlNode("record", key)}
+	if rqlNode.Node != nil {
+		conditions = append(conditions, rqlNode.Node)
+	}
+	rqlNode.Node = newRqlRootNode(conditions...).Node
+	total, records, err := historyProcessor.getBulkByRql(RecordHistoryMetaName, rqlNode, nil, nil, nil, 1, true)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		entry := record.GetData()
+		var previous, current map[string]interface{}
+		json.Unmarshal([]byte(fmt.Sprintf("%v", entry["previous"])), &previous)
+		json.Unmarshal([]byte(fmt.Sprintf("%v", entry["current"])), &current)
+		entry["previous"] = previous
+		entry["current"] = current
+		fields := make([]string, 0)
+		if fieldsString, ok := entry["fields"].(string); ok && fieldsString != "" {
+			fields = strings.Split(fieldsString, ",")
+		}
+		entry["fields"] = fields
+		result = append(result, entry)
+	}
+	return total, result, nil
+}
@@ -0,0 +1,146 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Record history", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	havingObjectWithHistory := func(history bool) *object.Meta {
+		metaDescription := description.MetaDescription{
+			Name:    utils.RandomString(8),
+			Key:     "id",
+			Cas:     false,
+			History: history,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name:     "name",
+					Type:     description.FieldTypeString,
+					Optional: true,
+				},
+				{
+					Name:     "status",
+					Type:     description.FieldTypeString,
+					Optional: true,
+				},
+			},
+		}
+		metaObj, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(metaObj)
+		Expect(err).To(BeNil())
+		return metaObj
+	}
+
+	It("Records create, update and remove of the record", func() {
+		metaObj := havingObjectWithHistory(true)
+		user := auth.User{Id: 5}
+
+		record, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"name": "first", "status": "new"}, user)
+		Expect(err).To(BeNil())
+		key := record.PkAsString()
+
+		_, err = dataProcessor.UpdateRecord(metaObj.Name, key, map[string]interface{}{"name": "second", "status": "new"}, user)
+		Expect(err).To(BeNil())
+
+		_, err = dataProcessor.RemoveRecord(metaObj.Name, key, user)
+		Expect(err).To(BeNil())
+
+		total, entries, err := dataProcessor.GetHistory(metaObj.Name, key, "")
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(3))
+		Expect(entries[0]["action"]).To(Equal(description.MethodRemove.AsString()))
+		Expect(entries[2]["action"]).To(Equal(description.MethodCreate.AsString()))
+		Expect(entries[1]["action"]).To(Equal(description.MethodUpdate.AsString()))
+		Expect(entries[1]["user"]).To(Equal(float64(5)))
+		Expect(entries[1]["fields"]).To(Equal([]string{"name"}))
+		Expect(entries[1]["previous"]).To(Equal(map[string]interface{}{"name": "first"}))
+		Expect(entries[1]["current"]).To(Equal(map[string]interface{}{"name": "second"}))
+
+		_, entries, err = dataProcessor.GetHistory(metaObj.Name, key, "eq(action,update)")
+		Expect(err).To(BeNil())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	It("Retrieves history of the record having RQL special characters in the key", func() {
+		metaDescription := description.MetaDescription{
+			Name:    utils.RandomString(8),
+			Key:     "code",
+			Cas:     false,
+			History: true,
+			Fields: []description.Field{
+				{Name: "code", Type: description.FieldTypeString},
+				{Name: "name", Type: description.FieldTypeString, Optional: true},
+			},
+		}
+		metaObj, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(metaObj)
+		Expect(err).To(BeNil())
+
+		_, err = dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"code": "a"}, auth.User{})
+		Expect(err).To(BeNil())
+		_, err = dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"code": "a),eq(action,create"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		total, entries, err := dataProcessor.GetHistory(metaObj.Name, "a),eq(action,create", "")
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(1))
+		Expect(entries[0]["record"]).To(Equal("a),eq(action,create"))
+
+		total, _, err = dataProcessor.GetHistory(metaObj.Name, "b),or(ne(record,b)", "")
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(0))
+	})
+
+	It("Does not update the record if its history can't be written", func() {
+		metaObj := havingObjectWithHistory(true)
+		record, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec(`ALTER TABLE "o___custodian_records_history__" RENAME TO "o___custodian_records_history___moved"`)
+		Expect(err).To(BeNil())
+		defer db.Exec(`ALTER TABLE "o___custodian_records_history___moved" RENAME TO "o___custodian_records_history__"`)
+
+		_, err = dataProcessor.UpdateRecord(metaObj.Name, record.PkAsString(), map[string]interface{}{"name": "second"}, auth.User{})
+		Expect(err).NotTo(BeNil())
+
+		record, err = dataProcessor.Get(metaObj.Name, record.PkAsString(), nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(record.Data["name"]).To(Equal("first"))
+	})
+
+	It("Cannot retrieve history of the object with disabled history", func() {
+		metaObj := havingObjectWithHistory(false)
+
+		_, _, err := dataProcessor.GetHistory(metaObj.Name, "1", "")
+		Expect(err).NotTo(BeNil())
+	})
+})
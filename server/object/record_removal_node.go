@@ -11,6 +11,8 @@ type RecordRemovalNode struct {
 	LinkField        *FieldDescription
 	Record           *Record
 	OnDeleteStrategy *description.OnDeleteStrategy
+	//record is marked as deleted instead of being removed
+	SoftDelete bool
 }
 
 func NewRecordRemovalNode(record *Record, onDeleteStrategy *description.OnDeleteStrategy, parent *RecordRemovalNode, linkField *FieldDescription) *RecordRemovalNode {
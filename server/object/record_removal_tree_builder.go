@@ -6,6 +6,8 @@ import (
 	"custodian/server/transactions"
 
 	"fmt"
+
+	rqlParser "github.com/Q-CIS-DEV/go-rql-parser"
 )
 
 type RecordRemovalTreeBuilder struct {
@@ -14,6 +16,7 @@ type RecordRemovalTreeBuilder struct {
 //Extract record`s full tree consisting of depending records, which would be affected by root record removal
 func (r *RecordRemovalTreeBuilder) Extract(record *Record, processor *Processor, dbTransaction transactions.DbTransaction) (*RecordRemovalNode, error) {
 	recordTree := NewRecordRemovalNode(record, nil, nil, nil)
+	recordTree.SoftDelete = record.Meta.SoftDelete
 	if err := r.fillWithDependingRecords(recordTree, processor, dbTransaction); err != nil {
 		return nil, err
 	} else {
@@ -21,12 +24,12 @@ func (r *RecordRemovalTreeBuilder) Extract(record *Record, processor *Processor,
 	}
 }
 
-func (r *RecordRemovalTreeBuilder) makeFilter(innerField *FieldDescription, ownerId string) string {
-	return fmt.Sprintf("eq(%s,%s)", innerField.Name, ownerId)
+func (r *RecordRemovalTreeBuilder) makeFilter(innerField *FieldDescription, ownerId string) *rqlParser.RqlRootNode {
+	return newRqlRootNode(newEqRqlNode(innerField.Name, ownerId))
 }
 
-func (r *RecordRemovalTreeBuilder) makeGenericFilter(innerField *FieldDescription, ownerObjectName string, ownerId string) string {
-	return fmt.Sprintf("eq(%s.%s.%s,%s)", innerField.Name, ownerObjectName, innerField.LinkMetaList.GetByName(ownerObjectName).Key.Name, ownerId)
+func (r *RecordRemovalTreeBuilder) makeGenericFilter(innerField *FieldDescription, ownerObjectName string, ownerId string) *rqlParser.RqlRootNode {
+	return newRqlRootNode(newEqRqlNode(innerField.Name+"."+ownerObjectName+"."+innerField.LinkMetaList.GetByName(ownerObjectName).Key.Name, ownerId))
 }
 
 //iterate through record`s fields and process outer relations
@@ -39,13 +42,13 @@ func (r *RecordRemovalTreeBuilder) fillWithDependingRecords(recordNode *RecordRe
 			if err != nil {
 				return err
 			}
-			filter := ""
+			var filter *rqlParser.RqlRootNode
 			if field.Type == description.FieldTypeArray {
 				filter = r.makeFilter(field.OuterLinkField, pkAsString)
 			} else if field.Type == description.FieldTypeGeneric {
 				filter = r.makeGenericFilter(field.OuterLinkField, recordNode.Record.Meta.Name, pkAsString)
 			}
-			_, relatedRecords, err = processor.GetBulk(field.LinkMeta.Name, filter, nil, nil, 1, false)
+			_, relatedRecords, err = processor.getBulkByRql(field.LinkMeta.Name, filter, nil, nil, nil, 1, false)
 			if err != nil {
 				return err
 			}
@@ -59,6 +62,14 @@ func (r *RecordRemovalTreeBuilder) fillWithDependingRecords(recordNode *RecordRe
 						recordNode,
 						field.OuterLinkField,
 					)
+					//records depending on the soft-deleted one stay untouched, because it still exists, only
+					//cascaded records of soft-deletable objects are marked as deleted along with it
+					if recordNode.SoftDelete && *newRecordNode.OnDeleteStrategy != description.OnDeleteRestrict {
+						if *newRecordNode.OnDeleteStrategy != description.OnDeleteCascade || !field.LinkMeta.SoftDelete {
+							continue
+						}
+						newRecordNode.SoftDelete = true
+					}
 					switch *newRecordNode.OnDeleteStrategy {
 					case description.OnDeleteCascade:
 						if err := r.fillWithDependingRecords(newRecordNode, processor, dbTransaction); err != nil {
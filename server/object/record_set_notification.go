@@ -1,9 +1,12 @@
 package object
 
 import (
+	"custodian/logger"
 	"custodian/server/auth"
 	"custodian/server/object/description"
 	"custodian/utils"
+
+	"github.com/Q-CIS-DEV/go-rql-parser"
 )
 
 type RecordSetNotification struct {
@@ -13,6 +16,11 @@ type RecordSetNotification struct {
 	Method        description.Method
 	PreviousState map[int]*RecordSet
 	CurrentState  map[int]*RecordSet
+	//raw records' states are kept regardless of actions to be written to the record history
+	previousRecords []*Record
+	currentRecords  []*Record
+	historyRecorded bool
+	outboxWritten   bool
 }
 
 func NewRecordSetNotification(recordSet *RecordSet, isRoot bool, method description.Method) *RecordSetNotification {
@@ -28,10 +36,12 @@ func NewRecordSetNotification(recordSet *RecordSet, isRoot bool, method descript
 }
 
 func (notification *RecordSetNotification) CapturePreviousState(objects []*Record) {
+	notification.previousRecords = objects
 	notification.captureState(notification.PreviousState, objects)
 }
 
 func (notification *RecordSetNotification) CaptureCurrentState(objects []*Record) {
+	notification.currentRecords = objects
 	notification.captureState(notification.CurrentState, objects)
 }
 
@@ -39,21 +49,30 @@ func (notification *RecordSetNotification) ShouldBeProcessed(method description.
 	return method.AsString() == notification.Method.AsString()
 }
 
-//Build notification object for each record in recordSet for given action
-func (notification *RecordSetNotification) BuildNotificationsData(previousState *RecordSet, currentState *RecordSet, user auth.User) []map[string]interface{} {
+//Build notification object for each record in recordSet for given action, records not triggering the action are skipped.
+//Records changed along with the root one are notified only by actions active if not root, whatever the protocol is
+func (notification *RecordSetNotification) BuildNotificationsData(action *description.Action, user auth.User) []map[string]interface{} {
+	previousState := notification.PreviousState[action.Id()]
+	currentState := notification.CurrentState[action.Id()]
 	notifications := make([]map[string]interface{}, 0)
-	for i := range previousState.Records {
-		var previousStateData map[string]interface{}
-		var currentStateData map[string]interface{}
-		if previousState.Records[i] != nil {
+	if !notification.isRoot && !action.ActiveIfNotRoot {
+		return notifications
+	}
+	count := len(previousState.Records)
+	if len(currentState.Records) > count {
+		count = len(currentState.Records)
+	}
+	for i := 0; i < count; i++ {
+		if action.HasTriggers() && !notification.triggers(action, i) {
+			continue
+		}
+		previousStateData := map[string]interface{}{}
+		currentStateData := map[string]interface{}{}
+		if i < len(previousState.Records) && previousState.Records[i] != nil {
 			previousStateData = previousState.Records[i].Data
-		} else {
-			previousStateData = map[string]interface{}{}
 		}
-		if currentState.Records[i] != nil {
+		if i < len(currentState.Records) && currentState.Records[i] != nil {
 			currentStateData = currentState.Records[i].Data
-		} else {
-			currentStateData = map[string]interface{}{}
 		}
 		notificationData := make(map[string]interface{})
 		notificationData["action"] = notification.Method.AsString()
@@ -66,25 +85,71 @@ func (notification *RecordSetNotification) BuildNotificationsData(previousState
 	return notifications
 }
 
+//Check if the i-th record triggers the action
+func (notification *RecordSetNotification) triggers(action *description.Action, i int) bool {
+	previous := map[string]interface{}{}
+	if i < len(notification.previousRecords) && notification.previousRecords[i] != nil {
+		previous = notification.previousRecords[i].Data
+	}
+	current := utils.CloneMap(previous)
+	if notification.Method != description.MethodRemove && i < len(notification.currentRecords) && notification.currentRecords[i] != nil {
+		for key, value := range notification.currentRecords[i].Data {
+			current[key] = value
+		}
+	}
+	return actionTriggered(action, notification.recordSet.Meta, notification.Method, previous, current)
+}
+
+//Check if the record matches the condition of the action and has any of its changed fields modified.
+//The condition is evaluated against the record state after the change, or against the removed record;
+//the state before the change is available with the "previous." prefix. Removal modifies all fields
+func actionTriggered(action *description.Action, meta *Meta, method description.Method, previous map[string]interface{}, current map[string]interface{}) bool {
+	if len(action.ChangedFields) > 0 && method != description.MethodRemove {
+		previousValues, currentValues := adaptRecordData(previous), adaptRecordData(current)
+		changed := false
+		for _, fieldName := range action.ChangedFields {
+			if !historyValuesEqual(previousValues[fieldName], currentValues[fieldName]) {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return false
+		}
+	}
+
+	if action.Condition != "" {
+		rqlRoot, err := rqlParser.NewParser().Parse(action.Condition)
+		if err != nil || rqlRoot.Node == nil {
+			logger.Error("Action '%s' of '%s' has wrong condition '%s'", action.Name, meta.Name, action.Condition)
+			return false
+		}
+		matches, err := evaluateCheckNode(rqlRoot.Node, meta, current, previous)
+		if err != nil {
+			logger.Error("Failed to evaluate condition of action '%s' of '%s': %s", action.Name, meta.Name, err.Error())
+			return false
+		}
+		return matches
+	}
+	return true
+}
+
 func (notification *RecordSetNotification) captureState(state map[int]*RecordSet, objects []*Record) {
-	//capture state if recordSet has PKs defined, set empty map otherwise, because records cannot be retrieved
+	//states keep indexes of the records in recordSet, so that previous and current states of the record match,
+	//the state of the absent record is nil
+	count := len(notification.recordSet.Records)
+	if len(objects) > count {
+		count = len(objects)
+	}
 	for _, action := range notification.Actions {
-		state[action.Id()] = &RecordSet{Meta: notification.recordSet.Meta, Records: make([]*Record, 0)}
+		state[action.Id()] = &RecordSet{Meta: notification.recordSet.Meta, Records: make([]*Record, count)}
 		if action.Method.AsString() == notification.Method.AsString() {
-
-			for _, obj := range objects {
+			for i, obj := range objects {
 				if obj != nil {
-					state[action.Id()].Records = append(
-						state[action.Id()].Records,
-						NewRecord(state[action.Id()].Meta, notification.buildRecordStateObject(obj, action), obj.processor),
-					)
+					state[action.Id()].Records[i] = NewRecord(state[action.Id()].Meta, notification.buildRecordStateObject(obj, action), obj.processor)
 				}
 			}
 		}
-		//fill DataSet with empty values
-		if len(state[action.Id()].Records) == 0 {
-			state[action.Id()].Records = make([]*Record, len(notification.recordSet.Records))
-		}
 	}
 }
 
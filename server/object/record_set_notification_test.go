@@ -219,6 +219,115 @@ var _ = Describe("Data 101", func() {
 			Expect(current["a_last_name"]).To(Equal("Ivanova"))
 		})
 
+		It("fires action only for records matching its condition and changed fields", func() {
+			updateAction := []description.Action{
+				{
+					Method:          description.MethodUpdate,
+					Protocol:        noti.TEST,
+					Args:            []string{"http://example.com"},
+					ActiveIfNotRoot: true,
+					Condition:       "and(eq(last_name,Ivanova),eq(previous.last_name,Petrova))",
+					ChangedFields:   []string{"last_name"},
+				},
+			}
+
+			havingObjectB()
+			havingObjectA(updateAction)
+			havingBRecord()
+			havingARecord(bRecord.Pk().(float64))
+
+			record, err := dataProcessor.UpdateRecord(testObjAName, aRecord.PkAsString(), map[string]interface{}{
+				"first_name": "Vera",
+			}, auth.User{})
+			Expect(err).To(BeNil())
+			notifier := record.Meta.Actions[0].Notifier.(*noti.TestNotifier)
+			Consistently(notifier.Events).Should(HaveLen(0))
+
+			_, err = dataProcessor.UpdateRecord(testObjAName, aRecord.PkAsString(), map[string]interface{}{
+				"last_name": "Ivanova",
+			}, auth.User{})
+			Expect(err).To(BeNil())
+
+			var received *noti.Event
+			Eventually(notifier.Events).Should(Receive(&received))
+			Expect(received.Obj()["current"].(map[string]interface{})["last_name"]).To(Equal("Ivanova"))
+
+			_, err = dataProcessor.UpdateRecord(testObjAName, aRecord.PkAsString(), map[string]interface{}{
+				"last_name": "Ivanova",
+			}, auth.User{})
+			Expect(err).To(BeNil())
+			Consistently(notifier.Events).Should(HaveLen(0))
+		})
+
+		It("checks the condition against states of the same record in the mixed set", func() {
+			updateAction := []description.Action{
+				{
+					Method:          description.MethodUpdate,
+					Protocol:        noti.TEST,
+					Args:            []string{"http://example.com"},
+					ActiveIfNotRoot: true,
+					Condition:       "eq(last_name,Ivanova)",
+				},
+			}
+
+			havingObjectB()
+			havingObjectA(updateAction)
+			aMetaObj, _, err := metaStore.Get(testObjAName, true)
+			Expect(err).To(BeNil())
+
+			newRecord := func(id float64, lastName string) *object.Record {
+				return object.NewRecord(aMetaObj, map[string]interface{}{"id": id, "last_name": lastName}, dataProcessor)
+			}
+			recordSet := object.RecordSet{
+				Meta:    aMetaObj,
+				Records: []*object.Record{newRecord(1, "Petrova"), newRecord(2, "Ivanova"), newRecord(3, "Ivanova")},
+			}
+			recordSetNotification := object.NewRecordSetNotification(&recordSet, true, description.MethodUpdate)
+			//the previous state of the second record is absent
+			recordSetNotification.CapturePreviousState([]*object.Record{newRecord(1, "Sidorova"), nil, newRecord(3, "Smirnova")})
+			recordSetNotification.CaptureCurrentState(recordSet.Records)
+
+			notifications := recordSetNotification.BuildNotificationsData(aMetaObj.Actions[0], auth.User{})
+			Expect(notifications).To(HaveLen(2))
+			Expect(notifications[0]["previous"]).To(BeEmpty())
+			Expect(notifications[0]["current"].(map[string]interface{})["id"]).To(Equal(2.0))
+			Expect(notifications[1]["previous"].(map[string]interface{})["last_name"]).To(Equal("Smirnova"))
+			Expect(notifications[1]["current"].(map[string]interface{})["id"]).To(Equal(3.0))
+		})
+
+		It("notifies about records changed along with the root one only by actions active if not root", func() {
+			updateActions := []description.Action{
+				{
+					Method:          description.MethodUpdate,
+					Protocol:        noti.TEST,
+					Args:            []string{"http://example.com"},
+					ActiveIfNotRoot: false,
+				},
+				{
+					Method:          description.MethodUpdate,
+					Protocol:        noti.TEST,
+					Args:            []string{"http://example.com"},
+					ActiveIfNotRoot: true,
+				},
+			}
+
+			havingObjectB()
+			havingObjectA(updateActions)
+			aMetaObj, _, err := metaStore.Get(testObjAName, true)
+			Expect(err).To(BeNil())
+
+			recordSet := object.RecordSet{
+				Meta:    aMetaObj,
+				Records: []*object.Record{object.NewRecord(aMetaObj, map[string]interface{}{"id": 1.0, "last_name": "Ivanova"}, dataProcessor)},
+			}
+			recordSetNotification := object.NewRecordSetNotification(&recordSet, false, description.MethodUpdate)
+			recordSetNotification.CapturePreviousState(recordSet.Records)
+			recordSetNotification.CaptureCurrentState(recordSet.Records)
+
+			Expect(recordSetNotification.BuildNotificationsData(aMetaObj.Actions[0], auth.User{})).To(BeEmpty())
+			Expect(recordSetNotification.BuildNotificationsData(aMetaObj.Actions[1], auth.User{})).To(HaveLen(1))
+		})
+
 		It("can capture state two actions", func() {
 			actions := []description.Action{
 				{
@@ -414,4 +414,96 @@ var _ = Describe("RecordSetOperations removal", func() {
 		//check removed data tree
 		Expect(removedData).To(Not(BeNil()))
 	})
+
+	It("Can soft delete and restore record with cascade relation", func() {
+		testObjAName := utils.RandomString(8)
+		testObjBName := utils.RandomString(8)
+
+		aMetaDescription := description.MetaDescription{
+			Name:       testObjAName,
+			Key:        "id",
+			Cas:        false,
+			SoftDelete: true,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+			},
+		}
+		aMetaObj, err := metaStore.NewMeta(&aMetaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(aMetaObj)
+		Expect(err).To(BeNil())
+
+		bMetaDescription := description.MetaDescription{
+			Name:       testObjBName,
+			Key:        "id",
+			Cas:        false,
+			SoftDelete: true,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name:     testObjAName,
+					Type:     description.FieldTypeObject,
+					LinkType: description.LinkTypeInner,
+					LinkMeta: testObjAName,
+					OnDelete: description.OnDeleteCascade.ToVerbose(),
+				},
+			},
+		}
+		bMetaObj, err := metaStore.NewMeta(&bMetaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(bMetaObj)
+		Expect(err).To(BeNil())
+
+		aRecord, err := dataProcessor.CreateRecord(aMetaObj.Name, map[string]interface{}{}, auth.User{})
+		Expect(err).To(BeNil())
+		bRecord, err := dataProcessor.CreateRecord(bMetaObj.Name, map[string]interface{}{testObjAName: aRecord.Data["id"]}, auth.User{})
+		Expect(err).To(BeNil())
+		aKey, _ := aMetaObj.Key.ValueAsString(aRecord.Data["id"])
+		bKey, _ := bMetaObj.Key.ValueAsString(bRecord.Data["id"])
+
+		_, err = dataProcessor.RemoveRecord(aMetaObj.Name, aKey, auth.User{})
+		Expect(err).To(BeNil())
+
+		//check both records are hidden
+		record, _ := dataProcessor.Get(aMetaObj.Name, aKey, nil, nil, 1, false)
+		Expect(record).To(BeNil())
+		record, _ = dataProcessor.Get(bMetaObj.Name, bKey, nil, nil, 1, false)
+		Expect(record).To(BeNil())
+
+		//check records are still available with explicit flag
+		_, records, err := dataProcessor.GetBulk(bMetaObj.Name, "with_deleted()", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].Data[description.SoftDeleteFieldName]).NotTo(BeNil())
+		record, err = dataProcessor.GetWithDeleted(bMetaObj.Name, bKey, nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(record).NotTo(BeNil())
+
+		//the key is not a part of the filter
+		_, err = dataProcessor.RestoreRecord(aMetaObj.Name, aKey+"),eq(id,"+aKey, auth.User{})
+		Expect(err).NotTo(BeNil())
+
+		//restore A record
+		restored, err := dataProcessor.RestoreRecord(aMetaObj.Name, aKey, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(restored).NotTo(BeNil())
+
+		record, _ = dataProcessor.Get(bMetaObj.Name, bKey, nil, nil, 1, false)
+		Expect(record).NotTo(BeNil())
+		Expect(record.Data[description.SoftDeleteFieldName]).To(BeNil())
+	})
 })
@@ -43,6 +43,18 @@ const (
 		WHERE c.oid = $1 AND c.oid = i.indrelid
 		      AND c.oid = a.attrelid AND a.attnum > 0 AND NOT a.attisdropped
 		      AND a.attnum = ANY(con.conkey)
+		      AND (con.contype = 'p' OR array_length(con.conkey, 1) = 1)
+	`
+
+	SQL_UNIQUE_TOGETHER string = `
+	    SELECT con.conname,
+		    array_to_string(ARRAY(SELECT a.attname
+			FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, n)
+			JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+			ORDER BY k.n), ',')
+		FROM pg_catalog.pg_constraint con
+		WHERE con.conrelid = $1 AND con.contype = 'u' AND array_length(con.conkey, 1) > 1
+		ORDER BY con.conname
 	`
 
 	SQL_OFK_TO_TABLE string = `
@@ -66,6 +78,20 @@ const (
 		WHERE c.conrelid = $1 AND c.contype = 'f';
 	`
 
+	SQL_INDEXES string = `
+	    SELECT ic.relname, i.indisunique, am.amname,
+		    array_to_string(ARRAY(SELECT a.attname
+			FROM unnest(i.indkey) WITH ORDINALITY AS k(attnum, n)
+			JOIN pg_catalog.pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+			ORDER BY k.n), ','),
+		    coalesce(pg_catalog.pg_get_expr(i.indpred, i.indrelid), '')
+		FROM pg_catalog.pg_index i
+		JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+		JOIN pg_catalog.pg_am am ON am.oid = ic.relam
+		WHERE i.indrelid = $1
+		  AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_constraint con WHERE con.conindid = i.indexrelid)
+	`
+
 	SQL_ENUM_VALUES string = `
 	SELECT value from (
 		SELECT type.typname AS name, string_agg(enum.enumlabel, '|') AS value
@@ -101,10 +127,16 @@ func (r *Reverser) Columns(cols *[]Column, pk *string) error {
 	var notnull, ok bool
 	var coltyp description.FieldType
 	var colsmap = make(map[string]int)
-	for i := 0; colrows.Next(); i++ {
+	var searchColumns = make([]string, 0)
+	for colrows.Next() {
 		if err = colrows.Scan(&column, &dbtype, &dbdefval, &notnull); err != nil {
 			return &DDLError{table: r.table, code: ErrInternal, msg: "parse column desc" + err.Error()}
 		}
+		//generated search columns are not the fields, they only mark the corresponding fields as searchable
+		if dbtype == "tsvector" && IsSearchFieldColumn(column) {
+			searchColumns = append(searchColumns, column)
+			continue
+		}
 		if coltyp, ok = dbTypeToFieldType(dbtype); !ok {
 			return &DDLError{table: r.table, code: ErrInternal, msg: fmt.Sprintf("Unknown database type: '%s'", dbtype)}
 		}
@@ -115,8 +147,15 @@ func (r *Reverser) Columns(cols *[]Column, pk *string) error {
 		}
 		//TODO: implement this: *cols = append(*cols, Column{Name: column, Typ: coltyp, Optional: len(defval) > 0 || !notnull, Defval: defval})
 		//when invariants` restrictions would be implemented (TB-116)
-		*cols = append(*cols, Column{Name: column, Typ: coltyp, Optional: !notnull, Defval: defval})
-		colsmap[column] = i
+		colsmap[column] = len(*cols)
+		precision, scale := dbTypePrecision(dbtype)
+		*cols = append(*cols, Column{Name: column, Typ: coltyp, Optional: !notnull, Defval: defval, Precision: precision, Scale: scale})
+	}
+
+	for _, searchColumn := range searchColumns {
+		if i, ok := colsmap[strings.TrimSuffix(searchColumn, searchFieldColumnSuffix)]; ok {
+			(*cols)[i].Searchable = true
+		}
 	}
 
 	for i, col := range *cols {
@@ -199,3 +238,52 @@ func (r *Reverser) Constraints(ifks *[]IFK, ofks *[]OFK) error {
 
 	return nil
 }
+
+//Revers declared indexes of the table, indexes which are not created for the object's description are omitted
+func (r *Reverser) Indexes(indexes *[]TableIndex) error {
+	indexrows, err := r.tx.Query(SQL_INDEXES, r.oid)
+	if err != nil {
+		return &DDLError{table: r.table, code: ErrInternal, msg: "select indexes: " + err.Error()}
+	}
+	defer indexrows.Close()
+
+	prefix := GetIndexName(r.table, "")
+	for indexrows.Next() {
+		var name, method, columns, predicate string
+		var unique bool
+		if err = indexrows.Scan(&name, &unique, &method, &columns, &predicate); err != nil {
+			return &DDLError{table: r.table, code: ErrInternal, msg: "parse indexes: " + err.Error()}
+		}
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		*indexes = append(*indexes, TableIndex{
+			Name:      strings.TrimPrefix(name, prefix),
+			Columns:   strings.Split(columns, ","),
+			Unique:    unique,
+			Method:    method,
+			Predicate: predicate,
+		})
+	}
+
+	return nil
+}
+
+//Revers unique constraints which consist of several columns
+func (r *Reverser) UniqueTogether(constraints *[]UniqueConstraint) error {
+	conrows, err := r.tx.Query(SQL_UNIQUE_TOGETHER, r.oid)
+	if err != nil {
+		return &DDLError{table: r.table, code: ErrInternal, msg: "select unique constraints: " + err.Error()}
+	}
+	defer conrows.Close()
+
+	for conrows.Next() {
+		var name, columns string
+		if err = conrows.Scan(&name, &columns); err != nil {
+			return &DDLError{table: r.table, code: ErrInternal, msg: "parse unique constraints: " + err.Error()}
+		}
+		*constraints = append(*constraints, UniqueConstraint{Name: name, Columns: strings.Split(columns, ",")})
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package object
+
+import (
+	"custodian/server/errors"
+	"custodian/server/object/description"
+	"fmt"
+)
+
+//Builds read-only fields for the rollups of the object, their values are selected with correlated subqueries
+func factoryRollupFields(objectMeta *Meta) error {
+	for i := range objectMeta.MetaDescription.Rollups {
+		rollup := &objectMeta.MetaDescription.Rollups[i]
+		link := objectMeta.FindField(rollup.Link)
+		if link == nil || link.LinkMeta == nil {
+			return errors.NewValidationError("new_meta", fmt.Sprintf("Rollup '%s' refers to unknown link '%s'", rollup.Name, rollup.Link), nil)
+		}
+		fieldType := description.FieldTypeNumber
+		if rollup.Field != "" {
+			target := link.LinkMeta.FindField(rollup.Field)
+			if target == nil || !target.IsSimple() || target.LinkType == description.LinkTypeInner {
+				return errors.NewValidationError("new_meta", fmt.Sprintf("Rollup '%s' refers to field '%s' which can't be aggregated", rollup.Name, rollup.Field), nil)
+			}
+			if (rollup.Func == description.RollupFuncSum || rollup.Func == description.RollupFuncAvg) && !target.Type.IsNumeric() {
+				return errors.NewValidationError("new_meta", fmt.Sprintf("Rollup '%s' can compute '%s' of number fields only", rollup.Name, rollup.Func), nil)
+			}
+			if rollup.Func == description.RollupFuncMin || rollup.Func == description.RollupFuncMax {
+				fieldType = target.Type
+			}
+		}
+		objectMeta.Fields = append(objectMeta.Fields, FieldDescription{
+			Field:        &description.Field{Name: rollup.Name, Type: fieldType, Optional: true},
+			Meta:         objectMeta,
+			LinkMetaList: &MetaList{},
+			Rollup:       rollup,
+		})
+	}
+	return nil
+}
+
+//Returns correlated subquery which computes the rollup for the record of the table with the given alias.
+//Sum is zero if there are no linked records, records marked as deleted are not aggregated
+func rollupSql(field *FieldDescription, alias string) (string, error) {
+	link := field.Meta.FindField(field.Rollup.Link)
+	if link == nil || link.LinkMeta == nil || link.OuterLinkField == nil {
+		return "", fmt.Errorf("rollup '%s' refers to unknown link '%s'", field.Name, field.Rollup.Link)
+	}
+	linkedAlias := alias + "_" + link.Name
+	value := "*"
+	if field.Rollup.Field != "" {
+		target := link.LinkMeta.FindField(field.Rollup.Field)
+		if target == nil {
+			return "", fmt.Errorf("object '%s' doesn't have '%s' field", link.LinkMeta.Name, field.Rollup.Field)
+		}
+		var err error
+		if value, err = fieldValueSql(target, linkedAlias); err != nil {
+			return "", err
+		}
+	}
+	aggregate := fmt.Sprintf("%s(%s)", field.Rollup.Func, value)
+	if field.Rollup.Func == description.RollupFuncSum {
+		aggregate = fmt.Sprintf("coalesce(%s, 0)", aggregate)
+	}
+	condition := fmt.Sprintf("%s.\"%s\" = %s.\"%s\"", linkedAlias, link.OuterLinkField.Name, alias, field.Meta.Key.Name)
+	if link.LinkMeta.SoftDelete {
+		condition += fmt.Sprintf(" AND %s.\"%s\" IS NULL", linkedAlias, description.SoftDeleteFieldName)
+	}
+	return fmt.Sprintf("(SELECT %s FROM %s %s WHERE %s)", aggregate, GetTableName(link.LinkMeta.Name), linkedAlias, condition), nil
+}
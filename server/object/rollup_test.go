@@ -0,0 +1,140 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rollup fields", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	orderName := utils.RandomString(8)
+	lineName := utils.RandomString(8)
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	havingOrderWithLinesRollups := func() *object.Meta {
+		orderDescription := description.MetaDescription{
+			Name: orderName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+			},
+		}
+		orderMeta, err := metaStore.NewMeta(&orderDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(orderMeta)
+		Expect(err).To(BeNil())
+
+		lineDescription := description.MetaDescription{
+			Name: lineName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name: "amount",
+					Type: description.FieldTypeNumber,
+				},
+				{
+					Name:     orderName,
+					Type:     description.FieldTypeObject,
+					LinkMeta: orderName,
+					LinkType: description.LinkTypeInner,
+					OnDelete: "cascade",
+				},
+			},
+		}
+		lineMeta, err := metaStore.NewMeta(&lineDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(lineMeta)
+		Expect(err).To(BeNil())
+
+		orderDescription.Fields = append(orderDescription.Fields, description.Field{
+			Name:           "lines",
+			Type:           description.FieldTypeArray,
+			LinkType:       description.LinkTypeOuter,
+			LinkMeta:       lineName,
+			OuterLinkField: orderName,
+			Optional:       true,
+		})
+		orderDescription.Rollups = []description.Rollup{
+			{Name: "lines_count", Link: "lines", Func: description.RollupFuncCount},
+			{Name: "lines_total", Link: "lines", Func: description.RollupFuncSum, Field: "amount"},
+		}
+		orderMeta, err = metaStore.NewMeta(&orderDescription)
+		Expect(err).To(BeNil())
+		_, err = metaStore.Update(orderMeta.Name, orderMeta, true, true)
+		Expect(err).To(BeNil())
+		return orderMeta
+	}
+
+	havingOrderWithLines := func(amounts ...float64) *object.Record {
+		order, err := dataProcessor.CreateRecord(orderName, map[string]interface{}{}, auth.User{})
+		Expect(err).To(BeNil())
+		for _, amount := range amounts {
+			_, err := dataProcessor.CreateRecord(lineName, map[string]interface{}{"amount": amount, orderName: order.Pk()}, auth.User{})
+			Expect(err).To(BeNil())
+		}
+		return order
+	}
+
+	It("Computes rollups of the record", func() {
+		havingOrderWithLinesRollups()
+		order := havingOrderWithLines(10, 15)
+		emptyOrder := havingOrderWithLines()
+
+		record, err := dataProcessor.Get(orderName, order.PkAsString(), nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(record.Data["lines_count"]).To(Equal(2.0))
+		Expect(record.Data["lines_total"]).To(Equal(25.0))
+
+		record, err = dataProcessor.Get(orderName, emptyOrder.PkAsString(), nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(record.Data["lines_count"]).To(Equal(0.0))
+		Expect(record.Data["lines_total"]).To(Equal(0.0))
+	})
+
+	It("Filters and sorts records by rollups", func() {
+		havingOrderWithLinesRollups()
+		havingOrderWithLines(1)
+		largeOrder := havingOrderWithLines(10, 15, 20)
+		havingOrderWithLines()
+
+		_, records, err := dataProcessor.GetBulk(orderName, "gt(lines_count,0),sort(-lines_total)", nil, nil, 1, true)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Pk()).To(Equal(largeOrder.Pk()))
+		Expect(records[0].Data["lines_total"]).To(Equal(45.0))
+	})
+})
@@ -4,6 +4,8 @@ import (
 	"custodian/server/errors"
 	"custodian/server/object/description"
 	"database/sql"
+	"encoding/json"
+	"github.com/lib/pq"
 	"reflect"
 	"time"
 )
@@ -15,7 +17,8 @@ type Rows struct {
 func (rows *Rows) getDefaultValues(fields []*FieldDescription) ([]interface{}, error) {
 	values := make([]interface{}, 0)
 	for _, field := range fields {
-		if newValue, err := newFieldValue(field, field.Optional); err != nil {
+		//generated column is NULL if any of the values it is computed from is NULL
+		if newValue, err := newFieldValue(field, field.Optional || field.IsComputed()); err != nil {
 			return nil, err
 		} else {
 			if castValues, ok := newValue.([]interface{}); ok {
@@ -88,9 +91,19 @@ func (rows *Rows) Parse(fields []*FieldDescription) ([]map[string]interface{}, e
 		}
 		return nil
 	}
+	//virtual fields have no values to scan, since they have no columns in the result
+	selectedFields := make([]*FieldDescription, 0, len(fields))
+	for _, field := range fields {
+		for _, columnName := range cols {
+			if fieldByColumnName(columnName) == field {
+				selectedFields = append(selectedFields, field)
+				break
+			}
+		}
+	}
 
 	for rows.Next() {
-		if values, err := rows.getDefaultValues(fields); err != nil {
+		if values, err := rows.getDefaultValues(selectedFields); err != nil {
 			return nil, err
 		} else {
 			if err = rows.Scan(values...); err != nil {
@@ -155,6 +168,17 @@ func (rows *Rows) Parse(fields []*FieldDescription) ([]map[string]interface{}, e
 						}
 						result[i][columnName] = t
 					}
+				} else if fieldByColumnName(columnName).Type == description.FieldTypeJSON {
+					value := values[j].(*sql.NullString)
+					if value.Valid {
+						var document interface{}
+						if err := json.Unmarshal([]byte(value.String), &document); err != nil {
+							return nil, errors.NewFatalError(ErrDMLFailed, err.Error(), nil)
+						}
+						result[i][columnName] = document
+					} else {
+						result[i][columnName] = nil
+					}
 				} else if fieldDescription := fieldByColumnName(columnName); fieldDescription.Type == description.FieldTypeGeneric {
 
 					//
@@ -226,6 +250,26 @@ func (rows *Rows) Parse(fields []*FieldDescription) ([]map[string]interface{}, e
 						} else {
 							result[i][columnName] = nil
 						}
+					case *pq.StringArray:
+						if *t != nil {
+							items := make([]interface{}, len(*t))
+							for k, item := range *t {
+								items[k] = item
+							}
+							result[i][columnName] = items
+						} else {
+							result[i][columnName] = nil
+						}
+					case *pq.Float64Array:
+						if *t != nil {
+							items := make([]interface{}, len(*t))
+							for k, item := range *t {
+								items[k] = item
+							}
+							result[i][columnName] = items
+						} else {
+							result[i][columnName] = nil
+						}
 					default:
 						return nil, errors.NewFatalError(ErrDMLFailed, "unknown reference type '%s'", reflect.TypeOf(values[j]).String())
 					}
@@ -3,15 +3,21 @@ package object
 import (
 	"bytes"
 	"custodian/logger"
+	errors2 "custodian/server/errors"
 	"custodian/server/object/description"
+	"custodian/server/object/errors"
 	"encoding/json"
 	"fmt"
 	"github.com/Q-CIS-DEV/go-rql-parser"
+	"github.com/lib/pq"
 	"strconv"
 	"strings"
 	"text/template"
 )
 
+//Sorting by this key orders records by the rank of full-text search conditions, eg: sort(-_rank)
+const SearchRankSortKey = "_rank"
+
 //https://doc.apsstandard.org/2.1/spec/rql/
 //It's important that using the join with distinct or group by is more worse then exists, see exmaple: https://danmartensen.svbtle.com/sql-performance-of-join-and-where-exists
 
@@ -22,6 +28,10 @@ const (
 	ErrRQLUnknownValueFunc = "unknown_value_function"
 	ErrRQLWrongFieldName   = "wrong_field_name"
 	ErrRQLWrongValue       = "wrong_value"
+	ErrRQLWrongAggregation = "wrong_aggregation"
+	ErrRQLWrongSearch      = "wrong_search"
+	ErrRQLWrongContains    = "wrong_contains"
+	ErrRQLWrongCursor      = "wrong_cursor"
 )
 
 type RqlError struct {
@@ -51,20 +61,88 @@ type SqlQuery struct {
 	Sort   string
 	Limit  string
 	Offset string
+	//keyset predicate selecting records which follow the cursor, it is not applied to the count of records
+	Keyset      string
+	KeysetBinds []interface{}
+	//fields of the sort order and the cursor to fill with their values, they are set if the pagination is requested
+	keysetFields []string
+	cursor       *cursor
+}
+
+//Returns the WHERE statement and binds of the requested page
+func (sq *SqlQuery) pageWhere() (string, []interface{}) {
+	if sq.Keyset == "" {
+		return sq.Where, sq.Binds
+	}
+	binds := append(append(make([]interface{}, 0, len(sq.Binds)+len(sq.KeysetBinds)), sq.Binds...), sq.KeysetBinds...)
+	if sq.Where == "" {
+		return sq.Keyset, binds
+	}
+	return "(" + sq.Where + ") AND " + sq.Keyset, binds
+}
+
+//Returns the cursor pointing to the last record of the page, it is empty if there are no more records to fetch
+func (sq *SqlQuery) nextCursor(records []map[string]interface{}) string {
+	limit, err := strconv.Atoi(sq.Limit)
+	if sq.cursor == nil || err != nil || limit == 0 || len(records) < limit {
+		return ""
+	}
+	last := records[len(records)-1]
+	values := make([]interface{}, len(sq.keysetFields))
+	for i, fieldName := range sq.keysetFields {
+		values[i] = last[fieldName]
+	}
+	return (&cursor{Sort: sq.cursor.Sort, Values: values}).encode()
+}
+
+type SqlAggregationQuery struct {
+	Cols    []string
+	Joins   []string
+	Where   string
+	Binds   []interface{}
+	GroupBy []string
+	Sort    string
+	Limit   string
+	Offset  string
+	Fields  []*FieldDescription
 }
 
 type SqlTranslator struct {
-	rootNode *rqlParser.RqlRootNode
+	rootNode    *rqlParser.RqlRootNode
+	withDeleted bool
+	pagination  *Pagination
 }
 
+//Records of soft-deletable objects marked as deleted are excluded unless with_deleted() is specified
 func NewSqlTranslator(rqlRoot *rqlParser.RqlRootNode) *SqlTranslator {
-	return &SqlTranslator{rootNode: rqlRoot}
+	withDeleted := len(extractRootNodes(rqlRoot, isWithDeletedNode)) > 0
+	return &SqlTranslator{rootNode: rqlRoot, withDeleted: withDeleted}
+}
+
+//Records are paginated by the cursor instead of the offset if the pagination requests it
+func NewPaginatedSqlTranslator(rqlRoot *rqlParser.RqlRootNode, pagination *Pagination) *SqlTranslator {
+	translator := NewSqlTranslator(rqlRoot)
+	translator.pagination = pagination
+	return translator
+}
+
+//Appends the condition excluding records marked as deleted to the WHERE statement
+func (st *SqlTranslator) excludeDeleted(tableAlias string, root *Node, whereStatement string) string {
+	if st.withDeleted || !root.Meta.SoftDelete {
+		return whereStatement
+	}
+	condition := fmt.Sprintf("%s.\"%s\" IS NULL", tableAlias, description.SoftDeleteFieldName)
+	if whereStatement == "" {
+		return condition
+	}
+	return "(" + whereStatement + ") AND " + condition
 }
 
 type context struct {
-	root     *Node
-	tblAlias string
-	binds    []interface{}
+	root      *Node
+	tblAlias  string
+	binds     []interface{}
+	rankExprs []string
 }
 
 func (ctx *context) addBind(v interface{}) string {
@@ -108,6 +186,9 @@ func init() {
 	operators["GE"] = ge
 	operators["LIKE"] = like
 	operators["IS_NULL"] = is_null
+	operators["SEARCH"] = search
+	operators["FTS"] = fts
+	operators["CONTAINS"] = contains
 
 	valueFuncs["NULL"] = nullvf
 	valueFuncs["EMPTY"] = emptyvf
@@ -181,6 +262,9 @@ func argToFieldVal(arg interface{}, field *FieldDescription) (interface{}, error
 			t, _ := field.Type.String()
 			return nil, NewRqlError(ErrRQLWrongValue, "Value '%s' is wrong. Expected: %s", value, t)
 		}
+		if val != nil && field.Type == description.FieldTypeJSON {
+			return JSONValue{Document: val}, nil
+		}
 		return val, nil
 	case string:
 		return field.ValueFromString(value)
@@ -230,8 +314,40 @@ func not(ctx *context, args []interface{}) (expr, error) {
 
 type sqlOp func(*FieldDescription, []interface{}) (string, error)
 
+//Assembles SQL condition for the column of the field, alias is the alias of the table the field belongs to,
+//jsonPath is the path to the key of the JSON document if the field is JSON one
+type sqlColumnOp func(alias string, field *FieldDescription, jsonPath []string, values []interface{}) (string, error)
+
 //Assemble SQL for the given expression
 func (ctx *context) makeFieldExpression(args []interface{}, sqlOperator sqlOp) (expr, error) {
+	return ctx.makeColumnExpression(args, func(alias string, field *FieldDescription, jsonPath []string, values []interface{}) (string, error) {
+		var fieldName string
+		if field.Type == description.FieldTypeGeneric {
+			fieldName = GetGenericFieldTypeColumnName(field.Name)
+		} else {
+			fieldName = field.Name
+		}
+
+		op, err := sqlOperator(field, values)
+		if err != nil {
+			return "", err
+		}
+		if !field.hasColumn() {
+			value, err := fieldValueSql(field, alias)
+			if err != nil {
+				return "", NewRqlError(ErrRQLWrongFieldName, "Field '%s' has wrong formula: %s", field.Name, err.Error())
+			}
+			return fmt.Sprintf("%s %s", value, op), nil
+		}
+		if len(jsonPath) > 0 {
+			return fmt.Sprintf("%s.\"%s\" #> %s %s", alias, fieldName, ctx.addBind(pq.StringArray(jsonPath)), op), nil
+		}
+		return fmt.Sprintf("%s.\"%s\" %s", alias, fieldName, op), nil
+	})
+}
+
+//Assemble SQL for the given expression, joining related objects along the field path
+func (ctx *context) makeColumnExpression(args []interface{}, sqlColumnOperator sqlColumnOp) (expr, error) {
 	// 	Recursively walk through each object building joins between tables and query by value at the end
 	//	example: handling "eq(fruit_tags.tag_id,1)" the function will make 1 join with "fruit_tags" and 1 filter with
 	//	"tag_id=1"
@@ -258,6 +374,15 @@ func (ctx *context) makeFieldExpression(args []interface{}, sqlOperator sqlOp) (
 		if field == nil {
 			return nil, NewRqlError(ErrRQLWrongFieldName, "Object '%s' doesn't have '%s' field", currentMeta.Name, fieldPathParts[i])
 		}
+		//the rest of the path refers to the key of the JSON document, eg: eq(payload.customer.name,John)
+		if field.Type == description.FieldTypeJSON {
+			condition, err := sqlColumnOperator(alias, field, fieldPathParts[i+1:], args[1:])
+			if err != nil {
+				return nil, err
+			}
+			expression.WriteString(condition)
+			break
+		}
 		// process related object`s table join
 		// do it only if the current iteration is not that last, because the target field for the query can have the
 		// "LinkMeta"
@@ -346,19 +471,12 @@ func (ctx *context) makeFieldExpression(args []interface{}, sqlOperator sqlOp) (
 				return nil, NewRqlError(ErrRQLWrongFieldName, "FieldDescription path '%s' in 'eq' rql function is incorrect", fieldPath)
 			}
 		} else {
-			var fieldName string
-			if field.Type == description.FieldTypeGeneric {
-				fieldName = GetGenericFieldTypeColumnName(field.Name)
-			} else {
-				fieldName = field.Name
-			}
-
-			op, err := sqlOperator(field, args[1:])
+			condition, err := sqlColumnOperator(alias, field, nil, args[1:])
 			if err != nil {
 				return nil, err
 			}
 
-			expression.WriteString(fmt.Sprintf("%s.\"%s\" %s", alias, fieldName, op))
+			expression.WriteString(condition)
 		}
 	}
 
@@ -497,7 +615,114 @@ func is_null(ctx *context, args []interface{}) (expr, error) {
 	})
 }
 
-func (st *SqlTranslator) sort(tableAlias string, root *Node) (string, error) {
+//Full-text search query for the given terms
+func (ctx *context) searchQuery(terms interface{}) (string, error) {
+	termsString, ok := terms.(string)
+	if !ok || strings.TrimSpace(termsString) == "" {
+		return "", NewRqlError(ErrRQLWrongSearch, "Search terms must be a non-empty string")
+	}
+	return fmt.Sprintf("plainto_tsquery('%s', %s)", SearchConfiguration, ctx.addBind(termsString)), nil
+}
+
+//Full-text search by the searchable field, eg: search(description,red apple) or search(author.name,john)
+func search(ctx *context, args []interface{}) (expr, error) {
+	if len(args) != 2 {
+		return nil, NewRqlError(ErrRQLWrong, "Expected only two arguments for '%s' rql function but founded '%d'", "search", len(args))
+	}
+	return ctx.makeColumnExpression(args, func(alias string, field *FieldDescription, jsonPath []string, values []interface{}) (string, error) {
+		if !field.Searchable {
+			return "", NewRqlError(ErrRQLWrongSearch, "Field '%s' of object '%s' is not searchable", field.Name, field.Meta.Name)
+		}
+		query, err := ctx.searchQuery(values[0])
+		if err != nil {
+			return "", err
+		}
+		column := fmt.Sprintf("%s.\"%s\"", alias, GetSearchFieldColumnName(field.Name))
+		//only fields of the queried object are ranked
+		if alias == ctx.tblAlias {
+			ctx.rankExprs = append(ctx.rankExprs, fmt.Sprintf("ts_rank(%s, %s)", column, query))
+		}
+		return fmt.Sprintf("%s @@ %s", column, query), nil
+	})
+}
+
+//Full-text search by all searchable fields of the object, eg: fts(red apple)
+func fts(ctx *context, args []interface{}) (expr, error) {
+	if len(args) != 1 {
+		return nil, NewRqlError(ErrRQLWrong, "Expected only one argument for '%s' rql function but founded '%d'", "fts", len(args))
+	}
+	columns := make([]string, 0)
+	for _, field := range ctx.root.Meta.Fields {
+		if field.Searchable {
+			columns = append(columns, fmt.Sprintf("%s.\"%s\"", ctx.tblAlias, GetSearchFieldColumnName(field.Name)))
+		}
+	}
+	if len(columns) == 0 {
+		return nil, NewRqlError(ErrRQLWrongSearch, "Object '%s' doesn't have searchable fields", ctx.root.Meta.Name)
+	}
+	query, err := ctx.searchQuery(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	//each column is matched separately to use its own index
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		conditions[i] = fmt.Sprintf("%s @@ %s", column, query)
+	}
+	ctx.rankExprs = append(ctx.rankExprs, fmt.Sprintf("ts_rank(%s, %s)", strings.Join(columns, " || "), query))
+	return func() string {
+		return "(" + strings.Join(conditions, " OR ") + ")"
+	}, nil
+}
+
+//Matches records whose array field contains all the given values, eg: contains(tags,red) or contains(tags,(red,green)).
+//JSON documents are matched by the array at the given key, eg: contains(payload.tags,red)
+func contains(ctx *context, args []interface{}) (expr, error) {
+	if len(args) != 2 {
+		return nil, NewRqlError(ErrRQLWrong, "Expected only two arguments for '%s' rql function but founded '%d'", "contains", len(args))
+	}
+	return ctx.makeFieldExpression(args, func(field *FieldDescription, values []interface{}) (string, error) {
+		items := values
+		if valuesNode, ok := values[0].(*rqlParser.RqlNode); ok {
+			items = valuesNode.Args
+		}
+		switch field.Type {
+		case description.FieldTypeStringArray, description.FieldTypeNumberArray:
+			itemType := "text"
+			if field.Type == description.FieldTypeNumberArray {
+				itemType = "numeric"
+			}
+			placeholders := make([]string, len(items))
+			for i := range items {
+				value, err := argToFieldVal(items[i], field)
+				if err != nil {
+					return "", err
+				}
+				placeholders[i] = ctx.addBind(value) + "::" + itemType
+			}
+			return fmt.Sprintf("@> ARRAY[%s]", strings.Join(placeholders, ",")), nil
+		case description.FieldTypeJSON:
+			documents := make([]interface{}, len(items))
+			for i := range items {
+				value, err := argToFieldVal(items[i], field)
+				if err != nil {
+					return "", err
+				}
+				if document, ok := value.(JSONValue); ok {
+					documents[i] = document.Document
+				}
+			}
+			return "@> " + ctx.addBind(JSONValue{Document: documents}), nil
+		default:
+			return "", NewRqlError(ErrRQLWrongContains, "Field '%s' of object '%s' is neither array nor JSON one", field.Name, field.Meta.Name)
+		}
+	})
+}
+
+//Returns the ORDER BY statement and, if the pagination is requested, the keyset predicate
+//selecting records which follow the cursor
+func (st *SqlTranslator) sort(ctx *context, tableAlias string, root *Node) (string, string, error) {
 	var b bytes.Buffer
 	var sorts = make([]rqlParser.Sort, 0)
 
@@ -506,14 +731,41 @@ func (st *SqlTranslator) sort(tableAlias string, root *Node) (string, error) {
 	}
 
 	sorts = append(sorts, rqlParser.Sort{By: root.Meta.MetaDescription.Key, Desc: false})
+	keyset := make([]keysetColumn, 0, len(sorts))
 	for i := range sorts {
+		if sorts[i].By == SearchRankSortKey {
+			if len(ctx.rankExprs) == 0 {
+				return "", "", NewRqlError(ErrRQLWrongSearch, "Sorting by '%s' requires full-text search conditions", SearchRankSortKey)
+			}
+			if st.pagination.byCursor() {
+				return "", "", NewRqlError(ErrRQLWrongCursor, "Sorting by '%s' can't be paginated by the cursor", SearchRankSortKey)
+			}
+			b.WriteString(strings.Join(ctx.rankExprs, " + "))
+			if sorts[i].Desc {
+				b.WriteString(" DESC")
+			}
+			b.WriteRune(',')
+			continue
+		}
 		fieldDescription := root.Meta.FindField(sorts[i].By)
 		if fieldDescription == nil {
-			return "", NewRqlError(ErrRQLWrongFieldName, "Object '%s' doesn't have '%s' field", root.Meta.Name, sorts[i].By)
+			return "", "", NewRqlError(ErrRQLWrongFieldName, "Object '%s' doesn't have '%s' field", root.Meta.Name, sorts[i].By)
+		}
+		var value string
+		if !fieldDescription.hasColumn() {
+			var err error
+			value, err = fieldValueSql(fieldDescription, tableAlias)
+			if err != nil {
+				return "", "", NewRqlError(ErrRQLWrongFieldName, "Field '%s' has wrong formula: %s", fieldDescription.Name, err.Error())
+			}
+		} else {
+			value = tableAlias + "." + sorts[i].By
+		}
+		if st.pagination.byCursor() && !isKeysetField(fieldDescription) {
+			return "", "", NewRqlError(ErrRQLWrongCursor, "Sorting by '%s' field can't be paginated by the cursor", fieldDescription.Name)
 		}
-		b.WriteString(tableAlias)
-		b.WriteRune('.')
-		b.WriteString(sorts[i].By)
+		keyset = append(keyset, keysetColumn{Field: fieldDescription, Expr: value, Desc: sorts[i].Desc})
+		b.WriteString(value)
 		if sorts[i].Desc {
 			b.WriteString(" DESC")
 		}
@@ -524,7 +776,17 @@ func (st *SqlTranslator) sort(tableAlias string, root *Node) (string, error) {
 		b.Truncate(b.Len() - 1)
 	}
 
-	return b.String(), nil
+	if !st.pagination.byCursor() || st.pagination.Cursor == "" {
+		return b.String(), "", nil
+	}
+	c, err := decodeCursor(st.pagination.Cursor)
+	if err != nil {
+		return "", "", err
+	}
+	if c.Sort != keysetSortString(sorts) || len(c.Values) != len(keyset) {
+		return "", "", NewRqlError(ErrRQLWrongCursor, "Cursor doesn't match the sort order '%s'", keysetSortString(sorts))
+	}
+	return b.String(), ctx.keysetPredicate(keyset, c.Values), nil
 }
 
 func (st *SqlTranslator) query(tableAlias string, root *Node) (*SqlQuery, error) {
@@ -540,10 +802,342 @@ func (st *SqlTranslator) query(tableAlias string, root *Node) (*SqlQuery, error)
 		whereStatement = ""
 	}
 
-	sort, err := st.sort(tableAlias, root)
+	whereStatement = st.excludeDeleted(tableAlias, root, whereStatement)
+
+	//binds added by sort belong to the keyset predicate
+	bindsCount := len(ctx.binds)
+	sort, keyset, err := st.sort(ctx, tableAlias, root)
 	if err != nil {
 		return nil, err
 	}
 
-	return &SqlQuery{Where: whereStatement, Binds: ctx.binds, Sort: sort, Limit: st.rootNode.Limit(), Offset: st.rootNode.Offset()}, nil
+	sqlQuery := &SqlQuery{Where: whereStatement, Binds: ctx.binds[:bindsCount], Sort: sort, Limit: st.rootNode.Limit(), Offset: st.rootNode.Offset()}
+	if !st.pagination.byCursor() {
+		return sqlQuery, nil
+	}
+	if keyset != "" {
+		if offset, _ := strconv.Atoi(sqlQuery.Offset); offset != 0 {
+			return nil, NewRqlError(ErrRQLWrongCursor, "Offset can't be used with the cursor")
+		}
+		sqlQuery.Keyset, sqlQuery.KeysetBinds = keyset, ctx.binds[bindsCount:]
+	}
+	sorts := append(st.rootNode.Sort(), rqlParser.Sort{By: root.Meta.MetaDescription.Key})
+	sqlQuery.cursor = &cursor{Sort: keysetSortString(sorts)}
+	for _, sort := range sorts {
+		sqlQuery.keysetFields = append(sqlQuery.keysetFields, sort.By)
+	}
+	return sqlQuery, nil
+}
+
+//Aggregate function applied to the field, eg: sum(amount) or count()
+type AggregateFunc struct {
+	Func  string
+	Field string
+}
+
+//Name of the result column, eg: "amount__sum" for sum(amount) and "count" for count()
+func (af *AggregateFunc) Key() string {
+	if af.Field == "" {
+		return af.Func
+	}
+	return af.Field + "__" + af.Func
+}
+
+//Aggregation requested with aggregate(...) and groupby(...) RQL nodes
+type Aggregation struct {
+	Funcs   []*AggregateFunc
+	GroupBy []string
+}
+
+//Returns all field paths the aggregation is built on
+func (a *Aggregation) Fields() []string {
+	fields := make([]string, 0)
+	fields = append(fields, a.GroupBy...)
+	for _, aggregateFunc := range a.Funcs {
+		if aggregateFunc.Field != "" {
+			fields = append(fields, aggregateFunc.Field)
+		}
+	}
+	return fields
+}
+
+var aggregateFuncs = map[string]string{"COUNT": "count", "SUM": "sum", "AVG": "avg", "MIN": "min", "MAX": "max"}
+
+//Condition of the field equal to the value, the value is passed as is, so that it is never interpreted as RQL
+func newEqRqlNode(fieldPath string, value string) *rqlParser.RqlNode {
+	return &rqlParser.RqlNode{Op: "eq", Args: []interface{}{fieldPath, value}}
+}
+
+//Condition of the field equal to any of the values, values are passed as is the same way
+func newInRqlNode(fieldPath string, values []string) *rqlParser.RqlNode {
+	valuesNode := &rqlParser.RqlNode{Args: make([]interface{}, len(values))}
+	for i, value := range values {
+		valuesNode.Args[i] = value
+	}
+	return &rqlParser.RqlNode{Op: "in", Args: []interface{}{fieldPath, valuesNode}}
+}
+
+func newNotRqlNode(condition *rqlParser.RqlNode) *rqlParser.RqlNode {
+	return &rqlParser.RqlNode{Op: "not", Args: []interface{}{condition}}
+}
+
+//RQL root matching all of the conditions
+func newRqlRootNode(conditions ...*rqlParser.RqlNode) *rqlParser.RqlRootNode {
+	if len(conditions) == 1 {
+		return &rqlParser.RqlRootNode{Node: conditions[0]}
+	}
+	args := make([]interface{}, len(conditions))
+	for i, condition := range conditions {
+		args[i] = condition
+	}
+	return &rqlParser.RqlRootNode{Node: &rqlParser.RqlNode{Op: "and", Args: args}}
+}
+
+//Removes top-level nodes matching the condition from the RQL root and returns them
+func extractRootNodes(rqlRoot *rqlParser.RqlRootNode, match func(*rqlParser.RqlNode) bool) []*rqlParser.RqlNode {
+	extracted := make([]*rqlParser.RqlNode, 0)
+	if rqlRoot.Node == nil {
+		return extracted
+	}
+
+	if match(rqlRoot.Node) {
+		extracted = append(extracted, rqlRoot.Node)
+		rqlRoot.Node = nil
+	} else if strings.ToUpper(rqlRoot.Node.Op) == "AND" {
+		args := make([]interface{}, 0)
+		for _, arg := range rqlRoot.Node.Args {
+			if node, ok := arg.(*rqlParser.RqlNode); ok && node != nil && match(node) {
+				extracted = append(extracted, node)
+			} else {
+				args = append(args, arg)
+			}
+		}
+		if len(args) == 0 {
+			rqlRoot.Node = nil
+		} else if node, ok := args[0].(*rqlParser.RqlNode); ok && len(args) == 1 {
+			rqlRoot.Node = node
+		} else {
+			rqlRoot.Node.Args = args
+		}
+	}
+	return extracted
+}
+
+func isWithDeletedNode(node *rqlParser.RqlNode) bool {
+	return strings.ToUpper(node.Op) == "WITH_DELETED"
+}
+
+func isAggregationNode(node *rqlParser.RqlNode) bool {
+	op := strings.ToUpper(node.Op)
+	return op == "AGGREGATE" || op == "GROUPBY"
+}
+
+//Extracts aggregate() and groupby() nodes from the RQL root the same way limit() and sort() are extracted,
+//so the remaining node can be translated into the WHERE clause. Returns nil if the query has no aggregation
+func ExtractAggregation(rqlRoot *rqlParser.RqlRootNode) (*Aggregation, error) {
+	aggregationNodes := extractRootNodes(rqlRoot, isAggregationNode)
+	if len(aggregationNodes) == 0 {
+		return nil, nil
+	}
+
+	aggregation := &Aggregation{Funcs: make([]*AggregateFunc, 0), GroupBy: make([]string, 0)}
+	for _, node := range aggregationNodes {
+		if strings.ToUpper(node.Op) == "GROUPBY" {
+			for _, arg := range node.Args {
+				fieldPath, ok := arg.(string)
+				if !ok || fieldPath == "" {
+					return nil, NewRqlError(ErrRQLWrongAggregation, "Arguments of 'groupby' rql function must be field names")
+				}
+				aggregation.GroupBy = append(aggregation.GroupBy, fieldPath)
+			}
+		} else {
+			for _, arg := range node.Args {
+				funcNode, ok := arg.(*rqlParser.RqlNode)
+				if !ok || funcNode == nil {
+					return nil, NewRqlError(ErrRQLWrongAggregation, "Unexpected argument of 'aggregate' rql function: %s", arg)
+				}
+				funcName, ok := aggregateFuncs[strings.ToUpper(funcNode.Op)]
+				if !ok {
+					return nil, NewRqlError(ErrRQLWrongAggregation, "Aggregate function '%s' is unknown", funcNode.Op)
+				}
+				aggregateFunc := &AggregateFunc{Func: funcName}
+				for _, funcArg := range funcNode.Args {
+					if fieldPath, ok := funcArg.(string); ok && aggregateFunc.Field == "" {
+						aggregateFunc.Field = fieldPath
+					} else if node, ok := funcArg.(*rqlParser.RqlNode); !ok || node != nil {
+						return nil, NewRqlError(ErrRQLWrongAggregation, "Expected only one field name for '%s' aggregate function", funcName)
+					}
+				}
+				if aggregateFunc.Field == "" && funcName != "count" {
+					return nil, NewRqlError(ErrRQLWrongAggregation, "Aggregate function '%s' requires a field name", funcName)
+				}
+				aggregation.Funcs = append(aggregation.Funcs, aggregateFunc)
+			}
+		}
+	}
+
+	if len(aggregation.Funcs) == 0 && len(aggregation.GroupBy) == 0 {
+		return nil, NewRqlError(ErrRQLWrongAggregation, "Neither aggregate functions nor grouping fields are specified")
+	}
+
+	return aggregation, nil
+}
+
+//Parses the filter and returns the aggregation it requests, nil is returned for regular queries
+func ParseAggregation(filter string) (*Aggregation, error) {
+	rqlNode, err := rqlParser.NewParser().Parse(filter)
+	if err != nil {
+		return nil, errors2.NewValidationError(errors.ErrWrongRQL, err.Error(), nil)
+	}
+	return ExtractAggregation(rqlNode)
+}
+
+//Resolves the field path for aggregation, joining the tables of inner links along the way.
+//Unlike filtering, aggregated values are selected, so EXISTS can't be used here and inner links are LEFT JOINed
+//using the same aliases makeFieldExpression gives to them
+func (ctx *context) resolveAggregationPath(fieldPath string, joins *[]string, joinedAliases map[string]bool) (string, *FieldDescription, error) {
+	alias := ctx.tblAlias
+	currentMeta := ctx.root.Meta
+
+	fieldPathParts := strings.Split(fieldPath, ".")
+	for i, fieldName := range fieldPathParts {
+		field := currentMeta.FindField(fieldName)
+		if field == nil {
+			return "", nil, NewRqlError(ErrRQLWrongFieldName, "Object '%s' doesn't have '%s' field", currentMeta.Name, fieldName)
+		}
+
+		isLink := field.Type == description.FieldTypeObject || field.Type == description.FieldTypeGeneric ||
+			field.Type == description.FieldTypeArray || field.Type == description.FieldTypeObjects
+		if isLink && (field.Type != description.FieldTypeObject || field.LinkType != description.LinkTypeInner) {
+			return "", nil, NewRqlError(ErrRQLWrongAggregation, "Field '%s' of object '%s' can't be used for aggregation, only inner links are supported", fieldName, currentMeta.Name)
+		}
+
+		if i == len(fieldPathParts)-1 {
+			if !field.hasColumn() {
+				value, err := fieldValueSql(field, alias)
+				if err != nil {
+					return "", nil, NewRqlError(ErrRQLWrongFieldName, "Field '%s' has wrong formula: %s", field.Name, err.Error())
+				}
+				return value, field, nil
+			}
+			return fmt.Sprintf("%s.\"%s\"", alias, field.Name), field, nil
+		}
+
+		if field.Type != description.FieldTypeObject {
+			return "", nil, NewRqlError(ErrRQLWrongFieldName, "FieldDescription path '%s' in aggregation is incorrect", fieldPath)
+		}
+
+		linkedAlias := alias + field.Name
+		if !joinedAliases[linkedAlias] {
+			*joins = append(*joins, fmt.Sprintf(
+				"LEFT JOIN %s %s ON %s.\"%s\"=%s.\"%s\"",
+				GetTableName(field.LinkMeta.Name), linkedAlias, linkedAlias, field.LinkMeta.Key.Name, alias, field.Name,
+			))
+			joinedAliases[linkedAlias] = true
+		}
+		alias = linkedAlias
+		currentMeta = field.LinkMeta
+	}
+
+	return "", nil, NewRqlError(ErrRQLWrongFieldName, "FieldDescription path '%s' in aggregation is incorrect", fieldPath)
+}
+
+//Makes the description of the result column, which is used to parse values returned by the database
+func aggregationResultField(key string, fieldType description.FieldType, field *FieldDescription) *FieldDescription {
+	resultField := &FieldDescription{Field: &description.Field{Name: key, Type: fieldType, Optional: true}}
+	if field != nil {
+		fieldCopy := *field.Field
+		fieldCopy.Name = key
+		fieldCopy.Optional = true
+		fieldCopy.Formula = ""
+		resultField = &FieldDescription{Field: &fieldCopy, Meta: field.Meta, LinkMeta: field.LinkMeta}
+	}
+	return resultField
+}
+
+func (st *SqlTranslator) aggregationQuery(tableAlias string, root *Node, aggregation *Aggregation) (*SqlAggregationQuery, error) {
+	ctx := &context{root: root, tblAlias: tableAlias, binds: make([]interface{}, 0)}
+	query := &SqlAggregationQuery{
+		Cols:    make([]string, 0),
+		Joins:   make([]string, 0),
+		GroupBy: make([]string, 0),
+		Fields:  make([]*FieldDescription, 0),
+		Limit:   st.rootNode.Limit(),
+		Offset:  st.rootNode.Offset(),
+	}
+
+	if st.rootNode.Node != nil {
+		whereExp, err := ctx.nodeToOpExpr(st.rootNode.Node)
+		if err != nil {
+			return nil, err
+		}
+		query.Where = whereExp()
+	}
+	query.Where = st.excludeDeleted(tableAlias, root, query.Where)
+
+	joinedAliases := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, fieldPath := range aggregation.GroupBy {
+		column, field, err := ctx.resolveAggregationPath(fieldPath, &query.Joins, joinedAliases)
+		if err != nil {
+			return nil, err
+		}
+		query.Cols = append(query.Cols, fmt.Sprintf("%s AS \"%s\"", column, fieldPath))
+		query.GroupBy = append(query.GroupBy, column)
+		query.Fields = append(query.Fields, aggregationResultField(fieldPath, field.Type, field))
+		keys[fieldPath] = true
+	}
+
+	for _, aggregateFunc := range aggregation.Funcs {
+		key := aggregateFunc.Key()
+		if aggregateFunc.Field == "" {
+			query.Cols = append(query.Cols, fmt.Sprintf("count(*) AS \"%s\"", key))
+			query.Fields = append(query.Fields, aggregationResultField(key, description.FieldTypeNumber, nil))
+			keys[key] = true
+			continue
+		}
+
+		column, field, err := ctx.resolveAggregationPath(aggregateFunc.Field, &query.Joins, joinedAliases)
+		if err != nil {
+			return nil, err
+		}
+		switch aggregateFunc.Func {
+		case "count":
+			query.Fields = append(query.Fields, aggregationResultField(key, description.FieldTypeNumber, nil))
+		case "sum", "avg":
+			if !field.Type.IsNumeric() {
+				return nil, NewRqlError(ErrRQLWrongAggregation, "Aggregate function '%s' can be applied to numeric fields only, '%s' is not numeric", aggregateFunc.Func, aggregateFunc.Field)
+			}
+			query.Fields = append(query.Fields, aggregationResultField(key, description.FieldTypeNumber, nil))
+		default:
+			if field.Type == description.FieldTypeBool || field.Type == description.FieldTypeObject {
+				return nil, NewRqlError(ErrRQLWrongAggregation, "Aggregate function '%s' can't be applied to '%s' field", aggregateFunc.Func, aggregateFunc.Field)
+			}
+			query.Fields = append(query.Fields, aggregationResultField(key, field.Type, field))
+		}
+		query.Cols = append(query.Cols, fmt.Sprintf("%s(%s) AS \"%s\"", aggregateFunc.Func, column, key))
+		keys[key] = true
+	}
+
+	//sorting is possible only by the grouping fields and aggregated values
+	var sort bytes.Buffer
+	if len(st.rootNode.Sort()) > 0 {
+		for _, s := range st.rootNode.Sort() {
+			if !keys[s.By] {
+				return nil, NewRqlError(ErrRQLWrongAggregation, "Can't sort by '%s', it is neither grouped nor aggregated", s.By)
+			}
+			sort.WriteString(fmt.Sprintf("\"%s\"", s.By))
+			if s.Desc {
+				sort.WriteString(" DESC")
+			}
+			sort.WriteRune(',')
+		}
+		sort.Truncate(sort.Len() - 1)
+	} else {
+		sort.WriteString(strings.Join(query.GroupBy, ","))
+	}
+	query.Sort = sort.String()
+	query.Binds = ctx.binds
+
+	return query, nil
 }
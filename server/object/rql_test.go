@@ -42,6 +42,12 @@ var _ = Describe("RQL test", func() {
 				Type:     description.FieldTypeString,
 				Optional: true,
 			},
+			{
+				Name:       "description",
+				Type:       description.FieldTypeString,
+				Optional:   true,
+				Searchable: true,
+			},
 		},
 	}
 	meta, _ := metaStore.NewMeta(&metaDescription)
@@ -144,4 +150,127 @@ var _ = Describe("RQL test", func() {
 		Expect(err).To(BeNil())
 		Expect(query.Where).To(BeEquivalentTo("test.\"camelField\" =$1"))
 	})
+
+	It("handle aggregate() and groupby() operators", func() {
+		parser := rqlParser.NewParser()
+		rqlNode, _ := parser.Parse("eq(camelField,val),aggregate(count(),sum(id)),groupby(test_field),sort(-id__sum)")
+		aggregation, err := ExtractAggregation(rqlNode)
+		Expect(err).To(BeNil())
+		Expect(aggregation.GroupBy).To(Equal([]string{"test_field"}))
+		Expect(aggregation.Funcs).To(HaveLen(2))
+
+		translator := NewSqlTranslator(rqlNode)
+		query, err := translator.aggregationQuery("test", dataNode, aggregation)
+
+		Expect(err).To(BeNil())
+		Expect(query.Where).To(BeEquivalentTo("test.\"camelField\" =$1"))
+		Expect(query.Cols).To(Equal([]string{
+			"test.\"test_field\" AS \"test_field\"", "count(*) AS \"count\"", "sum(test.\"id\") AS \"id__sum\"",
+		}))
+		Expect(query.GroupBy).To(Equal([]string{"test.\"test_field\""}))
+		Expect(query.Sort).To(BeEquivalentTo("\"id__sum\" DESC"))
+	})
+
+	It("does not allow to sum non-numeric field", func() {
+		parser := rqlParser.NewParser()
+		rqlNode, _ := parser.Parse("aggregate(sum(test_field))")
+		aggregation, err := ExtractAggregation(rqlNode)
+		Expect(err).To(BeNil())
+
+		translator := NewSqlTranslator(rqlNode)
+		_, err = translator.aggregationQuery("test", dataNode, aggregation)
+
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("does not allow to sort aggregation by not grouped field", func() {
+		parser := rqlParser.NewParser()
+		rqlNode, _ := parser.Parse("aggregate(count()),groupby(test_field),sort(camelField)")
+		aggregation, err := ExtractAggregation(rqlNode)
+		Expect(err).To(BeNil())
+
+		translator := NewSqlTranslator(rqlNode)
+		_, err = translator.aggregationQuery("test", dataNode, aggregation)
+
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("handle search() operator with sorting by rank", func() {
+		parser := rqlParser.NewParser()
+		rqlNode, _ := parser.Parse("search(description,red apple),sort(-_rank)")
+		translator := NewSqlTranslator(rqlNode)
+
+		query, err := translator.query("test", dataNode)
+
+		Expect(err).To(BeNil())
+		Expect(query.Where).To(BeEquivalentTo("test.\"description__tsv\" @@ plainto_tsquery('simple', $1)"))
+		Expect(query.Binds).To(Equal([]interface{}{"red apple"}))
+		Expect(query.Sort).To(BeEquivalentTo("ts_rank(test.\"description__tsv\", plainto_tsquery('simple', $1)) DESC,test.id"))
+	})
+
+	It("handle fts() operator", func() {
+		parser := rqlParser.NewParser()
+		rqlNode, _ := parser.Parse("fts(apple)")
+		translator := NewSqlTranslator(rqlNode)
+
+		query, err := translator.query("test", dataNode)
+
+		Expect(err).To(BeNil())
+		Expect(query.Where).To(BeEquivalentTo("(test.\"description__tsv\" @@ plainto_tsquery('simple', $1))"))
+	})
+
+	It("does not allow to search by not searchable field", func() {
+		parser := rqlParser.NewParser()
+		rqlNode, _ := parser.Parse("search(test_field,apple)")
+		translator := NewSqlTranslator(rqlNode)
+
+		_, err := translator.query("test", dataNode)
+
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("builds keyset predicate of the cursor pagination", func() {
+		parser := rqlParser.NewParser()
+		rqlNode, _ := parser.Parse("eq(camelField,apple),sort(-test_field),limit(0,2)")
+		pageCursor := (&cursor{Sort: "-test_field,+id", Values: []interface{}{"b", float64(3)}}).encode()
+		translator := NewPaginatedSqlTranslator(rqlNode, &Pagination{ByCursor: true, Cursor: pageCursor})
+
+		query, err := translator.query("test", dataNode)
+
+		Expect(err).To(BeNil())
+		Expect(query.Where).To(BeEquivalentTo("test.\"camelField\" =$1"))
+		Expect(query.Keyset).To(BeEquivalentTo("((test.test_field < $2) OR (test.test_field = $2 AND test.id > $3))"))
+		Expect(query.Binds).To(Equal([]interface{}{"apple"}))
+		Expect(query.KeysetBinds).To(Equal([]interface{}{"b", "3"}))
+
+		nextCursor, err := decodeCursor(query.nextCursor([]map[string]interface{}{
+			{"id": float64(4), "test_field": nil}, {"id": float64(5), "test_field": "a"},
+		}))
+		Expect(err).To(BeNil())
+		Expect(nextCursor.Sort).To(Equal("-test_field,+id"))
+		Expect(nextCursor.Values).To(Equal([]interface{}{"a", float64(5)}))
+	})
+
+	It("does not return the next cursor on the last page", func() {
+		parser := rqlParser.NewParser()
+		rqlNode, _ := parser.Parse("limit(0,2)")
+		translator := NewPaginatedSqlTranslator(rqlNode, &Pagination{ByCursor: true})
+
+		query, err := translator.query("test", dataNode)
+
+		Expect(err).To(BeNil())
+		Expect(query.Keyset).To(BeEmpty())
+		Expect(query.nextCursor([]map[string]interface{}{{"id": float64(4)}})).To(BeEmpty())
+	})
+
+	It("does not accept the cursor of another sort order", func() {
+		parser := rqlParser.NewParser()
+		rqlNode, _ := parser.Parse("sort(+test_field),limit(0,2)")
+		pageCursor := (&cursor{Sort: "-test_field,+id", Values: []interface{}{"b", float64(3)}}).encode()
+		translator := NewPaginatedSqlTranslator(rqlNode, &Pagination{ByCursor: true, Cursor: pageCursor})
+
+		_, err := translator.query("test", dataNode)
+
+		Expect(err).NotTo(BeNil())
+	})
 })
@@ -0,0 +1,60 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+//Text search configuration used both for tsvector columns and queries
+const SearchConfiguration = "simple"
+
+func CreateSearchColumnStatement(tableName string, fieldName string) (*DDLStmt, error) {
+	const createSearchColumnTemplate = `ALTER TABLE "{{.Table}}" ADD COLUMN IF NOT EXISTS "{{.SearchColumn}}" tsvector GENERATED ALWAYS AS (to_tsvector('{{.Configuration}}', coalesce("{{.Column}}", ''))) STORED;`
+	var buffer bytes.Buffer
+	context := map[string]interface{}{"Table": tableName, "Column": fieldName, "SearchColumn": GetSearchFieldColumnName(fieldName), "Configuration": SearchConfiguration}
+	parsedSearchTemplate := template.Must(template.New("statement").Parse(createSearchColumnTemplate))
+	if e := parsedSearchTemplate.Execute(&buffer, context); e != nil {
+		return nil, NewDdlError(ErrInternal, e.Error(), tableName)
+	}
+	return NewDdlStatement(fmt.Sprintf("add_search_column#%s_%s", tableName, fieldName), buffer.String()), nil
+}
+
+func CreateSearchIndexStatement(tableName string, fieldName string) (*DDLStmt, error) {
+	const createSearchIndexTemplate = `CREATE INDEX IF NOT EXISTS "{{.Table}}_{{.SearchColumn}}_idx" ON "{{.Table}}" USING GIN ("{{.SearchColumn}}");`
+	var buffer bytes.Buffer
+	context := map[string]interface{}{"Table": tableName, "SearchColumn": GetSearchFieldColumnName(fieldName)}
+	parsedSearchTemplate := template.Must(template.New("statement").Parse(createSearchIndexTemplate))
+	if e := parsedSearchTemplate.Execute(&buffer, context); e != nil {
+		return nil, NewDdlError(ErrInternal, e.Error(), tableName)
+	}
+	return NewDdlStatement(fmt.Sprintf("add_search_index#%s_%s", tableName, fieldName), buffer.String()), nil
+}
+
+//The index is dropped along with the column
+func DropSearchColumnStatement(tableName string, fieldName string) (*DDLStmt, error) {
+	const dropSearchColumnTemplate = `ALTER TABLE "{{.Table}}" DROP COLUMN IF EXISTS "{{.SearchColumn}}";`
+	var buffer bytes.Buffer
+	context := map[string]interface{}{"Table": tableName, "SearchColumn": GetSearchFieldColumnName(fieldName)}
+	parsedSearchTemplate := template.Must(template.New("statement").Parse(dropSearchColumnTemplate))
+	if e := parsedSearchTemplate.Execute(&buffer, context); e != nil {
+		return nil, NewDdlError(ErrInternal, e.Error(), tableName)
+	}
+	return NewDdlStatement(fmt.Sprintf("drop_search_column#%s_%s", tableName, fieldName), buffer.String()), nil
+}
+
+//Statements to add the generated tsvector column of the searchable field along with its GIN index
+func CreateSearchStatements(tableName string, fieldName string) (DdlStatementSet, error) {
+	var stmts = DdlStatementSet{}
+	if stmt, e := CreateSearchColumnStatement(tableName, fieldName); e != nil {
+		return nil, e
+	} else {
+		stmts.Add(stmt)
+	}
+	if stmt, e := CreateSearchIndexStatement(tableName, fieldName); e != nil {
+		return nil, e
+	} else {
+		stmts.Add(stmt)
+	}
+	return stmts, nil
+}
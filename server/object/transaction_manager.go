@@ -12,6 +12,10 @@ type PgDbTransactionManager struct {
 
 //transaction related methods
 func (tm *PgDbTransactionManager) BeginTransaction() (transactions.DbTransaction, error) {
+	if tm.transaction != nil {
+		tm.transaction.Counter += 1
+		return tm.transaction, nil
+	}
 	if tx, err := tm.db.Begin(); err != nil {
 		return nil, err
 	} else {
@@ -19,6 +23,34 @@ func (tm *PgDbTransactionManager) BeginTransaction() (transactions.DbTransaction
 	}
 }
 
+//Begins the transaction which all transactions begun with the manager are nested into, so that their
+//commits and rollbacks take no effect until the shared transaction is completed
+func (tm *PgDbTransactionManager) BeginSharedTransaction() (transactions.DbTransaction, error) {
+	transaction, err := tm.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	tm.transaction = transaction.(*PgTransaction)
+	return transaction, nil
+}
+
+//Commits or rolls back the shared transaction regardless of the nested transactions left uncompleted
+func (tm *PgDbTransactionManager) CompleteSharedTransaction(commit bool) error {
+	transaction := tm.transaction
+	tm.transaction = nil
+	if transaction == nil {
+		return nil
+	}
+	if commit {
+		return transaction.Tx.Commit()
+	}
+	return transaction.Tx.Rollback()
+}
+
+func (tm *PgDbTransactionManager) InSharedTransaction() bool {
+	return tm.transaction != nil
+}
+
 func NewPgDbTransactionManager(db *sql.DB) *PgDbTransactionManager {
 	return &PgDbTransactionManager{db: db}
 }
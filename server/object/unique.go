@@ -0,0 +1,87 @@
+package object
+
+import (
+	"bytes"
+	"custodian/server/object/description"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+//Unique constraint across several columns of the table
+type UniqueConstraint struct {
+	Name    string
+	Columns []string
+}
+
+//Follows naming of the unique constraints Postgres uses by default
+func GetUniqueConstraintName(tableName string, columns []string) string {
+	return fmt.Sprintf("%s_%s_key", tableName, strings.Join(columns, "_"))
+}
+
+func NewUniqueConstraint(tableName string, columns []string) *UniqueConstraint {
+	return &UniqueConstraint{Name: GetUniqueConstraintName(tableName, columns), Columns: columns}
+}
+
+const templAddUniqueConstraint = `ALTER TABLE "{{.Table}}" ADD CONSTRAINT "{{.dot.Name}}" UNIQUE ({{range $i, $column := .dot.Columns}}{{if $i}}, {{end}}"{{$column}}"{{end}});`
+const templDropUniqueConstraint = `ALTER TABLE "{{.Table}}" DROP CONSTRAINT IF EXISTS "{{.dot.Name}}";`
+
+var parsedTemplAddUniqueConstraint = template.Must(template.New("add_unique_constraint").Parse(templAddUniqueConstraint))
+var parsedTemplDropUniqueConstraint = template.Must(template.New("drop_unique_constraint").Parse(templDropUniqueConstraint))
+
+//Creates a DDL script to add the unique constraint
+func (uc *UniqueConstraint) AddScript(tableName string) (*DDLStmt, error) {
+	var buffer bytes.Buffer
+	if e := parsedTemplAddUniqueConstraint.Execute(&buffer, map[string]interface{}{
+		"Table": tableName,
+		"dot":   uc}); e != nil {
+		return nil, NewDdlError(ErrInternal, e.Error(), tableName)
+	}
+	return NewDdlStatement(fmt.Sprintf("add_unique_constraint#%s", uc.Name), buffer.String()), nil
+}
+
+//Creates a DDL script to remove the unique constraint
+func (uc *UniqueConstraint) DropScript(tableName string) (*DDLStmt, error) {
+	var buffer bytes.Buffer
+	if e := parsedTemplDropUniqueConstraint.Execute(&buffer, map[string]interface{}{
+		"Table": tableName,
+		"dot":   uc}); e != nil {
+		return nil, NewDdlError(ErrInternal, e.Error(), tableName)
+	}
+	return NewDdlStatement(fmt.Sprintf("drop_unique_constraint#%s", uc.Name), buffer.String()), nil
+}
+
+type UniqueConstraintSliceCP struct {
+	from []UniqueConstraint
+	to   *[]UniqueConstraint
+}
+
+func (cp *UniqueConstraintSliceCP) Id(i int) string { return cp.from[i].Name }
+func (cp *UniqueConstraintSliceCP) Len() int        { return len(cp.from) }
+func (cp *UniqueConstraintSliceCP) Copy(i int)      { *cp.to = append(*cp.to, cp.from[i]) }
+
+//Builds unique constraints for the "uniqueTogether" declarations of the object's description
+func (mdf *MetaDdlFactory) factoryUniqueConstraints(metaDescription *description.MetaDescription, metaDdl *MetaDDL) ([]UniqueConstraint, error) {
+	constraints := make([]UniqueConstraint, 0)
+	for _, fields := range metaDescription.UniqueTogether {
+		constraint, err := mdf.FactoryUniqueConstraint(fields, metaDdl)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, *constraint)
+	}
+	return constraints, nil
+}
+
+//Builds the unique constraint for the fields, each of them must have a column in the table
+func (mdf *MetaDdlFactory) FactoryUniqueConstraint(fields []string, metaDdl *MetaDDL) (*UniqueConstraint, error) {
+	if len(fields) < 2 {
+		return nil, NewDdlError(ErrWrongUniqueTogether, fmt.Sprintf("At least two fields are expected to be unique together, got: %v", fields), metaDdl.Table)
+	}
+	for _, fieldName := range fields {
+		if metaDdl.findColumn(fieldName) == nil {
+			return nil, NewDdlError(ErrWrongUniqueTogether, fmt.Sprintf("Field '%s' can't be a part of the unique constraint", fieldName), metaDdl.Table)
+		}
+	}
+	return NewUniqueConstraint(metaDdl.Table, fields), nil
+}
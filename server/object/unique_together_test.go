@@ -0,0 +1,93 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/errors"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unique together", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	havingObjectWithUniqueTogether := func() *object.Meta {
+		metaDescription := description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name: "company",
+					Type: description.FieldTypeString,
+				},
+				{
+					Name: "code",
+					Type: description.FieldTypeString,
+				},
+			},
+			UniqueTogether: [][]string{{"company", "code"}},
+		}
+		metaObj, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(metaObj)
+		Expect(err).To(BeNil())
+		return metaObj
+	}
+
+	It("Creates the constraint and reverses it from the database", func() {
+		metaObj := havingObjectWithUniqueTogether()
+
+		tx, err := dbTransactionManager.BeginTransaction()
+		Expect(err).To(BeNil())
+		metaDdl, err := object.MetaDDLFromDB(tx.Transaction(), metaObj.Name)
+		tx.Commit()
+		Expect(err).To(BeNil())
+		Expect(metaDdl.UniqueTogether).To(HaveLen(1))
+		Expect(metaDdl.UniqueTogether[0].Columns).To(Equal([]string{"company", "code"}))
+		Expect(metaDdl.Columns[1].Unique).To(BeFalse())
+	})
+
+	It("Returns validation error with field names on duplicated values", func() {
+		metaObj := havingObjectWithUniqueTogether()
+		user := auth.User{}
+
+		_, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"company": "A", "code": "1"}, user)
+		Expect(err).To(BeNil())
+		record, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"company": "B", "code": "1"}, user)
+		Expect(err).To(BeNil())
+
+		_, err = dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"company": "A", "code": "1"}, user)
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(object.ErrValueDuplication))
+		Expect(err.Error()).To(ContainSubstring("company, code"))
+
+		_, err = dataProcessor.UpdateRecord(metaObj.Name, record.PkAsString(), map[string]interface{}{"company": "A"}, user)
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(object.ErrValueDuplication))
+		Expect(err.(*errors.ServerError).Data).To(Equal(map[string]interface{}{"company": "A", "code": "1"}))
+	})
+})
@@ -0,0 +1,142 @@
+package object
+
+import (
+	errors2 "custodian/server/errors"
+	"custodian/server/object/description"
+	"custodian/server/transactions"
+	"custodian/utils"
+	"fmt"
+	"strings"
+)
+
+//Records are upserted by the fields, authorize is called with each existing record before it is updated
+type upsertSettings struct {
+	fields    []string
+	authorize func(*Record, map[string]interface{}) error
+}
+
+//Checks if records can be matched by the fields: they must be the key, a unique field or a set of fields unique together,
+//since the conflict of the insert is detected by the unique constraint
+func (m *Meta) checkUpsertFields(upsertFields []string) error {
+	for _, fieldName := range upsertFields {
+		field := m.FindField(fieldName)
+		if field == nil {
+			return errors2.NewValidationError(ErrInvalidArgument, fmt.Sprintf("Object '%s' doesn't have '%s' field", m.Name, fieldName), nil)
+		}
+		if !field.IsSimple() && !(field.Type == description.FieldTypeObject && field.LinkType == description.LinkTypeInner) {
+			return errors2.NewValidationError(ErrInvalidArgument, fmt.Sprintf("Field '%s' can't be used to upsert records", fieldName), nil)
+		}
+	}
+	if len(upsertFields) == 1 {
+		if field := m.FindField(upsertFields[0]); field.Name == m.Key.Name || field.Unique {
+			return nil
+		}
+	}
+	for _, uniqueFields := range m.MetaDescription.UniqueTogether {
+		if utils.Equal(uniqueFields, upsertFields, false) {
+			return nil
+		}
+	}
+	return errors2.NewValidationError(
+		ErrInvalidArgument, fmt.Sprintf("Fields '%s' must be the key or unique to upsert records", strings.Join(upsertFields, ",")), nil,
+	)
+}
+
+//Returns existing records having the same values of upsert fields as the records of the set,
+//an item is nil if there is no such record yet
+func (processor *Processor) findUpsertTargets(recordSet *RecordSet, upsertFields []string, dbTransaction transactions.DbTransaction) ([]*Record, error) {
+	targets := make([]*Record, len(recordSet.Records))
+	for i, record := range recordSet.Records {
+		filters := make(map[string]interface{})
+		for _, fieldName := range upsertFields {
+			//values of links are already replaced with the keys of the linked records
+			if value := record.RawData[fieldName]; value != nil {
+				filters[fieldName] = value
+			}
+		}
+		//the record with absent values can't conflict with the existing ones
+		if len(filters) < len(upsertFields) {
+			continue
+		}
+		existing, err := processor.GetAll(recordSet.Meta, nil, filters, dbTransaction)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			targets[i] = NewRecord(recordSet.Meta, existing[0], processor)
+		}
+	}
+	return targets, nil
+}
+
+//Returns the existing record matching the data of the record being upserted, values of the links are either keys
+//of the linked records or their data
+func (processor *Processor) findUpsertTarget(objectMeta *Meta, upsertFields []string, recordData map[string]interface{}) (*Record, error) {
+	filters := make(map[string]interface{})
+	for _, fieldName := range upsertFields {
+		value := recordData[fieldName]
+		if linkData, ok := value.(map[string]interface{}); ok {
+			if field := objectMeta.FindField(fieldName); field != nil && field.LinkMeta != nil {
+				value = linkData[field.LinkMeta.Key.Name]
+			}
+		}
+		//the record with absent values can't conflict with the existing ones
+		if value == nil {
+			return nil, nil
+		}
+		filters[fieldName] = value
+	}
+
+	dbTransaction, err := processor.transactionManager.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := processor.GetAll(objectMeta, nil, filters, dbTransaction)
+	if err != nil {
+		dbTransaction.Rollback()
+		return nil, err
+	}
+	dbTransaction.Commit()
+	if len(existing) == 0 {
+		return nil, nil
+	}
+
+	key, err := objectMeta.Key.ValueAsString(existing[0][objectMeta.Key.Name])
+	if err != nil {
+		return nil, err
+	}
+	return processor.Get(objectMeta.Name, key, nil, nil, 1, true)
+}
+
+//Only the root records are upserted, the nested ones are created as usual
+func rootUpsert(isRoot bool, upsert *upsertSettings) *upsertSettings {
+	if isRoot {
+		return upsert
+	}
+	return nil
+}
+
+//Existing records are updated by the upsert, so they are checked the same way as the updated ones:
+//the version of the record of CAS-enabled object must match and the update must be authorized
+func checkUpsertTargets(recordSet *RecordSet, targets []*Record, authorize func(*Record, map[string]interface{}) error) error {
+	for i, target := range targets {
+		if target == nil {
+			continue
+		}
+		record := recordSet.Records[i]
+		if recordSet.Meta.Cas {
+			if err := checkCasValue(recordSet.Meta, record.RawData); err != nil {
+				return err
+			}
+			if actual := target.Data[description.CasFieldName]; record.RawData[description.CasFieldName] != actual {
+				return newCasConflictError(recordSet.Meta, actual)
+			}
+		}
+		if authorize != nil {
+			if err := authorize(target, record.Data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/errors"
+	"custodian/server/noti"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Upsert", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	objectName := utils.RandomString(8)
+
+	BeforeEach(func() {
+		metaDescription := description.MetaDescription{
+			Name: objectName,
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+				{Name: "code", Type: description.FieldTypeString, Unique: true},
+				{Name: "name", Type: description.FieldTypeString, Optional: true},
+			},
+			Actions: []description.Action{
+				{Method: description.MethodCreate, Protocol: noti.TEST, Args: []string{"http://example.com"}, ActiveIfNotRoot: true, Name: "on_create"},
+				{Method: description.MethodUpdate, Protocol: noti.TEST, Args: []string{"http://example.com"}, ActiveIfNotRoot: true, Name: "on_update"},
+			},
+		}
+		meta, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(meta)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	It("creates the record if it does not exist and updates it otherwise", func() {
+		record, err := dataProcessor.UpsertRecord(objectName, map[string]interface{}{"code": "a", "name": "first"}, []string{"code"}, nil, auth.User{})
+		Expect(err).To(BeNil())
+		id := record.Data["id"]
+
+		record, err = dataProcessor.UpsertRecord(objectName, map[string]interface{}{"code": "a", "name": "second"}, []string{"code"}, nil, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(record.Data["id"]).To(Equal(id))
+		Expect(record.Data["name"]).To(Equal("second"))
+
+		count, _, err := dataProcessor.GetBulk(objectName, "", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(1))
+	})
+
+	It("upserts records by the key if no fields are given", func() {
+		record, err := dataProcessor.CreateRecord(objectName, map[string]interface{}{"code": "a", "name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		records, err := dataProcessor.BulkUpsertRecords(objectName, []map[string]interface{}{
+			{"id": record.Data["id"], "code": "a", "name": "second"},
+			{"code": "b", "name": "third"},
+		}, nil, nil, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Data["name"]).To(Equal("second"))
+
+		count, _, err := dataProcessor.GetBulk(objectName, "", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(2))
+	})
+
+	It("notifies about created and updated records of the mixed upsert separately", func() {
+		_, err := dataProcessor.CreateRecord(objectName, map[string]interface{}{"code": "a", "name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+		meta, _, err := metaStore.Get(objectName, true)
+		Expect(err).To(BeNil())
+		createEvents := meta.Actions[0].Notifier.(*noti.TestNotifier).Events
+		updateEvents := meta.Actions[1].Notifier.(*noti.TestNotifier).Events
+		Eventually(createEvents).Should(Receive())
+
+		_, err = dataProcessor.BulkUpsertRecords(objectName, []map[string]interface{}{
+			{"code": "b", "name": "third"},
+			{"code": "a", "name": "second"},
+			{"code": "c", "name": "fourth"},
+		}, []string{"code"}, nil, auth.User{})
+		Expect(err).To(BeNil())
+
+		var event *noti.Event
+		Eventually(updateEvents).Should(Receive(&event))
+		Expect(event.Obj()["previous"].(map[string]interface{})["name"]).To(Equal("first"))
+		Expect(event.Obj()["current"].(map[string]interface{})["name"]).To(Equal("second"))
+		Consistently(updateEvents).Should(BeEmpty())
+
+		Eventually(createEvents).Should(Receive(&event))
+		Expect(event.Obj()["previous"]).To(BeEmpty())
+		Expect(event.Obj()["current"].(map[string]interface{})["code"]).To(Equal("b"))
+		Eventually(createEvents).Should(Receive(&event))
+		Expect(event.Obj()["current"].(map[string]interface{})["code"]).To(Equal("c"))
+		Consistently(createEvents).Should(BeEmpty())
+	})
+
+	It("authorizes the update of the existing record and does not update it if it is rejected", func() {
+		record, err := dataProcessor.CreateRecord(objectName, map[string]interface{}{"code": "a", "name": "first"}, auth.User{})
+		Expect(err).To(BeNil())
+
+		var authorized []*object.Record
+		authorize := func(target *object.Record, recordData map[string]interface{}) error {
+			authorized = append(authorized, target)
+			return errors.NewValidationError("forbidden", "Update is forbidden", nil)
+		}
+		_, err = dataProcessor.BulkUpsertRecords(objectName, []map[string]interface{}{
+			{"code": "b", "name": "third"},
+			{"code": "a", "name": "second"},
+		}, []string{"code"}, authorize, auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(authorized).To(HaveLen(1))
+		Expect(authorized[0].Data["id"]).To(Equal(record.Data["id"]))
+
+		count, records, err := dataProcessor.GetBulk(objectName, "", nil, nil, 1, false)
+		Expect(err).To(BeNil())
+		Expect(count).To(Equal(1))
+		Expect(records[0].Data["name"]).To(Equal("first"))
+	})
+
+	It("does not upsert records by the field which is not unique", func() {
+		_, err := dataProcessor.UpsertRecord(objectName, map[string]interface{}{"code": "a", "name": "first"}, []string{"name"}, nil, auth.User{})
+		Expect(err).NotTo(BeNil())
+	})
+
+	Describe("CAS-enabled object", func() {
+		casObjectName := utils.RandomString(8)
+
+		BeforeEach(func() {
+			metaDescription := description.MetaDescription{
+				Name: casObjectName,
+				Key:  "id",
+				Cas:  true,
+				Fields: []description.Field{
+					{Name: "id", Type: description.FieldTypeNumber, Optional: true, Def: map[string]interface{}{"func": "nextval"}},
+					{Name: "code", Type: description.FieldTypeString, Unique: true},
+					{Name: "name", Type: description.FieldTypeString, Optional: true},
+				},
+			}
+			meta, err := metaStore.NewMeta(&metaDescription)
+			Expect(err).To(BeNil())
+			err = metaStore.Create(meta)
+			Expect(err).To(BeNil())
+		})
+
+		It("updates the existing record only if its cas value matches and increases it", func() {
+			_, err := dataProcessor.CreateRecord(casObjectName, map[string]interface{}{"code": "a", "name": "first"}, auth.User{})
+			Expect(err).To(BeNil())
+
+			_, err = dataProcessor.UpsertRecord(casObjectName, map[string]interface{}{"code": "a", "name": "second", "cas": 2.0}, []string{"code"}, nil, auth.User{})
+			Expect(err).NotTo(BeNil())
+			Expect(err.(*errors.ServerError).Code).To(Equal(object.ErrCasConflict))
+
+			record, err := dataProcessor.UpsertRecord(casObjectName, map[string]interface{}{"code": "a", "name": "second", "cas": 1.0}, []string{"code"}, nil, auth.User{})
+			Expect(err).To(BeNil())
+			Expect(record.Data["name"]).To(Equal("second"))
+			Expect(record.Data["cas"]).To(Equal(2.0))
+		})
+	})
+})
@@ -9,6 +9,7 @@ const (
 	genericFieldColumnTypeSuffix = "__type"
 	genericFieldColumnKeySuffix  = "__key"
 	reverseInnerLinkSuffix       = "_set"
+	searchFieldColumnSuffix      = "__tsv"
 )
 
 func GetGenericFieldTypeColumnName(fieldName string) string {
@@ -19,6 +20,14 @@ func GetGenericFieldKeyColumnName(fieldName string) string {
 	return fmt.Sprintf("%s%s", fieldName, genericFieldColumnKeySuffix)
 }
 
+func GetSearchFieldColumnName(fieldName string) string {
+	return fmt.Sprintf("%s%s", fieldName, searchFieldColumnSuffix)
+}
+
+func IsSearchFieldColumn(columnName string) bool {
+	return strings.HasSuffix(columnName, searchFieldColumnSuffix)
+}
+
 func IsGenericFieldTypeColumn(columnName string) bool {
 	return strings.HasSuffix(columnName, genericFieldColumnTypeSuffix)
 }
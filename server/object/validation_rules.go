@@ -0,0 +1,283 @@
+package object
+
+import (
+	errors2 "custodian/server/errors"
+	"custodian/server/object/description"
+	"custodian/server/object/errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Q-CIS-DEV/go-rql-parser"
+)
+
+//Validates the record against validation rules of its fields and checks of its object.
+//All violations are collected, so the error contains each of them
+func (vs *ValidationService) validateRules(record *Record) error {
+	violations := make([]map[string]string, 0)
+	for i := range record.Meta.Fields {
+		fieldDescription := &record.Meta.Fields[i]
+		if !fieldDescription.HasValidationRules() {
+			continue
+		}
+		if value, ok := record.Data[fieldDescription.Name]; ok && value != nil {
+			violations = append(violations, validateFieldRules(fieldDescription.Field, value)...)
+		}
+	}
+
+	if len(record.Meta.Checks) > 0 {
+		checkViolations, err := vs.validateChecks(record)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, checkViolations...)
+	}
+
+	if len(violations) > 0 {
+		return errors2.NewValidationError(
+			errors.ErrValidationRuleViolation,
+			fmt.Sprintf("Record of '%s' does not satisfy validation rules", record.Meta.Name),
+			violations,
+		)
+	}
+	return nil
+}
+
+func validateFieldRules(field *description.Field, value interface{}) []map[string]string {
+	violations := make([]map[string]string, 0)
+	violation := func(rule string, msg string, a ...interface{}) {
+		violations = append(violations, map[string]string{"field": field.Name, "rule": rule, "msg": fmt.Sprintf(msg, a...)})
+	}
+	switch value := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(value)
+		if field.MinLength != nil && length < *field.MinLength {
+			violation("minLength", "Value of '%s' must be at least %d characters long", field.Name, *field.MinLength)
+		}
+		if field.MaxLength != nil && length > *field.MaxLength {
+			violation("maxLength", "Value of '%s' must be at most %d characters long", field.Name, *field.MaxLength)
+		}
+		if field.Pattern != "" {
+			if matched, _ := regexp.MatchString(field.Pattern, value); !matched {
+				violation("pattern", "Value of '%s' does not match pattern '%s'", field.Name, field.Pattern)
+			}
+		}
+	default:
+		if number, ok := checkNumberValue(value); ok {
+			if field.Min != nil && number < *field.Min {
+				violation("min", "Value of '%s' must be greater than or equal to %v", field.Name, *field.Min)
+			}
+			if field.Max != nil && number > *field.Max {
+				violation("max", "Value of '%s' must be less than or equal to %v", field.Name, *field.Max)
+			}
+		}
+	}
+	return violations
+}
+
+//Evaluates checks of the object, values which are absent in the data of the existing record are taken from the DB
+func (vs *ValidationService) validateChecks(record *Record) ([]map[string]string, error) {
+	data := make(map[string]interface{})
+	if !record.IsPhantom() {
+		if currentRecord, err := vs.processor.Get(record.Meta.Name, record.PkAsString(), nil, nil, 1, true); err != nil {
+			return nil, err
+		} else if currentRecord != nil {
+			for key, value := range currentRecord.Data {
+				data[key] = value
+			}
+		}
+	}
+	for key, value := range record.Data {
+		data[key] = value
+	}
+
+	violations := make([]map[string]string, 0)
+	for _, check := range record.Meta.Checks {
+		rqlRoot, err := rqlParser.NewParser().Parse(check.Expression)
+		if err != nil || rqlRoot.Node == nil {
+			return nil, errors2.NewValidationError(errors.ErrWrongRQL, fmt.Sprintf("Check '%s' has wrong expression '%s'", check.Name, check.Expression), nil)
+		}
+		passed, err := evaluateCheckNode(rqlRoot.Node, record.Meta, data, nil)
+		if err != nil {
+			return nil, errors2.NewValidationError(errors.ErrWrongRQL, fmt.Sprintf("Check '%s' can't be evaluated: %s", check.Name, err.Error()), nil)
+		}
+		if !passed {
+			msg := check.Message
+			if msg == "" {
+				msg = fmt.Sprintf("Check '%s' failed", check.Name)
+			}
+			violations = append(violations, map[string]string{"check": check.Name, "rule": "check", "msg": msg})
+		}
+	}
+	return violations, nil
+}
+
+const previousStatePrefix = "previous."
+
+//Evaluates RQL expression of the check against the record data.
+//Fields prefixed by "previous." refer to the previous data of the record if it is given
+func evaluateCheckNode(node *rqlParser.RqlNode, meta *Meta, data map[string]interface{}, previous map[string]interface{}) (bool, error) {
+	op := strings.ToUpper(node.Op)
+	switch op {
+	case "AND", "OR":
+		for _, arg := range node.Args {
+			argNode, ok := arg.(*rqlParser.RqlNode)
+			if !ok {
+				return false, fmt.Errorf("unexpected argument '%v' of '%s'", arg, node.Op)
+			}
+			result, err := evaluateCheckNode(argNode, meta, data, previous)
+			if err != nil {
+				return false, err
+			}
+			if op == "AND" && !result {
+				return false, nil
+			}
+			if op == "OR" && result {
+				return true, nil
+			}
+		}
+		return op == "AND", nil
+	case "NOT":
+		if len(node.Args) != 1 {
+			return false, fmt.Errorf("expected only one argument for 'not'")
+		}
+		argNode, ok := node.Args[0].(*rqlParser.RqlNode)
+		if !ok {
+			return false, fmt.Errorf("unexpected argument '%v' of 'not'", node.Args[0])
+		}
+		result, err := evaluateCheckNode(argNode, meta, data, previous)
+		return !result, err
+	}
+
+	if len(node.Args) != 2 {
+		return false, fmt.Errorf("expected two arguments for '%s'", node.Op)
+	}
+	fieldName, ok := node.Args[0].(string)
+	if !ok {
+		return false, fmt.Errorf("the field name of '%s' is not a string", node.Op)
+	}
+	values := data
+	if previous != nil && strings.HasPrefix(fieldName, previousStatePrefix) {
+		fieldName = strings.TrimPrefix(fieldName, previousStatePrefix)
+		values = previous
+	}
+	field := meta.FindField(fieldName)
+	if field == nil || !field.IsSimple() || field.LinkType == description.LinkTypeInner {
+		return false, fmt.Errorf("field '%s' can't be checked", fieldName)
+	}
+	value := values[fieldName]
+
+	switch op {
+	case "IS_NULL":
+		isNull, err := strconv.ParseBool(fmt.Sprintf("%v", node.Args[1]))
+		if err != nil {
+			return false, fmt.Errorf("second argument for is_null() must be 'true' or 'false'")
+		}
+		return (value == nil) == isNull, nil
+	case "IN":
+		values := []interface{}{node.Args[1]}
+		if valuesNode, ok := node.Args[1].(*rqlParser.RqlNode); ok {
+			values = valuesNode.Args
+		}
+		for _, arg := range values {
+			expected, err := argToFieldVal(arg, field)
+			if err != nil {
+				return false, err
+			}
+			if comparison, ok := compareCheckValues(value, expected); ok && comparison == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "LIKE":
+		stringValue, ok := value.(string)
+		pattern, isString := node.Args[1].(string)
+		if !ok || !isString {
+			return false, nil
+		}
+		pattern = "(?i)^" + strings.Replace(regexp.QuoteMeta(pattern), `\*`, ".*", -1) + "$"
+		return regexp.MatchString(pattern, stringValue)
+	case "EQ", "NE", "LT", "LE", "GT", "GE":
+		expected, err := argToFieldVal(node.Args[1], field)
+		if err != nil {
+			return false, err
+		}
+		if value == nil || expected == nil {
+			switch op {
+			case "EQ":
+				return value == nil && expected == nil, nil
+			case "NE":
+				return value != nil || expected != nil, nil
+			default:
+				return false, nil
+			}
+		}
+		comparison, ok := compareCheckValues(value, expected)
+		if !ok {
+			return false, fmt.Errorf("values of field '%s' can't be compared", fieldName)
+		}
+		switch op {
+		case "EQ":
+			return comparison == 0, nil
+		case "NE":
+			return comparison != 0, nil
+		case "LT":
+			return comparison < 0, nil
+		case "LE":
+			return comparison <= 0, nil
+		case "GT":
+			return comparison > 0, nil
+		default:
+			return comparison >= 0, nil
+		}
+	default:
+		return false, fmt.Errorf("operator '%s' is not supported in checks", node.Op)
+	}
+}
+
+//Compares values of the same kind, false is returned if they are not comparable
+func compareCheckValues(a, b interface{}) (int, bool) {
+	if aNumber, ok := checkNumberValue(a); ok {
+		if bNumber, ok := checkNumberValue(b); ok {
+			switch {
+			case aNumber < bNumber:
+				return -1, true
+			case aNumber > bNumber:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+		return 0, false
+	}
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	case bool:
+		if b, ok := b.(bool); ok && a == b {
+			return 0, true
+		} else if ok {
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+func checkNumberValue(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,119 @@
+package object_test
+
+import (
+	"custodian/server/auth"
+	"custodian/server/errors"
+	"custodian/server/object"
+	"custodian/server/object/description"
+	objectErrors "custodian/server/object/errors"
+	"custodian/utils"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validation rules", func() {
+	appConfig := utils.GetConfig()
+	db, _ := object.NewDbConnection(appConfig.DbConnectionUrl)
+
+	dbTransactionManager := object.NewPgDbTransactionManager(db)
+
+	metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, object.NewCache(), db)
+	metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
+	dataProcessor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+
+	AfterEach(func() {
+		err := metaStore.Flush()
+		Expect(err).To(BeNil())
+	})
+
+	havingObjectWithValidationRules := func() *object.Meta {
+		minLength, maxLength := 2, 5
+		min, max := 0.0, 100.0
+		metaDescription := description.MetaDescription{
+			Name: utils.RandomString(8),
+			Key:  "id",
+			Cas:  false,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name:      "code",
+					Type:      description.FieldTypeString,
+					MinLength: &minLength,
+					MaxLength: &maxLength,
+					Pattern:   "^[A-Z]+$",
+				},
+				{
+					Name: "amount",
+					Type: description.FieldTypeNumber,
+					Min:  &min,
+					Max:  &max,
+				},
+				{
+					Name:     "status",
+					Type:     description.FieldTypeString,
+					Optional: true,
+				},
+			},
+			Checks: []description.Check{
+				{
+					Name:       "closed_has_amount",
+					Expression: "or(ne(status,closed),gt(amount,0))",
+					Message:    "Closed record must have positive amount",
+				},
+			},
+		}
+		metaObj, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(metaObj)
+		Expect(err).To(BeNil())
+		return metaObj
+	}
+
+	It("Creates the record which satisfies the rules", func() {
+		metaObj := havingObjectWithValidationRules()
+
+		record, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"code": "AB", "amount": 10}, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(record.Data["code"]).To(Equal("AB"))
+	})
+
+	It("Returns errors for each violated field rule", func() {
+		metaObj := havingObjectWithValidationRules()
+
+		_, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"code": "abcdef", "amount": 101}, auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(objectErrors.ErrValidationRuleViolation))
+		violations := err.(*errors.ServerError).Data.([]map[string]string)
+		Expect(violations).To(HaveLen(3))
+		Expect(violations[0]["rule"]).To(Equal("maxLength"))
+		Expect(violations[1]["rule"]).To(Equal("pattern"))
+		Expect(violations[2]["field"]).To(Equal("amount"))
+		Expect(violations[2]["rule"]).To(Equal("max"))
+	})
+
+	It("Evaluates checks against the stored values on update", func() {
+		metaObj := havingObjectWithValidationRules()
+
+		record, err := dataProcessor.CreateRecord(metaObj.Name, map[string]interface{}{"code": "AB", "amount": 0}, auth.User{})
+		Expect(err).To(BeNil())
+
+		_, err = dataProcessor.UpdateRecord(metaObj.Name, record.PkAsString(), map[string]interface{}{"status": "closed"}, auth.User{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.(*errors.ServerError).Code).To(Equal(objectErrors.ErrValidationRuleViolation))
+		violations := err.(*errors.ServerError).Data.([]map[string]string)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0]["check"]).To(Equal("closed_has_amount"))
+		Expect(violations[0]["msg"]).To(Equal("Closed record must have positive amount"))
+
+		_, err = dataProcessor.UpdateRecord(metaObj.Name, record.PkAsString(), map[string]interface{}{"status": "closed", "amount": 5}, auth.User{})
+		Expect(err).To(BeNil())
+	})
+})
@@ -31,6 +31,13 @@ func (vs *ValidationService) Validate(record *Record) ([]*RecordProcessingNode,
 		if fieldDescription.LinkType == description.LinkTypeOuter && !fieldDescription.RetrieveMode {
 			continue
 		}
+		if fieldDescription.IsComputed() || fieldDescription.Rollup != nil {
+			if _, valueIsSet := record.Data[fieldName]; valueIsSet {
+				return nil, nil, nil, nil, errors2.NewValidationError(
+					errors.ErrComputedFieldReadOnly, fmt.Sprintf("Computed field '%s' is read-only", fieldName), map[string]string{"field": fieldName})
+			}
+			continue
+		}
 
 		value, valueIsSet := record.Data[fieldName]
 		if !valueIsSet && !fieldDescription.Optional && record.IsPhantom() {
@@ -89,6 +96,10 @@ func (vs *ValidationService) Validate(record *Record) ([]*RecordProcessingNode,
 							fmt.Sprintf("value '%s' is not in enum choices %s", value, fieldDescription.Enum),
 							map[string]string{"field": fieldName})
 					}
+				} else if fieldDescription.Type == description.FieldTypeJSON {
+					record.Data[fieldDescription.Name] = JSONValue{Document: value}
+				} else if fieldDescription.Type == description.FieldTypeStringArray || fieldDescription.Type == description.FieldTypeNumberArray {
+					record.Data[fieldDescription.Name] = ArrayValue(value.([]interface{}))
 				}
 			case fieldDescription.Type == description.FieldTypeObject && fieldDescription.LinkType == description.LinkTypeInner && (fieldDescription.LinkMeta.Key.Type.AssertType(value) || fieldDescription.Optional && value == nil):
 				record.Data[fieldDescription.Name] = DLink{Field: fieldDescription.LinkMeta.Key, IsOuter: false, Id: value}
@@ -124,6 +135,9 @@ func (vs *ValidationService) Validate(record *Record) ([]*RecordProcessingNode,
 			}
 		}
 	}
+	if err := vs.validateRules(record); err != nil {
+		return nil, nil, nil, nil, err
+	}
 	return nodesToRetrieveBefore, nodesToProcessBefore, nodesToProcessAfter, nodesToRemoveBefore, nil
 }
 
@@ -152,35 +166,27 @@ func (vs *ValidationService) validateArray(value interface{}, fieldDescription *
 
 	//get records which are not presented in data
 	if !record.IsPhantom() {
-		idFilters := ""
+		var ids []string
 		for _, record := range recordsToProcess {
 			if !record.IsPhantom() {
 				idAsString, _ := record.Meta.Key.ValueAsString(record.Pk())
-				if len(idFilters) != 0 {
-					idFilters += ","
-				}
-				idFilters += idAsString
+				ids = append(ids, idAsString)
 			}
 		}
-		var filter string
-
-		if len(idFilters) > 0 {
+		// update data without existing records
+		filter := newRqlRootNode(newEqRqlNode(fieldDescription.OuterLinkField.Name, record.PkAsString()))
+		if len(ids) > 0 {
 			// update data with existing records
-			filter = fmt.Sprintf("eq(%s,%s),not(in(%s,(%s)))", fieldDescription.OuterLinkField.Name, record.PkAsString(), fieldDescription.LinkMeta.Key.Name, idFilters)
-		} else {
-			// update data without existing records
-			filter = fmt.Sprintf("eq(%s,%s)", fieldDescription.OuterLinkField.Name, record.PkAsString())
+			filter = newRqlRootNode(filter.Node, newNotRqlNode(newInRqlNode(fieldDescription.LinkMeta.Key.Name, ids)))
 		}
 
-		if len(filter) > 0 {
-			_, records, _ := vs.processor.GetBulk(fieldDescription.LinkMeta.Name, filter, nil, nil, 1, true)
-			if *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteCascade || *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteRestrict {
-				recordsToRemove = records
-			} else if *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteSetNull {
-				for _, item := range records {
-					item.Data[fieldDescription.OuterLinkField.Name] = nil
-					recordsToProcess = append(recordsToProcess, item)
-				}
+		_, records, _ := vs.processor.getBulkByRql(fieldDescription.LinkMeta.Name, filter, nil, nil, nil, 1, true)
+		if *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteCascade || *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteRestrict {
+			recordsToRemove = records
+		} else if *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteSetNull {
+			for _, item := range records {
+				item.Data[fieldDescription.OuterLinkField.Name] = nil
+				recordsToProcess = append(recordsToProcess, item)
 			}
 		}
 		if len(recordsToRemove) > 0 {
@@ -275,34 +281,30 @@ func (vs *ValidationService) validateObjectsFieldArray(value interface{}, fieldD
 		}
 	}
 
-	beingAddedIds := ""
+	var beingAddedIds []string
 
 	for _, record := range recordsToProcess {
 		if pkValue, ok := record.Data[fieldDescription.LinkMeta.Name].(interface{}); ok {
 			idAsString, _ := fieldDescription.LinkMeta.Key.ValueAsString(pkValue)
 			if idAsString != "" {
-				if len(beingAddedIds) != 0 {
-					beingAddedIds += ","
-				}
-				beingAddedIds += idAsString
+				beingAddedIds = append(beingAddedIds, idAsString)
 			}
 		}
 	}
 
 	//get records which are not presented in data and should be removed from m2m relation
 	if !record.IsPhantom() {
-		filter := fmt.Sprintf("eq(%s,%s)", fieldDescription.Meta.Name, record.PkAsString())
+		filter := newRqlRootNode(newEqRqlNode(fieldDescription.Meta.Name, record.PkAsString()))
 		if len(beingAddedIds) > 0 {
-			excludeFilter := fmt.Sprintf("not(in(%s,(%s)))", fieldDescription.LinkMeta.Name, beingAddedIds)
-			filter = fmt.Sprintf("%s,%s", filter, excludeFilter)
+			filter = newRqlRootNode(filter.Node, newNotRqlNode(newInRqlNode(fieldDescription.LinkMeta.Name, beingAddedIds)))
 		}
-		_, recordsToRemove, _ = vs.processor.GetBulk(fieldDescription.LinkThrough.Name, filter, nil, nil, 1, true)
+		_, recordsToRemove, _ = vs.processor.getBulkByRql(fieldDescription.LinkThrough.Name, filter, nil, nil, nil, 1, true)
 	}
 	//get records which are already attached and remove them from list of records to process
 	if !record.IsPhantom() {
 		if len(beingAddedIds) > 0 {
-			filter := fmt.Sprintf("eq(%s,%s),in(%s,(%s))", fieldDescription.Meta.Name, record.PkAsString(), fieldDescription.LinkMeta.Name, beingAddedIds)
-			_, toExclude, _ := vs.processor.GetBulk(fieldDescription.LinkThrough.Name, filter, nil, nil, 1, true)
+			filter := newRqlRootNode(newEqRqlNode(fieldDescription.Meta.Name, record.PkAsString()), newInRqlNode(fieldDescription.LinkMeta.Name, beingAddedIds))
+			_, toExclude, _ := vs.processor.getBulkByRql(fieldDescription.LinkThrough.Name, filter, nil, nil, nil, 1, true)
 			for _, obj := range toExclude {
 				removedCount := 0
 				for i := range recordsToProcess {
@@ -362,35 +364,27 @@ func (vs *ValidationService) validateGenericArray(value interface{}, fieldDescri
 
 	//get records which are not presented in data
 	if !record.IsPhantom() {
-		idFilters := ""
+		var ids []string
 		for _, record := range recordsToProcess {
 			if !record.IsPhantom() {
 				idAsString, _ := record.Meta.Key.ValueAsString(record.Pk())
-				if len(idFilters) != 0 {
-					idFilters += ","
-				}
-				idFilters += idAsString
+				ids = append(ids, idAsString)
 			}
 		}
-		var filter string
-
-		if len(idFilters) > 0 {
+		// update data without existing records
+		filter := newRqlRootNode(newEqRqlNode(fieldDescription.OuterLinkField.Name+"."+record.Meta.Name+"."+record.Meta.Key.Name, record.PkAsString()))
+		if len(ids) > 0 {
 			// update data with existing records
-			filter = fmt.Sprintf("eq(%s.%s.%s,%s),not(in(%s,(%s)))", fieldDescription.OuterLinkField.Name, record.Meta.MetaDescription.Name, record.Meta.MetaDescription.Key, record.PkAsString(), fieldDescription.LinkMeta.Key.Name, idFilters)
-		} else {
-			// update data without existing records
-			filter = fmt.Sprintf("eq(%s.%s.%s,%s)", fieldDescription.OuterLinkField.Name, record.Meta.MetaDescription.Name, record.Meta.MetaDescription.Key, record.PkAsString())
+			filter = newRqlRootNode(filter.Node, newNotRqlNode(newInRqlNode(fieldDescription.LinkMeta.Key.Name, ids)))
 		}
 
-		if len(filter) > 0 {
-			_, records, _ := vs.processor.GetBulk(fieldDescription.LinkMeta.Name, filter, nil, nil, 1, true)
-			if *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteCascade || *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteRestrict {
-				recordsToRemove = records
-			} else if *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteSetNull {
-				for _, item := range records {
-					item.Data[fieldDescription.OuterLinkField.Name] = nil
-					recordsToProcess = append(recordsToProcess, item)
-				}
+		_, records, _ := vs.processor.getBulkByRql(fieldDescription.LinkMeta.Name, filter, nil, nil, nil, 1, true)
+		if *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteCascade || *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteRestrict {
+			recordsToRemove = records
+		} else if *fieldDescription.OuterLinkField.OnDeleteStrategy() == description.OnDeleteSetNull {
+			for _, item := range records {
+				item.Data[fieldDescription.OuterLinkField.Name] = nil
+				recordsToProcess = append(recordsToProcess, item)
 			}
 		}
 		if len(recordsToRemove) > 0 {
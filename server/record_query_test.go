@@ -4,9 +4,11 @@ import (
 	"custodian/server/auth"
 	"custodian/server/object"
 	"custodian/utils"
+	"encoding/csv"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -226,6 +228,53 @@ var _ = Describe("Server 101", func() {
 			Expect(body["total_count"].(float64)).To(Equal(float64(20)))
 		})
 
+		It("exports records by query as NDJSON", func() {
+			for _, name := range []string{"A", "B", "B"} {
+				_, err := dataProcessor.CreateRecord(aMetaObj.Name, map[string]interface{}{"name": name}, auth.User{})
+				Expect(err).To(BeNil())
+			}
+
+			url := fmt.Sprintf("%s/data/%s/export?format=ndjson&q=eq(name,B)&only=name", appConfig.UrlPrefix, aMetaObj.Name)
+
+			var request, _ = http.NewRequest("GET", url, nil)
+			httpServer.Handler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+			lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			var record map[string]interface{}
+			json.Unmarshal([]byte(lines[0]), &record)
+			Expect(record["name"]).To(Equal("B"))
+			Expect(record).NotTo(HaveKey("description"))
+		})
+
+		It("exports records as CSV", func() {
+			_, err := dataProcessor.CreateRecord(aMetaObj.Name, map[string]interface{}{"name": "A, the first", "description": "first"}, auth.User{})
+			Expect(err).To(BeNil())
+
+			url := fmt.Sprintf("%s/data/%s/export?format=csv", appConfig.UrlPrefix, aMetaObj.Name)
+
+			var request, _ = http.NewRequest("GET", url, nil)
+			httpServer.Handler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			rows, err := csv.NewReader(recorder.Body).ReadAll()
+			Expect(err).To(BeNil())
+			Expect(rows).To(HaveLen(2))
+			Expect(rows[0]).To(Equal([]string{"id", "name", "description"}))
+			Expect(rows[1][1:]).To(Equal([]string{"A, the first", "first"}))
+		})
+
+		It("does not export records in unknown format", func() {
+			url := fmt.Sprintf("%s/data/%s/export?format=xml", appConfig.UrlPrefix, aMetaObj.Name)
+
+			var request, _ = http.NewRequest("GET", url, nil)
+			httpServer.Handler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+
 		It("returns same records using different queries", func() {
 			for i := 0; i < 20; i++ {
 				_, err := dataProcessor.CreateRecord(aMetaObj.Name, map[string]interface{}{"name": "A"}, auth.User{})
@@ -398,4 +398,67 @@ var _ = Describe("Server", func() {
 		})
 	})
 
+	It("updates CAS-enabled record only if actual version is specified", func() {
+		metaDescription := description.MetaDescription{
+			Name: "a",
+			Key:  "id",
+			Cas:  true,
+			Fields: []description.Field{
+				{
+					Name:     "id",
+					Type:     description.FieldTypeNumber,
+					Optional: true,
+					Def: map[string]interface{}{
+						"func": "nextval",
+					},
+				},
+				{
+					Name:     "name",
+					Type:     description.FieldTypeString,
+					Optional: true,
+				},
+			},
+		}
+		(&description.NormalizationService{}).Normalize(&metaDescription)
+		objectA, err := metaStore.NewMeta(&metaDescription)
+		Expect(err).To(BeNil())
+		err = metaStore.Create(objectA)
+		Expect(err).To(BeNil())
+
+		record, err := dataProcessor.CreateRecord(objectA.Name, map[string]interface{}{"name": "SomeName"}, auth.User{})
+		Expect(err).To(BeNil())
+		Expect(record.Data["cas"]).To(Equal(float64(1)))
+
+		url := fmt.Sprintf("%s/data/%s/%d", appConfig.UrlPrefix, objectA.Name, int(record.Data["id"].(float64)))
+		patch := func(ifMatch string, data map[string]interface{}) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			encodedData, _ := json.Marshal(data)
+			var request, _ = http.NewRequest("PATCH", url, bytes.NewBuffer(encodedData))
+			request.Header.Set("Content-Type", "application/json")
+			if ifMatch != "" {
+				request.Header.Set("If-Match", ifMatch)
+			}
+			httpServer.Handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		Context("update without version is rejected", func() {
+			Expect(patch("", map[string]interface{}{"name": "OtherName"}).Code).To(Equal(http.StatusBadRequest))
+		})
+
+		Context("update with actual version passes and returns the next version", func() {
+			response := patch("\"1\"", map[string]interface{}{"name": "OtherName"})
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(response.Header().Get("ETag")).To(Equal("\"2\""))
+		})
+
+		Context("update with stale version is rejected with conflict", func() {
+			response := patch("", map[string]interface{}{"name": "StaleName", "cas": 1})
+			Expect(response.Code).To(Equal(http.StatusConflict))
+
+			var body map[string]interface{}
+			json.Unmarshal(response.Body.Bytes(), &body)
+			Expect(body["error"].(map[string]interface{})["Data"]).To(Equal(map[string]interface{}{"cas": float64(2)}))
+		})
+	})
 })
@@ -7,12 +7,14 @@ import (
 	"custodian/server/abac"
 	"custodian/server/auth"
 	. "custodian/server/errors"
+	"custodian/server/graphql"
 	migrations_description "custodian/server/migrations/description"
 	"custodian/server/object"
 	"custodian/server/object/description"
 	"custodian/server/object/migrations/managers"
 	"custodian/server/transactions"
 	"custodian/utils"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -62,6 +64,8 @@ func (app *CustodianApp) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			} else {
 				if splited[2] == "meta" {
 					res = "meta"
+				} else if splited[2] == "outbox" {
+					res = "outbox"
 				} else {
 					res = "*"
 				}
@@ -108,6 +112,7 @@ type CustodianServer struct {
 	db               string
 	auth_url         string
 	authenticator    auth.Authenticator
+	outbox           *object.NotificationOutbox
 }
 
 func New(host, port, urlPrefix, databaseConnectionOptions string) *CustodianServer {
@@ -130,6 +135,11 @@ func (cs *CustodianServer) SetAuthenticator(authenticator auth.Authenticator) {
 	cs.authenticator = authenticator
 }
 
+//Start delivering notifications from the outbox, the server must be set up
+func (cs *CustodianServer) StartOutbox() {
+	cs.outbox.Start()
+}
+
 //TODO: "enableProfiler" option should be configured like other options
 func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 	if cs.authenticator == nil {
@@ -150,12 +160,15 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 
 	migrationManager := managers.NewMigrationManager(metaDescriptionSyncer, dbTransactionManager, db)
 
+	cs.outbox = object.NewNotificationOutbox(db)
+
 	getDataProcessor := func() *object.Processor {
 		dbTransactionManager := object.NewPgDbTransactionManager(db)
 		metaDescriptionSyncer := object.NewPgMetaDescriptionSyncer(dbTransactionManager, metaCache, db)
 		metaStore := object.NewStore(metaDescriptionSyncer, dbTransactionManager)
 
 		processor, _ := object.NewProcessor(metaStore, dbTransactionManager)
+		processor.UseOutbox()
 		return processor
 	}
 
@@ -245,6 +258,36 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 		if i, e := strconv.Atoi(r.URL.Query().Get("depth")); e == nil {
 			depth = i
 		}
+		//records are upserted by the listed fields or by the key if the list is empty
+		var upsertFields []string
+		if _, ok := q["upsert"]; ok {
+			upsertFields = make([]string, 0)
+			if upsert := q.Get("upsert"); upsert != "" {
+				upsertFields = strings.Split(upsert, ",")
+			}
+		}
+		//existing records matched by the upsert are updated, so they are checked as the updated ones
+		authorizeUpdate := func(target *object.Record, recordData map[string]interface{}) error {
+			recordToUpdate, err := dataProcessor.Get(objectName, target.PkAsString(), nil, nil, 1, true)
+			if err != nil {
+				return err
+			}
+			if recordToUpdate == nil {
+				recordToUpdate = target
+			}
+			pass, rule := abac_resolver.CheckRecord(recordToUpdate, "data_PATCH")
+			if !pass {
+				return abac.NewError("Permission denied")
+			}
+			if rule != nil {
+				if restricted := abac.CheckMask(recordData, rule.Mask); len(restricted) > 0 {
+					return abac.NewError(
+						fmt.Sprintf("Updating fields [%s] restricted by ABAC rule", strings.Join(restricted, ",")),
+					)
+				}
+			}
+			return nil
+		}
 		if src.single != nil {
 			if rule != nil {
 				restricted := abac.CheckMask(src.single, rule.Mask)
@@ -259,7 +302,14 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 				}
 			}
 
-			if record, err := dataProcessor.CreateRecord(objectName, src.single, user); err != nil {
+			var record *object.Record
+			var err error
+			if upsertFields != nil {
+				record, err = dataProcessor.UpsertRecord(objectName, src.single, upsertFields, authorizeUpdate, user)
+			} else {
+				record, err = dataProcessor.CreateRecord(objectName, src.single, user)
+			}
+			if err != nil {
 				sink.pushError(err)
 			} else {
 				pkValue, _ := record.Meta.Key.ValueAsString(record.Data[record.Meta.Key.Name])
@@ -272,19 +322,25 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 
 		} else if src.list != nil {
 
-			records, e := dataProcessor.BulkCreateRecords(p.ByName("name"), src.list, user)
+			var records []*object.Record
+			var e error
+			if upsertFields != nil {
+				records, e = dataProcessor.BulkUpsertRecords(objectName, src.list, upsertFields, authorizeUpdate, user)
+			} else {
+				records, e = dataProcessor.BulkCreateRecords(objectName, src.list, user)
+			}
 
 			if e != nil {
 				sink.pushError(e)
 			} else {
 				result := make([]interface{}, 0)
 
-				var ids []string
+				var keys []interface{}
 				for _, obj := range records {
-					ids = append(ids, fmt.Sprint(obj.GetData()["id"]))
+					keys = append(keys, obj.Pk())
 				}
-				count, records, e := dataProcessor.GetBulk(
-					p.ByName("name"), fmt.Sprintf("in(id,(%s))", strings.Join(ids, ",")), r.URL.Query()["only"], r.URL.Query()["exclude"], depth, false,
+				count, records, e := dataProcessor.GetBulkByKeys(
+					p.ByName("name"), keys, r.URL.Query()["only"], r.URL.Query()["exclude"], depth, false,
 				)
 				if e != nil {
 					sink.pushError(e)
@@ -299,7 +355,76 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 
 	}))
 
+	exportRecords := func(sink *JsonSink, p httprouter.Params, q url.Values, request *http.Request) {
+		dataProcessor := getDataProcessor()
+		abac_resolver := request.Context().Value("abac").(abac.TroodABAC)
+		objectName := p.ByName("name")
+
+		format := q.Get("format")
+		if format == "" {
+			format = FormatNDJSON
+		}
+		if format != FormatNDJSON && format != FormatCSV {
+			sink.pushError(&ServerError{http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Export format '%s' is unknown, expected: ndjson or csv", format), nil})
+			return
+		}
+		objectMeta, e := dataProcessor.GetMeta(objectName)
+		if e != nil {
+			sink.pushError(e)
+			return
+		}
+
+		var depth = 1
+		if i, e := strconv.Atoi(q.Get("depth")); e == nil {
+			depth = i
+		}
+
+		var filters []string
+		_, rule := abac_resolver.Check(objectName, "data_LIST")
+		if rule != nil && rule.Filter != nil {
+			if rule.Result == "deny" {
+				filters = append(filters, rule.Filter.Invert().String())
+			} else {
+				filters = append(filters, rule.Filter.String())
+			}
+		}
+		if user_filters := q.Get("q"); user_filters != "" {
+			filters = append(filters, user_filters)
+		}
+		if len(q.Get("with_deleted")) > 0 {
+			filters = append(filters, "with_deleted()")
+		}
+
+		exportSink := newExportSink(sink.rw, format, objectMeta)
+		e = dataProcessor.Export(objectName, strings.Join(filters, ","), q["only"], q["exclude"], depth, len(q.Get("omit_outers")) > 0, func(records []*object.Record) error {
+			result := make([]map[string]interface{}, 0, len(records))
+			for _, obj := range records {
+				if pass, rec := abac_resolver.MaskRecord(obj, "data_LIST"); pass {
+					result = append(result, rec.(*object.Record).GetData())
+				}
+			}
+			return exportSink.push(result)
+		})
+		if e == nil {
+			e = exportSink.close()
+		}
+		if e != nil {
+			if exportSink.started {
+				//the response is already started, so that it can only be interrupted
+				logger.Error("Export of '%s' failed: %s", objectName, e.Error())
+			} else {
+				sink.pushError(e)
+			}
+		}
+	}
+
 	app.router.GET(cs.root+"/data/:name/:key", CreateJsonAction(func(r *JsonSource, sink *JsonSink, p httprouter.Params, q url.Values, request *http.Request) {
+		//export shares the path with records, since the router doesn't allow static and wildcard segments at the same position
+		if p.ByName("key") == "export" {
+			exportRecords(sink, p, q, request)
+			return
+		}
+
 		dataProcessor := getDataProcessor()
 
 		var depth = 2
@@ -312,7 +437,13 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 			omitOuters = true
 		}
 
-		if o, e := dataProcessor.Get(p.ByName("name"), p.ByName("key"), q["only"], q["exclude"], depth, omitOuters); e != nil {
+		getRecord := dataProcessor.Get
+		//records marked as deleted are retrieved only if explicitly requested
+		if len(q.Get("with_deleted")) > 0 {
+			getRecord = dataProcessor.GetWithDeleted
+		}
+
+		if o, e := getRecord(p.ByName("name"), p.ByName("key"), q["only"], q["exclude"], depth, omitOuters); e != nil {
 			sink.pushError(e)
 		} else {
 			if o == nil {
@@ -325,6 +456,7 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 					return
 				}
 
+				sink.setETag(o)
 				sink.pushObj(result.(*object.Record).GetData())
 			}
 		}
@@ -359,9 +491,51 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 			filters = append(filters, user_filters)
 		}
 
+		if len(q.Get("with_deleted")) > 0 {
+			filters = append(filters, "with_deleted()")
+		}
+
 		result := make([]interface{}, 0)
-		count, records, e := dataProcessor.GetBulk(
-			p.ByName("name"), strings.Join(filters, ","), q["only"], q["exclude"], depth, omitOuters,
+
+		if aggregation, e := object.ParseAggregation(user_filters); e != nil {
+			sink.pushError(e)
+			return
+		} else if aggregation != nil {
+			if rule != nil {
+				restricted := abac.CheckFieldsMask(aggregation.Fields(), rule.Mask)
+
+				if len(restricted) > 0 {
+					sink.pushError(
+						abac.NewError(
+							fmt.Sprintf("Aggregating fields [%s] restricted by ABAC rule", strings.Join(restricted, ",")),
+						),
+					)
+					return
+				}
+			}
+
+			count, groups, e := dataProcessor.Aggregate(p.ByName("name"), strings.Join(filters, ","))
+			if e != nil {
+				sink.pushError(e)
+			} else {
+				for _, group := range groups {
+					result = append(result, group)
+				}
+				sink.pushList(result, count)
+			}
+			return
+		}
+
+		countMode, e := object.ParseCountMode(q.Get("count"))
+		if e != nil {
+			sink.pushError(e)
+			return
+		}
+		//cursor parameter switches to the cursor pagination, its empty value requests the first page
+		_, byCursor := q["cursor"]
+		pagination := &object.Pagination{ByCursor: byCursor, Cursor: q.Get("cursor"), Count: countMode}
+		count, records, e := dataProcessor.GetBulkPage(
+			p.ByName("name"), strings.Join(filters, ","), pagination, q["only"], q["exclude"], depth, omitOuters,
 		)
 
 		if e != nil {
@@ -374,7 +548,7 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 				}
 			}
 
-			sink.pushList(result, count)
+			sink.pushPage(result, count, pagination)
 		}
 	}))
 
@@ -414,6 +588,20 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 		dataProcessor := getDataProcessor()
 
 		user := request.Context().Value("auth_user").(auth.User)
+		//records are removed by the filter instead of the list of keys
+		if _, ok := q["q"]; ok {
+			filter, e := filterForChange(request, p.ByName("name"), q, "data_DELETE")
+			if e != nil {
+				sink.pushError(e)
+				return
+			}
+			if count, e := dataProcessor.DeleteRecordsByFilter(p.ByName("name"), filter, user); e != nil {
+				sink.pushError(e)
+			} else {
+				sink.pushObj(map[string]interface{}{"count": count})
+			}
+			return
+		}
 		var i = 0
 		e := dataProcessor.BulkDeleteRecords(p.ByName("name"), func() (map[string]interface{}, error) {
 			if i < len(src.list) {
@@ -433,6 +621,223 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 
 	}))
 
+	app.router.POST(cs.root+"/data/:name/:key", CreateJsonAction(func(_ *JsonSource, sink *JsonSink, p httprouter.Params, q url.Values, r *http.Request) {
+		//import shares the path with records, since the router doesn't allow static and wildcard segments at the same position
+		if p.ByName("key") != "import" {
+			sink.pushError(&ServerError{http.StatusNotFound, ErrNotFound, "Not found", nil})
+			return
+		}
+		dataProcessor := getDataProcessor()
+		user := r.Context().Value("auth_user").(auth.User)
+		objectName := p.ByName("name")
+
+		abac_resolver := r.Context().Value("abac").(abac.TroodABAC)
+		_, rule := abac_resolver.Check(objectName, "data_POST")
+
+		mode, e := object.ParseImportMode(q.Get("mode"))
+		if e != nil {
+			sink.pushError(e)
+			return
+		}
+		objectMeta, e := dataProcessor.GetMeta(objectName)
+		if e != nil {
+			sink.pushError(e)
+			return
+		}
+
+		//format is taken from the content type unless it is specified explicitly
+		format := q.Get("format")
+		if format == "" {
+			if mediaType, _, e := mime.ParseMediaType(r.Header.Get("Content-Type")); e == nil && mediaType == "text/csv" {
+				format = FormatCSV
+			} else {
+				format = FormatNDJSON
+			}
+		}
+		var next func() (map[string]interface{}, error)
+		switch format {
+		case FormatNDJSON:
+			next = object.NewNDJSONImportReader(r.Body)
+		case FormatCSV:
+			if next, e = object.NewCSVImportReader(r.Body, objectMeta); e != nil {
+				sink.pushError(e)
+				return
+			}
+		default:
+			sink.pushError(&ServerError{http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Import format '%s' is unknown, expected: ndjson or csv", format), nil})
+			return
+		}
+
+		if rule != nil {
+			read := next
+			next = func() (map[string]interface{}, error) {
+				recordData, err := read()
+				if recordData != nil {
+					if restricted := abac.CheckMask(recordData, rule.Mask); len(restricted) > 0 {
+						return nil, &object.ImportRowError{Err: abac.NewError(
+							fmt.Sprintf("Creating object with fields [%s] restricted by ABAC rule", strings.Join(restricted, ",")),
+						)}
+					}
+				}
+				return recordData, err
+			}
+		}
+
+		if report, e := dataProcessor.ImportRecords(objectName, next, mode, user); e != nil {
+			sink.pushError(e)
+		} else {
+			sink.pushObj(report)
+		}
+	}))
+
+	app.router.POST(cs.root+"/batch", CreateJsonAction(func(src *JsonSource, sink *JsonSink, p httprouter.Params, q url.Values, r *http.Request) {
+		if src == nil || src.list == nil {
+			sink.pushError(&ServerError{http.StatusBadRequest, ErrBadRequest, "Batch must be a list of operations", nil})
+			return
+		}
+		operations, e := object.ParseBatchOperations(src.list)
+		if e != nil {
+			sink.pushError(e)
+			return
+		}
+		dataProcessor := getDataProcessor()
+		user := r.Context().Value("auth_user").(auth.User)
+		abac_resolver := r.Context().Value("abac").(abac.TroodABAC)
+
+		//access is checked the same way it is checked for the single record endpoints
+		authorize := func(operation *object.BatchOperation, record *object.Record) error {
+			var pass bool
+			var rule *abac.RuleABAC
+			switch operation.Method {
+			case object.BatchMethodCreate:
+				pass, rule = abac_resolver.Check(operation.Object, "data_POST")
+			case object.BatchMethodUpdate:
+				pass, rule = abac_resolver.CheckRecord(record, "data_PATCH")
+			case object.BatchMethodDelete:
+				pass, _ = abac_resolver.CheckRecord(record, "data_DELETE")
+			}
+			if !pass {
+				return abac.NewError("Permission denied")
+			}
+			if rule != nil {
+				if restricted := abac.CheckMask(operation.Data, rule.Mask); len(restricted) > 0 {
+					return abac.NewError(fmt.Sprintf("Fields [%s] restricted by ABAC rule", strings.Join(restricted, ",")))
+				}
+			}
+			return nil
+		}
+
+		if results, e := dataProcessor.ExecuteBatch(operations, authorize, user); e != nil {
+			sink.pushError(e)
+		} else {
+			sink.pushObj(results)
+		}
+	}))
+
+	graphqlSchemas := newGraphQLSchemaCache(metaCache, graphql.Limits{MaxDepth: config.GraphQLMaxDepth, MaxComplexity: config.GraphQLMaxComplexity})
+	app.router.POST(cs.root+"/graphql", CreateJsonAction(func(src *JsonSource, sink *JsonSink, p httprouter.Params, q url.Values, r *http.Request) {
+		request := &graphql.Request{}
+		if src == nil || src.single == nil || json.Unmarshal(src.body, request) != nil || request.Query == "" {
+			sink.pushError(&ServerError{http.StatusBadRequest, ErrBadRequest, "Request must be an object with the query", nil})
+			return
+		}
+		schema, e := graphqlSchemas.get()
+		if e != nil {
+			sink.pushError(e)
+			return
+		}
+		resolveContext := &graphqlContext{
+			processor:    getDataProcessor(),
+			user:         r.Context().Value("auth_user").(auth.User),
+			abacResolver: r.Context().Value("abac").(abac.TroodABAC),
+		}
+		//the result is returned as is, since GraphQL clients expect data and errors at the top level
+		if encodedData, err := json.Marshal(schema.Execute(request, resolveContext)); err != nil {
+			sink.pushError(err)
+		} else {
+			sink.rw.Header().Set("Content-Type", "application/json")
+			sink.rw.WriteHeader(http.StatusOK)
+			sink.rw.Write(encodedData)
+		}
+	}))
+
+	app.router.POST(cs.root+"/data/:name/:key/restore", CreateJsonAction(func(src *JsonSource, sink *JsonSink, p httprouter.Params, q url.Values, r *http.Request) {
+		dataProcessor := getDataProcessor()
+		user := r.Context().Value("auth_user").(auth.User)
+
+		objectName := p.ByName("name")
+		recordPkValue := p.ByName("key")
+
+		if _, err := dataProcessor.GetMeta(objectName); err != nil {
+			sink.pushError(err)
+			return
+		}
+
+		//restoring is permitted to those who can delete the record
+		record, err := dataProcessor.GetWithDeleted(objectName, recordPkValue, nil, nil, 1, true)
+		if err != nil || record == nil {
+			sink.pushError(NewNotFoundError(ErrNotFound, "record not found", nil))
+			return
+		}
+		abac_resolver := r.Context().Value("abac").(abac.TroodABAC)
+		if pass, _ := abac_resolver.CheckRecord(record, "data_DELETE"); !pass {
+			sink.pushError(abac.NewError("Permission denied"))
+			return
+		}
+
+		if _, e := dataProcessor.RestoreRecord(objectName, recordPkValue, user); e != nil {
+			sink.pushError(e)
+			return
+		}
+
+		var depth = 1
+		if i, e := strconv.Atoi(q.Get("depth")); e == nil {
+			depth = i
+		}
+		if recordData, err := dataProcessor.Get(objectName, recordPkValue, q["only"], q["exclude"], depth, false); err != nil {
+			sink.pushError(err)
+		} else if recordData == nil {
+			sink.pushError(NewNotFoundError(ErrNotFound, "record not found", nil))
+		} else {
+			sink.pushObj(recordData.GetData())
+		}
+	}))
+
+	app.router.GET(cs.root+"/data/:name/:key/history", CreateJsonAction(func(_ *JsonSource, sink *JsonSink, p httprouter.Params, q url.Values, r *http.Request) {
+		dataProcessor := getDataProcessor()
+
+		objectName := p.ByName("name")
+		recordPkValue := p.ByName("key")
+
+		if _, err := dataProcessor.GetMeta(objectName); err != nil {
+			sink.pushError(err)
+			return
+		}
+
+		//history is available to those who can retrieve the record, removed records are checked by the object rules only
+		abac_resolver := r.Context().Value("abac").(abac.TroodABAC)
+		var pass bool
+		if record, err := dataProcessor.GetWithDeleted(objectName, recordPkValue, nil, nil, 1, true); err == nil && record != nil {
+			pass, _ = abac_resolver.CheckRecord(record, "data_GET")
+		} else {
+			pass, _ = abac_resolver.Check(objectName, "data_GET")
+		}
+		if !pass {
+			sink.pushError(abac.NewError("Permission denied"))
+			return
+		}
+
+		if total, entries, err := dataProcessor.GetHistory(objectName, recordPkValue, q.Get("q")); err != nil {
+			sink.pushError(err)
+		} else {
+			result := make([]interface{}, 0, len(entries))
+			for _, entry := range entries {
+				result = append(result, entry)
+			}
+			sink.pushList(result, total)
+		}
+	}))
+
 	app.router.PATCH(cs.root+"/data/:name/:key", CreateJsonAction(func(src *JsonSource, sink *JsonSink, p httprouter.Params, u url.Values, r *http.Request) {
 		dataProcessor := getDataProcessor()
 		user := r.Context().Value("auth_user").(auth.User)
@@ -441,8 +846,9 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 
 		//process access check
 		recordToUpdate, err := dataProcessor.Get(objectName, recordPkValue, r.URL.Query()["only"], r.URL.Query()["exclude"], 1, true)
-		if err != nil {
+		if err != nil || recordToUpdate == nil {
 			sink.pushError(&ServerError{http.StatusNotFound, ErrNotFound, "record not found", nil})
+			return
 		} else {
 			abac_resolver := r.Context().Value("abac").(abac.TroodABAC)
 			pass, rule := abac_resolver.CheckRecord(recordToUpdate, "data_PATCH")
@@ -467,6 +873,13 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 
 		//end access check
 
+		//expected record version can be passed with If-Match header instead of "cas" value
+		if cas, ok := parseIfMatch(r.Header.Get("If-Match")); ok && src != nil && src.single != nil {
+			if _, ok := src.single[description.CasFieldName]; !ok {
+				src.single[description.CasFieldName] = cas
+			}
+		}
+
 		//TODO: building record data respecting "depth" argument should be implemented inside dataProcessor
 		//also "FillRecordValues" also should be moved from Node struct
 
@@ -481,6 +894,7 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 				if recordData, err := dataProcessor.Get(objectName, recordPkValue, r.URL.Query()["only"], r.URL.Query()["exclude"], depth, false); err != nil {
 					sink.pushError(err)
 				} else {
+					sink.setETag(recordData)
 					sink.pushObj(recordData.GetData())
 				}
 			} else {
@@ -494,6 +908,35 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 		dataProcessor := getDataProcessor()
 
 		user := request.Context().Value("auth_user").(auth.User)
+		//records matching the filter are updated with the patch instead of the list of records
+		if _, ok := q["q"]; ok {
+			if src == nil || src.single == nil {
+				sink.pushError(&ServerError{http.StatusBadRequest, ErrBadRequest, "Patch must be an object", nil})
+				return
+			}
+			filter, e := filterForChange(request, p.ByName("name"), q, "data_PATCH")
+			if e != nil {
+				sink.pushError(e)
+				return
+			}
+			abac_resolver := request.Context().Value("abac").(abac.TroodABAC)
+			if _, rule := abac_resolver.Check(p.ByName("name"), "data_PATCH"); rule != nil {
+				if restricted := abac.CheckMask(src.single, rule.Mask); len(restricted) > 0 {
+					sink.pushError(
+						abac.NewError(
+							fmt.Sprintf("Updating fields [%s] restricted by ABAC rule", strings.Join(restricted, ",")),
+						),
+					)
+					return
+				}
+			}
+			if count, e := dataProcessor.UpdateRecordsByFilter(p.ByName("name"), filter, src.single, user); e != nil {
+				sink.pushError(e)
+			} else {
+				sink.pushObj(map[string]interface{}{"count": count})
+			}
+			return
+		}
 		var i = 0
 		var result []interface{}
 		e := dataProcessor.BulkUpdateRecords(p.ByName("name"), func() (map[string]interface{}, error) {
@@ -513,12 +956,17 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 			if i, e := strconv.Atoi(url.QueryEscape(q.Get("depth"))); e == nil {
 				depth = i
 			}
-			var ids []string
+			objectMeta, e := dataProcessor.GetMeta(p.ByName("name"))
+			if e != nil {
+				sink.pushError(e)
+				return
+			}
+			var keys []interface{}
 			for _, obj := range result {
-				ids = append(ids, fmt.Sprint(int(obj.(map[string]interface{})["id"].(float64))))
+				keys = append(keys, obj.(map[string]interface{})[objectMeta.Key.Name])
 			}
-			count, record, e := dataProcessor.GetBulk(
-				p.ByName("name"), fmt.Sprintf("in(id,(%s))", strings.Join(ids, ",")), request.URL.Query()["only"], request.URL.Query()["exclude"], depth, false,
+			count, record, e := dataProcessor.GetBulkByKeys(
+				p.ByName("name"), keys, request.URL.Query()["only"], request.URL.Query()["exclude"], depth, false,
 			)
 			if e != nil {
 				sink.pushError(e)
@@ -701,6 +1149,52 @@ func (cs *CustodianServer) Setup(config *utils.AppConfig) *http.Server {
 		}
 	}))
 
+	//notifications which are waiting for delivery or ran out of attempts
+	app.router.GET(cs.root+"/outbox", CreateJsonAction(func(_ *JsonSource, sink *JsonSink, p httprouter.Params, q url.Values, request *http.Request) {
+		if err := checkOutboxAccess(request); err != nil {
+			sink.pushError(err)
+			return
+		}
+		status := object.OutboxStatusDead
+		if q.Get("status") != "" {
+			status = q.Get("status")
+		}
+		var limit, offset = 100, 0
+		if i, e := strconv.Atoi(q.Get("limit")); e == nil {
+			limit = i
+		}
+		if i, e := strconv.Atoi(q.Get("offset")); e == nil {
+			offset = i
+		}
+
+		if total, entries, err := cs.outbox.List(status, limit, offset); err != nil {
+			sink.pushError(err)
+		} else {
+			result := make([]interface{}, 0, len(entries))
+			for _, entry := range entries {
+				result = append(result, entry)
+			}
+			sink.pushList(result, total)
+		}
+	}))
+
+	app.router.POST(cs.root+"/outbox/:id/retry", CreateJsonAction(func(_ *JsonSource, sink *JsonSink, p httprouter.Params, q url.Values, request *http.Request) {
+		if err := checkOutboxAccess(request); err != nil {
+			sink.pushError(err)
+			return
+		}
+		id, err := strconv.Atoi(p.ByName("id"))
+		if err != nil {
+			sink.pushError(&ServerError{http.StatusNotFound, ErrNotFound, "outbox entry not found", nil})
+			return
+		}
+		if err := cs.outbox.Retry(id); err != nil {
+			sink.pushError(err)
+		} else {
+			sink.pushObj(nil)
+		}
+	}))
+
 	app.router.GET(cs.root+"/probe", CreateJsonAction(func(r *JsonSource, sink *JsonSink, p httprouter.Params, q url.Values, request *http.Request) {
 		now := int(time.Now().Unix())
 		probeData := map[string]interface{}{}
@@ -810,6 +1304,38 @@ func CreateJsonAction(f func(*JsonSource, *JsonSink, httprouter.Params, url.Valu
 	}
 }
 
+//Notifications of the outbox contain data of records of any object, so they are available to services only,
+//unless the access is granted to other users by the rule of the "outbox" resource
+func checkOutboxAccess(r *http.Request) error {
+	user := r.Context().Value("auth_user").(auth.User)
+	abac_resolver := r.Context().Value("abac").(abac.TroodABAC)
+	if pass, rule := abac_resolver.Check("outbox", r.Method); rule == nil && user.Type != "service" || !pass {
+		return abac.NewError("Permission denied")
+	}
+	return nil
+}
+
+//Returns the filter of records to update or delete, it is restricted by the filter of the ABAC rule of the action.
+//The filter is required, so that all records are never changed by mistake
+func filterForChange(r *http.Request, objectName string, q url.Values, action string) (string, error) {
+	userFilter := q.Get("q")
+	if userFilter == "" {
+		return "", &ServerError{http.StatusBadRequest, ErrBadRequest, "Filter must not be empty", nil}
+	}
+	var filters []string
+	abac_resolver := r.Context().Value("abac").(abac.TroodABAC)
+	_, rule := abac_resolver.Check(objectName, action)
+	if rule != nil && rule.Filter != nil {
+		if rule.Result == "deny" {
+			filters = append(filters, rule.Filter.Invert().String())
+		} else {
+			filters = append(filters, rule.Filter.String())
+		}
+	}
+	filters = append(filters, userFilter)
+	return strings.Join(filters, ","), nil
+}
+
 func parseQuery(m url.Values, query string) (err error) {
 
 	for query != "" {
@@ -939,14 +1465,69 @@ func (js *JsonSink) pushObj(object interface{}) {
 	}
 }
 
+//Exposes the version of CAS-enabled record as ETag header
+func (js *JsonSink) setETag(record *object.Record) {
+	if record == nil || !record.Meta.Cas {
+		return
+	}
+	if cas, ok := record.Data[description.CasFieldName]; ok && cas != nil {
+		js.rw.Header().Set("ETag", fmt.Sprintf("\"%v\"", cas))
+	}
+}
+
+//Parses the record version from If-Match header, the value is expected in the format of ETag header
+func parseIfMatch(header string) (float64, bool) {
+	header = strings.TrimPrefix(strings.TrimSpace(header), "W/")
+	if header == "" || header == "*" {
+		return 0, false
+	}
+	cas, err := strconv.ParseFloat(strings.Trim(header, "\""), 64)
+	if err != nil {
+		return 0, false
+	}
+	return cas, true
+}
+
 func (js *JsonSink) pushList(objects []interface{}, total int) {
+	js.pushListData(js.listData(objects, total))
+}
+
+//Pushes the page of the list, the count of records is returned according to the count mode of the pagination:
+//total_count for the exact one, estimated_count for the estimate and has_more flag for has_more.
+//Cursor of the next page is returned if the list is paginated by the cursor, it is null on the last page
+func (js *JsonSink) pushPage(objects []interface{}, total int, pagination *object.Pagination) {
+	responseData := js.listData(objects, total)
+	switch pagination.Count {
+	case object.CountEstimate:
+		delete(responseData, "total_count")
+		responseData["estimated_count"] = total
+	case object.CountNone:
+		delete(responseData, "total_count")
+	case object.CountHasMore:
+		delete(responseData, "total_count")
+		responseData["has_more"] = pagination.HasMore
+	}
+	if pagination.ByCursor {
+		if pagination.Next != "" {
+			responseData["cursor"] = pagination.Next
+		} else {
+			responseData["cursor"] = nil
+		}
+	}
+	js.pushListData(responseData)
+}
+
+func (js *JsonSink) listData(objects []interface{}, total int) map[string]interface{} {
 	responseData := map[string]interface{}{"status": js.Status}
 	if objects == nil {
 		objects = make([]interface{}, 0)
 	}
 	responseData["data"] = objects
 	responseData["total_count"] = total
+	return responseData
+}
 
+func (js *JsonSink) pushListData(responseData map[string]interface{}) {
 	if encodedData, err := json.Marshal(responseData); err != nil {
 		returnError(js.rw, err)
 	} else {
@@ -955,3 +1536,117 @@ func (js *JsonSink) pushList(objects []interface{}, total int) {
 		js.rw.Write(encodedData)
 	}
 }
+
+//Formats of exported and imported data
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+//Streams exported records as NDJSON or CSV. The response is started with the first records,
+//so that errors occurred before them are returned as usual
+type ExportSink struct {
+	rw      http.ResponseWriter
+	format  string
+	meta    *object.Meta
+	columns []string
+	csv     *csv.Writer
+	started bool
+}
+
+func newExportSink(w http.ResponseWriter, format string, meta *object.Meta) *ExportSink {
+	return &ExportSink{rw: w, format: format, meta: meta}
+}
+
+//CSV columns are the fields of the object present in the first record, in the order of the object description
+func (es *ExportSink) start(first map[string]interface{}) error {
+	es.started = true
+	if es.format == FormatNDJSON {
+		es.rw.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		es.rw.Header().Set("Content-Type", "text/csv")
+	}
+	es.rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.%s\"", es.meta.Name, es.format))
+	es.rw.WriteHeader(http.StatusOK)
+	if es.format != FormatCSV {
+		return nil
+	}
+	for _, field := range es.meta.Fields {
+		if _, ok := first[field.Name]; ok || first == nil {
+			es.columns = append(es.columns, field.Name)
+		}
+	}
+	es.csv = csv.NewWriter(es.rw)
+	return es.csv.Write(es.columns)
+}
+
+func (es *ExportSink) push(records []map[string]interface{}) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if !es.started {
+		if err := es.start(records[0]); err != nil {
+			return err
+		}
+	}
+	for _, record := range records {
+		if err := es.write(record); err != nil {
+			return err
+		}
+	}
+	return es.flush()
+}
+
+func (es *ExportSink) write(record map[string]interface{}) error {
+	if es.format == FormatNDJSON {
+		encodedData, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		_, err = es.rw.Write(append(encodedData, '\n'))
+		return err
+	}
+	row := make([]string, len(es.columns))
+	for i, column := range es.columns {
+		switch value := record[column].(type) {
+		case nil:
+		case string:
+			row[i] = value
+		case float64:
+			row[i] = strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			row[i] = strconv.FormatBool(value)
+		default:
+			//links and documents are written as JSON
+			encodedValue, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			row[i] = string(encodedValue)
+		}
+	}
+	return es.csv.Write(row)
+}
+
+func (es *ExportSink) flush() error {
+	if es.csv != nil {
+		es.csv.Flush()
+		if err := es.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := es.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+//Completes the export, the response is started here if there are no records to export
+func (es *ExportSink) close() error {
+	if !es.started {
+		if err := es.start(nil); err != nil {
+			return err
+		}
+	}
+	return es.flush()
+}
@@ -2,4 +2,8 @@ package transactions
 
 type DbTransactionManager interface {
 	BeginTransaction() (DbTransaction, error)
-}
\ No newline at end of file
+	//Transactions begun until the shared transaction is completed are nested into it
+	BeginSharedTransaction() (DbTransaction, error)
+	CompleteSharedTransaction(commit bool) error
+	InSharedTransaction() bool
+}
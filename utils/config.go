@@ -3,6 +3,7 @@ package utils
 import (
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +17,8 @@ type AppConfig struct {
 	MigrationStoragePath    string
 	StartTime               int
 	WorkDir                 string
+	GraphQLMaxDepth         int
+	GraphQLMaxComplexity    int
 }
 
 func getRealWorkingDirectory() string {
@@ -55,6 +58,8 @@ func GetConfig() *AppConfig {
 		EnableProfiler:          false,
 		DisableSafePanicHandler: true,
 		MigrationStoragePath:    path.Join(getRealWorkingDirectory(), "/applied_migrations"),
+		GraphQLMaxDepth:         15,
+		GraphQLMaxComplexity:    1000,
 	}
 
 	if urlPrefix := os.Getenv("URL_PREFIX"); len(urlPrefix) > 0 {
@@ -84,6 +89,13 @@ func GetConfig() *AppConfig {
 		}
 	}
 
+	if graphQLMaxDepth, err := strconv.Atoi(os.Getenv("GRAPHQL_MAX_DEPTH")); err == nil {
+		appConfig.GraphQLMaxDepth = graphQLMaxDepth
+	}
+	if graphQLMaxComplexity, err := strconv.Atoi(os.Getenv("GRAPHQL_MAX_COMPLEXITY")); err == nil {
+		appConfig.GraphQLMaxComplexity = graphQLMaxComplexity
+	}
+
 	appConfig.StartTime = int(time.Now().Unix())
 	appConfig.WorkDir = getRealWorkingDirectory()
 